@@ -0,0 +1,124 @@
+package wget
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"L2/internal/cli"
+)
+
+// convertLinks переписывает ссылки в загруженных HTML и CSS так, чтобы сайт
+// открывался с диска (-k). Ссылки на загруженные файлы становятся относительными
+// путями, остальные ссылки на http и https — абсолютными адресами. Ссылки
+// вида #anchor, mailto:, javascript: и data: не меняются. Выполняется после обхода,
+// когда известно, какие адреса загружены.
+func (m *mirror) convertLinks() {
+	converted := 0
+	for _, d := range m.downloads {
+		if d.kind == otherContent {
+			continue
+		}
+		data, err := os.ReadFile(d.file)
+		if err == nil {
+			_, err = saveFile(d.file, bytes.NewReader(m.rewrite(d, data)))
+		}
+		if err != nil {
+			cli.Errorf(m.stdio, "wget", cli.ExitFailure, "converting links: %v", err)
+			m.failed = true
+			continue
+		}
+		converted++
+	}
+	fmt.Fprintf(m.stdio.Out, "Converted links in %d files\n", converted)
+}
+
+// rewrite возвращает содержимое файла d с переписанными ссылками.
+func (m *mirror) rewrite(d download, data []byte) []byte {
+	base, refs := pageRefs(d.kind, data)
+	var edits []ref
+	for _, r := range refs {
+		if link, ok := m.convertLink(d, base, r.value); ok {
+			r.value = escapeLink(link, r)
+			edits = append(edits, r)
+		}
+	}
+	// Ссылки уже указывают на файлы относительно самой страницы,
+	// поэтому <base href> больше не нужен: пустой адрес — адрес документа.
+	if base != nil {
+		edits = append(edits, ref{start: base.start, end: base.end})
+	}
+	if len(edits) == 0 {
+		return data
+	}
+
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+	var out []byte
+	last := 0
+	for _, e := range edits {
+		out = append(out, data[last:e.start]...)
+		out = append(out, e.value...)
+		last = e.end
+	}
+	return append(out, data[last:]...)
+}
+
+// convertLink возвращает новую ссылку вместо link на странице d.
+// ok == false — ссылку менять не нужно.
+func (m *mirror) convertLink(d download, base *attr, link string) (string, bool) {
+	link = strings.TrimSpace(link)
+	if link == "" || strings.HasPrefix(link, "#") {
+		return "", false
+	}
+	u, err := resolve(d.url, base, link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", false
+	}
+	fragment := u.EscapedFragment()
+	normalize(u)
+	link = u.String()
+	if file, ok := m.localFile(link); ok {
+		rel, err := filepath.Rel(filepath.Dir(d.file), file)
+		if err != nil {
+			return "", false
+		}
+		// Имя файла может содержать '?' и '%' из строки запроса: в ссылке они экранируются.
+		link = (&url.URL{Path: filepath.ToSlash(rel)}).String()
+	}
+	if fragment != "" {
+		link += "#" + fragment
+	}
+	return link, true
+}
+
+// localFile возвращает файл, в который загружен адрес key, с учётом перенаправлений.
+func (m *mirror) localFile(key string) (string, bool) {
+	if file, ok := m.local[key]; ok {
+		return file, true
+	}
+	if final, ok := m.redirects[key]; ok {
+		file, ok := m.local[final]
+		return file, ok
+	}
+	return "", false
+}
+
+// cssEscaper экранирует символы, которые завершают url() без кавычек или строку CSS.
+var cssEscaper = strings.NewReplacer("(", "%28", ")", "%29", "'", "%27", `"`, "%22", " ", "%20", `\`, "%5C")
+
+// escapeLink экранирует ссылку для места r: в CSS — скобки, кавычки и пробелы,
+// в атрибуте HTML — сущностями HTML.
+func escapeLink(link string, r ref) string {
+	if r.css {
+		link = cssEscaper.Replace(link)
+	}
+	if r.html {
+		link = html.EscapeString(link)
+	}
+	return link
+}
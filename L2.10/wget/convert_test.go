@@ -0,0 +1,96 @@
+package wget
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"L2/internal/cli"
+)
+
+// convertSite — сайт для -k и -E: ссылки в атрибутах, <style>, style="" и CSS,
+// страница со строкой запроса и без расширения, битая и внешняя ссылки.
+var convertSite = map[string]string{
+	"/": `<html><head><base href="/"><link rel="stylesheet" href="/css/site.css">` +
+		`<style>h1 { background: url("img/h1 bg.png") }</style></head><body>` +
+		`<a href="/docs/page?id=1&amp;lang=en#intro">Doc</a> <a href="http://other.example/x">Other</a> ` +
+		`<a href="missing">Missing</a> <a href="#top">Top</a> <a href="mailto:a@b.c">Mail</a> ` +
+		`<img src="img/logo.png" style="border-image: url(/img/border.png)"></body></html>`,
+	"/docs/page":      `<a href="../">Home</a> <a href="/docs/page?id=1&amp;lang=en">Self</a>`,
+	"/css/site.css":   `@import "print.css"; body { background: url(/img/bg.png) }`,
+	"/css/print.css":  `p { color: black }`,
+	"/img/logo.png":   "logo",
+	"/img/bg.png":     "bg",
+	"/img/border.png": "border",
+	"/img/h1 bg.png":  "h1",
+}
+
+func TestConvertLinks(t *testing.T) {
+	server := newFixtureServer(t, convertSite)
+	host := strings.TrimPrefix(server.URL, "http://")
+	dir := t.TempDir()
+	var stdout, stderr bytes.Buffer
+	Main([]string{"-r", "-l", "0", "-k", "-E", "-P", dir, server.URL}, cli.Stdio{Out: &stdout, Err: &stderr})
+
+	site := filepath.Join(dir, host)
+	expectedFiles := []string{"css/print.css", "css/site.css", "docs/page?id=1&lang=en.html",
+		"img/bg.png", "img/border.png", "img/h1 bg.png", "img/logo.png", "index.html"}
+	if files := listFiles(t, site); !reflect.DeepEqual(files, expectedFiles) {
+		t.Fatalf("Ожидались файлы %q, но получено %q (stderr: %q)", expectedFiles, files, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Converted links in 4 files") {
+		t.Errorf("Ожидалось преобразование ссылок в 4 файлах, но получено %q", stdout.String())
+	}
+
+	tests := []struct {
+		file     string
+		expected string
+	}{
+		{"index.html", `<html><head><base href=""><link rel="stylesheet" href="css/site.css">` +
+			`<style>h1 { background: url("img/h1%20bg.png") }</style></head><body>` +
+			`<a href="docs/page%3Fid=1&amp;lang=en.html#intro">Doc</a> <a href="http://other.example/x">Other</a> ` +
+			`<a href="` + server.URL + `/missing">Missing</a> <a href="#top">Top</a> <a href="mailto:a@b.c">Mail</a> ` +
+			`<img src="img/logo.png" style="border-image: url(img/border.png)"></body></html>`},
+		{"docs/page?id=1&lang=en.html", `<a href="../index.html">Home</a> <a href="page%3Fid=1&amp;lang=en.html">Self</a>`},
+		{"css/site.css", `@import "print.css"; body { background: url(../img/bg.png) }`},
+		{"css/print.css", convertSite["/css/print.css"]},
+		{"img/logo.png", "logo"},
+	}
+	for _, test := range tests {
+		data, err := os.ReadFile(filepath.Join(site, filepath.FromSlash(test.file)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != test.expected {
+			t.Errorf("Для %s ожидалось\n%s\nно получено\n%s", test.file, test.expected, data)
+		}
+	}
+}
+
+func TestConvertLinksDepth(t *testing.T) {
+	// Страницы глубже -l не загружаются, и ссылки на них остаются абсолютными.
+	server := newFixtureServer(t, fixtureSite)
+	host := strings.TrimPrefix(server.URL, "http://")
+	dir := t.TempDir()
+	Main([]string{"-r", "-l", "1", "--convert-links", "-P", dir, server.URL + "/about.html"}, cli.Stdio{Out: &bytes.Buffer{}, Err: &bytes.Buffer{}})
+
+	data, err := os.ReadFile(filepath.Join(dir, host, "about.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<a href="index.html">Home</a> <a href="deep/1.html">Deep</a>`
+	if string(data) != expected {
+		t.Errorf("Ожидалось %q, но получено %q", expected, data)
+	}
+	data, err = os.ReadFile(filepath.Join(dir, host, "deep", "1.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = `<a href="` + server.URL + `/deep/2.html">Deeper</a>`
+	if string(data) != expected {
+		t.Errorf("Ожидалось %q, но получено %q", expected, data)
+	}
+}
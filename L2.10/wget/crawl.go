@@ -0,0 +1,171 @@
+package wget
+
+import (
+	"context"
+	"math/rand"
+	"net/url"
+	"sync"
+	"time"
+
+	"L2/internal/cli"
+)
+
+// exitInterrupted — код завершения после Ctrl+C, как у шелла.
+const exitInterrupted = 130
+
+// hostLimit ограничивает загрузки с одного хоста: не больше perHost одновременно
+// и не чаще одного запроса за wait.
+type hostLimit struct {
+	slots chan struct{}
+
+	mu   sync.Mutex
+	next time.Time // раньше этого времени новый запрос к хосту не начинается
+}
+
+// run обходит сайт: m.workers горутин берут адреса из общей очереди, а ссылки
+// загруженной страницы сразу попадают в её конец, поэтому медленная страница
+// не задерживает остальные. Глубина каждого адреса хранится в m.depths; если
+// адрес потом находится по более короткому пути, его глубина уменьшается
+// (см. lower), так что -l, как при последовательном обходе в ширину, считает
+// кратчайшее число переходов и не зависит от порядка загрузок.
+// Ошибка загрузки одного адреса не останавливает обход; после отмены ctx
+// новые загрузки не начинаются, а начатые прерываются.
+func (m *mirror) run(ctx context.Context) {
+	m.depths[m.start.String()] = 0
+	queue := []*url.URL{m.start}
+	pages := make(chan *url.URL)
+	done := make(chan *url.URL)
+	var wg sync.WaitGroup
+	for i := 0; i < m.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for u := range pages {
+				m.visit(ctx, u)
+				done <- u
+			}
+		}()
+	}
+
+	cancelled := ctx.Done()
+	for active := 0; active > 0 || (len(queue) > 0 && ctx.Err() == nil); {
+		// Пока очередь пуста или обход прерван, next == nil и отправка не выбирается.
+		var next chan *url.URL
+		var head *url.URL
+		if len(queue) > 0 && ctx.Err() == nil {
+			next, head = pages, queue[0]
+		}
+		select {
+		case next <- head:
+			queue = queue[1:]
+			active++
+		case u := <-done:
+			active--
+			m.mu.Lock()
+			queue = append(queue, m.follow(u.String())...)
+			m.mu.Unlock()
+		case <-cancelled:
+			cancelled = nil // дальше ждём только начатые загрузки
+		}
+	}
+	close(pages)
+	wg.Wait()
+}
+
+// visit загружает адрес u с учётом ограничений хоста. Ссылки страницы
+// fetch сохраняет в m.outlinks.
+func (m *mirror) visit(ctx context.Context, u *url.URL) {
+	release, err := m.acquire(ctx, u.Host)
+	if err != nil {
+		return
+	}
+	err = m.fetch(ctx, u)
+	release()
+	if err != nil && ctx.Err() == nil {
+		m.mu.Lock()
+		cli.Errorf(m.stdio, "wget", cli.ExitFailure, "%s: %v", u, err)
+		m.failed = true
+		m.mu.Unlock()
+	}
+}
+
+// follow возвращает новые адреса для очереди из ссылок адреса key, если его
+// глубина меньше -l; конечный адрес перенаправления получает ту же глубину.
+// Вызывается под m.mu.
+func (m *mirror) follow(key string) []*url.URL {
+	d := m.depths[key]
+	var queue []*url.URL
+	if final, ok := m.redirects[key]; ok {
+		queue = m.lower(final, d)
+	}
+	if m.depth > 0 && d >= m.depth {
+		return queue
+	}
+	for _, link := range m.outlinks[key] {
+		linkKey := link.String()
+		if _, seen := m.depths[linkKey]; !seen {
+			m.depths[linkKey] = d + 1
+			queue = append(queue, link)
+			continue
+		}
+		queue = append(queue, m.lower(linkKey, d+1)...)
+	}
+	return queue
+}
+
+// lower уменьшает глубину уже встречавшегося адреса key до d. Ссылки
+// загруженной страницы проходятся заново: какие-то из них могли оказаться
+// глубже -l. Вызывается под m.mu.
+func (m *mirror) lower(key string, d int) []*url.URL {
+	if m.depths[key] <= d {
+		return nil
+	}
+	m.depths[key] = d
+	return m.follow(key)
+}
+
+// acquire занимает место для загрузки с хоста host и выдерживает паузу --wait
+// после предыдущего запроса к нему. release освобождает место.
+func (m *mirror) acquire(ctx context.Context, host string) (release func(), err error) {
+	m.mu.Lock()
+	limit, ok := m.hosts[host]
+	if !ok {
+		limit = &hostLimit{slots: make(chan struct{}, m.perHost)}
+		m.hosts[host] = limit
+	}
+	m.mu.Unlock()
+
+	select {
+	case limit.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	release = func() { <-limit.slots }
+
+	limit.mu.Lock()
+	start := time.Now()
+	if limit.next.After(start) {
+		start = limit.next
+	}
+	limit.next = start.Add(m.pause())
+	limit.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(start))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return release, nil
+	case <-ctx.Done():
+		release()
+		return nil, ctx.Err()
+	}
+}
+
+// pause возвращает паузу перед следующим запросом к хосту: wait, а с --random-wait —
+// случайную величину от 0.5 до 1.5 wait, как в wget.
+func (m *mirror) pause() time.Duration {
+	if !m.randomWait || m.wait <= 0 {
+		return m.wait
+	}
+	return time.Duration((0.5 + rand.Float64()) * float64(m.wait))
+}
@@ -0,0 +1,209 @@
+package wget
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"L2/internal/cli"
+)
+
+// pagesSite возвращает сайт из стартовой страницы со ссылками на n страниц.
+func pagesSite(n int) map[string]string {
+	site := map[string]string{}
+	var index strings.Builder
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("/p/%d.html", i)
+		fmt.Fprintf(&index, `<a href="%s">%d</a>`, name, i)
+		site[name] = fmt.Sprintf("page %d", i)
+	}
+	site["/"] = index.String()
+	return site
+}
+
+func runOptions(t *testing.T, ctx context.Context, args ...string) (int, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	stdio := cli.Stdio{Out: &stdout, Err: &stderr}
+	opts, code, ok := parseWgetOptions(args, stdio)
+	if !ok {
+		t.Fatalf("Неверные аргументы %q: %s", args, stderr.String())
+	}
+	code = runMirror(ctx, opts, stdio)
+	return code, stderr.String()
+}
+
+func TestCrawlerLimits(t *testing.T) {
+	site := pagesSite(20)
+	var active, peak int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&active, 1)
+		defer atomic.AddInt32(&active, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(site[r.URL.Path]))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		workers, perHost string
+		expected         int32
+	}{
+		{"8", "3", 3},
+		{"2", "8", 2},
+		{"1", "4", 1},
+	}
+	for _, test := range tests {
+		atomic.StoreInt32(&peak, 0)
+		dir := t.TempDir()
+		code, stderr := runOptions(t, context.Background(), "-r", "-j", test.workers, "--per-host", test.perHost, "-P", dir, server.URL)
+		if code != cli.ExitOK {
+			t.Errorf("Для -j %s --per-host %s ожидался код 0, но получен %d: %s", test.workers, test.perHost, code, stderr)
+		}
+		if peak := atomic.LoadInt32(&peak); peak != test.expected {
+			t.Errorf("Для -j %s --per-host %s ожидалось %d одновременных запросов, но было %d", test.workers, test.perHost, test.expected, peak)
+		}
+		host := strings.TrimPrefix(server.URL, "http://")
+		if files := listFiles(t, filepath.Join(dir, host)); len(files) != len(site) {
+			t.Errorf("Ожидалось %d файлов, но получено %d: %q", len(site), len(files), files)
+		}
+	}
+}
+
+func TestCrawlerWait(t *testing.T) {
+	site := pagesSite(4)
+	var mu sync.Mutex
+	var starts []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		starts = append(starts, time.Now())
+		mu.Unlock()
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(site[r.URL.Path]))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		args []string
+		min  time.Duration
+	}{
+		{[]string{"--wait", "0.1"}, 100 * time.Millisecond},
+		{[]string{"--wait", "0.1", "--random-wait"}, 50 * time.Millisecond},
+	}
+	for _, test := range tests {
+		starts = nil
+		args := append([]string{"-r", "-j", "4", "-P", t.TempDir()}, test.args...)
+		if code, stderr := runOptions(t, context.Background(), append(args, server.URL)...); code != cli.ExitOK {
+			t.Fatalf("Для %q ожидался код 0, но получен %d: %s", test.args, code, stderr)
+		}
+		sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+		if len(starts) != len(site) {
+			t.Fatalf("Ожидалось %d запросов, но было %d", len(site), len(starts))
+		}
+		for i := 1; i < len(starts); i++ {
+			// Допуск на неточность таймеров.
+			if gap := starts[i].Sub(starts[i-1]); gap < test.min-5*time.Millisecond {
+				t.Errorf("Для %q запросы шли с интервалом %v, меньше %v", test.args, gap, test.min)
+			}
+		}
+	}
+}
+
+func TestCrawlerInterrupt(t *testing.T) {
+	site := pagesSite(5)
+	site["/"] += `<a href="/slow.html">slow</a>`
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		if r.URL.Path != "/slow.html" {
+			w.Write([]byte(site[r.URL.Path]))
+			return
+		}
+		// Половина страницы, затем Ctrl+C посреди загрузки.
+		w.Write([]byte("<p>first half"))
+		w.(http.Flusher).Flush()
+		time.AfterFunc(100*time.Millisecond, cancel)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	code, _ := runOptions(t, ctx, "-r", "-k", "-P", dir, server.URL)
+	if code != exitInterrupted {
+		t.Errorf("Ожидался код %d, но получен %d", exitInterrupted, code)
+	}
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	expected := []string{"index.html", "p/0.html", "p/1.html", "p/2.html", "p/3.html", "p/4.html"}
+	files := listFiles(t, filepath.Join(dir, host))
+	if strings.Join(files, " ") != strings.Join(expected, " ") {
+		t.Errorf("Ожидались только полностью загруженные файлы %q, но получено %q", expected, files)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, host, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `<a href="p/0.html">`) || !strings.Contains(string(data), `<a href="`+server.URL+`/slow.html">`) {
+		t.Errorf("Ссылки в частичной копии должны вести на загруженные файлы и на сайт для остальных: %q", data)
+	}
+}
+
+func TestCrawlerSlowPage(t *testing.T) {
+	// Короткий путь к x.html идёт через медленную страницу, длинный — через a и b.
+	// Пока медленная страница грузится, обход доходит до x.html на глубине 3,
+	// а после её загрузки x.html получает глубину 2, и по её ссылке загружается y.html.
+	site := map[string]string{
+		"/":       `<a href="/slow.html"></a><a href="/a.html"></a>`,
+		"/a.html": `<a href="/b.html"></a>`,
+		"/b.html": `<a href="/x.html"></a>`,
+		"/x.html": `<a href="/y.html"></a>`,
+		"/y.html": "y",
+	}
+	xRequested := make(chan struct{})
+	var once sync.Once
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/x.html":
+			once.Do(func() { close(xRequested) })
+		case "/slow.html":
+			select {
+			case <-xRequested:
+			case <-time.After(2 * time.Second):
+				t.Error("Медленная страница задержала загрузку страниц следующих уровней")
+			}
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<a href="/x.html"></a>`))
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(site[r.URL.Path]))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	if code, stderr := runOptions(t, context.Background(), "-r", "-l", "3", "-P", dir, server.URL); code != cli.ExitOK {
+		t.Fatalf("Ожидался код 0, но получен %d: %s", code, stderr)
+	}
+	host := strings.TrimPrefix(server.URL, "http://")
+	expected := []string{"a.html", "b.html", "index.html", "slow.html", "x.html", "y.html"}
+	if files := listFiles(t, filepath.Join(dir, host)); strings.Join(files, " ") != strings.Join(expected, " ") {
+		t.Errorf("Ожидались файлы %q, но получено %q", expected, files)
+	}
+}
@@ -0,0 +1,61 @@
+package wget
+
+import "bytes"
+
+// cssRefs находит ссылки в CSS: url(...) с кавычками и без и строки после @import.
+// Комментарии /* */ и прочие строки пропускаются.
+func cssRefs(data []byte) []ref {
+	var refs []ref
+	i := 0
+	for i < len(data) {
+		switch c := data[i]; {
+		case bytes.HasPrefix(data[i:], []byte("/*")):
+			i = skipPast(data, i+2, "*/")
+		case c == '"' || c == '\'':
+			_, _, i = attrValue(data, i)
+		case hasPrefixFold(data[i:], "@import"):
+			i += len("@import")
+			for i < len(data) && isSpace(data[i]) {
+				i++
+			}
+			if i < len(data) && (data[i] == '"' || data[i] == '\'') {
+				start, end, next := attrValue(data, i)
+				refs = append(refs, ref{value: string(data[start:end]), start: start, end: end, css: true})
+				i = next
+			}
+		case hasPrefixFold(data[i:], "url(") && (i == 0 || !isNameChar(data[i-1])):
+			i += len("url(")
+			for i < len(data) && isSpace(data[i]) {
+				i++
+			}
+			var start, end int
+			if i < len(data) && (data[i] == '"' || data[i] == '\'') {
+				start, end, i = attrValue(data, i)
+			} else {
+				start = i
+				for i < len(data) && data[i] != ')' {
+					i++
+				}
+				end = i
+				for end > start && isSpace(data[end-1]) {
+					end--
+				}
+			}
+			if end > start {
+				refs = append(refs, ref{value: string(data[start:end]), start: start, end: end, css: true})
+			}
+		default:
+			i++
+		}
+	}
+	return refs
+}
+
+func hasPrefixFold(data []byte, prefix string) bool {
+	return len(data) >= len(prefix) && bytes.EqualFold(data[:len(prefix)], []byte(prefix))
+}
+
+// isNameChar сообщает, что c может входить в идентификатор CSS.
+func isNameChar(c byte) bool {
+	return isLetter(c) || '0' <= c && c <= '9' || c == '-' || c == '_'
+}
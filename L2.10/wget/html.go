@@ -0,0 +1,209 @@
+package wget
+
+import (
+	"bytes"
+	"html"
+	"strings"
+)
+
+// attr — атрибут тега. value — значение с раскрытыми сущностями (&amp; и т. п.),
+// start и end — границы значения в исходном HTML без кавычек; у атрибута
+// без значения они равны -1.
+type attr struct {
+	name       string
+	value      string
+	start, end int
+}
+
+// tag — открывающий тег: имя в нижнем регистре и атрибуты в порядке следования.
+// Для script и style textStart и textEnd — границы содержимого элемента.
+type tag struct {
+	name  string
+	attrs []attr
+
+	textStart, textEnd int
+}
+
+// attr возвращает атрибут name; имя задаётся в нижнем регистре.
+func (t tag) attr(name string) (attr, bool) {
+	for _, a := range t.attrs {
+		if a.name == name {
+			return a, true
+		}
+	}
+	return attr{}, false
+}
+
+// parseTags находит в HTML открывающие теги. Комментарии, объявления (<!DOCTYPE>),
+// инструкции <?...?> и закрывающие теги пропускаются, содержимое script и style
+// не разбирается: теги внутри строк JavaScript — не теги. Разбор не строгий,
+// как у браузера: незакрытые кавычки и теги заканчиваются вместе с документом.
+func parseTags(data []byte) []tag {
+	var tags []tag
+	i := 0
+	for {
+		lt := bytes.IndexByte(data[i:], '<')
+		if lt < 0 {
+			return tags
+		}
+		i += lt
+		rest := data[i:]
+		switch {
+		case bytes.HasPrefix(rest, []byte("<!--")):
+			i = skipPast(data, i+4, "-->")
+		case bytes.HasPrefix(rest, []byte("<!")), bytes.HasPrefix(rest, []byte("<?")), bytes.HasPrefix(rest, []byte("</")):
+			i = skipPast(data, i+2, ">")
+		case len(rest) > 1 && isLetter(rest[1]):
+			var t tag
+			t, i = parseTag(data, i+1)
+			if t.name == "script" || t.name == "style" {
+				t.textStart = i
+				i = skipRawText(data, i, t.name)
+				t.textEnd = i
+			}
+			tags = append(tags, t)
+		default:
+			i++
+		}
+	}
+}
+
+// parseTag разбирает тег, имя которого начинается с data[i], и возвращает
+// его вместе с позицией после закрывающей '>'.
+func parseTag(data []byte, i int) (tag, int) {
+	start := i
+	for i < len(data) && !isSpace(data[i]) && data[i] != '>' && data[i] != '/' {
+		i++
+	}
+	t := tag{name: strings.ToLower(string(data[start:i]))}
+	for {
+		for i < len(data) && (isSpace(data[i]) || data[i] == '/') {
+			i++
+		}
+		if i >= len(data) {
+			return t, i
+		}
+		if data[i] == '>' {
+			return t, i + 1
+		}
+
+		start = i
+		for i < len(data) && !isSpace(data[i]) && data[i] != '>' && data[i] != '/' && (data[i] != '=' || i == start) {
+			i++
+		}
+		a := attr{name: strings.ToLower(string(data[start:i])), start: -1, end: -1}
+		j := i
+		for j < len(data) && isSpace(data[j]) {
+			j++
+		}
+		if j < len(data) && data[j] == '=' {
+			i = j + 1
+			for i < len(data) && isSpace(data[i]) {
+				i++
+			}
+			a.start, a.end, i = attrValue(data, i)
+			a.value = html.UnescapeString(string(data[a.start:a.end]))
+		}
+		t.attrs = append(t.attrs, a)
+	}
+}
+
+// attrValue возвращает границы значения атрибута, начинающегося с data[i],
+// и позицию после него.
+func attrValue(data []byte, i int) (start, end, next int) {
+	if i < len(data) && (data[i] == '"' || data[i] == '\'') {
+		quote := data[i]
+		end := bytes.IndexByte(data[i+1:], quote)
+		if end < 0 {
+			return i + 1, len(data), len(data)
+		}
+		return i + 1, i + 1 + end, i + end + 2
+	}
+	start = i
+	for i < len(data) && !isSpace(data[i]) && data[i] != '>' {
+		i++
+	}
+	return start, i, i
+}
+
+// skipPast возвращает позицию после первого end, начиная с data[i], или конец данных.
+func skipPast(data []byte, i int, end string) int {
+	n := bytes.Index(data[i:], []byte(end))
+	if n < 0 {
+		return len(data)
+	}
+	return i + n + len(end)
+}
+
+// skipRawText пропускает содержимое элемента name до закрывающего тега, который
+// ищется без учёта регистра, и возвращает позицию начала этого тега.
+func skipRawText(data []byte, i int, name string) int {
+	closing := []byte("</" + name)
+	for j := i; j+len(closing) <= len(data); j++ {
+		if data[j] == '<' && bytes.EqualFold(data[j:j+len(closing)], closing) {
+			return j
+		}
+	}
+	return len(data)
+}
+
+func isLetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// linkAttrs — теги и атрибуты, по ссылкам из которых идёт рекурсивная загрузка.
+var linkAttrs = map[string]string{
+	"a":      "href",
+	"link":   "href",
+	"img":    "src",
+	"script": "src",
+}
+
+// ref — ссылка в HTML или CSS: адрес и его границы в исходных данных.
+// html — ссылка в значении атрибута, где действуют сущности HTML, css — в коде CSS,
+// где адрес без кавычек заканчивается скобкой. Ссылка в атрибуте style — и то и другое.
+type ref struct {
+	value      string
+	start, end int
+	html, css  bool
+}
+
+// htmlRefs возвращает ссылки страницы: из атрибутов linkAttrs, из url() и @import
+// в элементах <style> и атрибутах style. base — атрибут href тега <base>, если он есть.
+func htmlRefs(data []byte) (base *attr, refs []ref) {
+	for _, t := range parseTags(data) {
+		if t.name == "base" {
+			if a, ok := t.attr("href"); ok && a.start >= 0 && base == nil {
+				base = &a
+			}
+			continue
+		}
+		if name, ok := linkAttrs[t.name]; ok {
+			if a, ok := t.attr(name); ok && a.start >= 0 {
+				refs = append(refs, ref{value: a.value, start: a.start, end: a.end, html: true})
+			}
+		}
+		// Смещения ссылок считаются по исходному тексту, поэтому атрибут style
+		// с сущностями HTML не разбирается.
+		if a, ok := t.attr("style"); ok && a.start >= 0 && a.value == string(data[a.start:a.end]) {
+			for _, r := range cssRefs([]byte(a.value)) {
+				r.start += a.start
+				r.end += a.start
+				r.html = true
+				refs = append(refs, r)
+			}
+		}
+		if t.name == "style" {
+			for _, r := range cssRefs(data[t.textStart:t.textEnd]) {
+				r.start += t.textStart
+				r.end += t.textStart
+				refs = append(refs, r)
+			}
+		}
+	}
+	return base, refs
+}
@@ -0,0 +1,87 @@
+package wget
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTags(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string // имя тега и пары атрибут=значение
+	}{
+		{`<a href="/x">x</a>`, []string{"a", "href=/x"}},
+		{`<IMG SRC='logo.png' alt=Logo>`, []string{"img", "src=logo.png", "alt=Logo"}},
+		{`<a href=/x/y/>y</a>`, []string{"a", "href=/x/y/"}},
+		{`<input disabled value = "a &amp; b"/>`, []string{"input", "disabled", "value=a & b"}},
+		{`<!-- <a href="/hidden"> --><p>`, []string{"p"}},
+		{`<!DOCTYPE html><?xml version="1.0"?></div><br/>`, []string{"br"}},
+		{`<script>document.write("<a href='/js'>")</SCRIPT><a href="/after">`, []string{"script", "a", "href=/after"}},
+		{`<style>a::before { content: "<img src=x>" }</style>`, []string{"style"}},
+		{`a < b <a href="unterminated`, []string{"a", "href=unterminated"}},
+	}
+
+	for _, test := range tests {
+		var got []string
+		for _, tag := range parseTags([]byte(test.input)) {
+			got = append(got, tag.name)
+			for _, a := range tag.attrs {
+				if a.start < 0 {
+					got = append(got, a.name)
+				} else {
+					got = append(got, a.name+"="+a.value)
+				}
+			}
+		}
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("Для %q ожидалось %q, но получено %q", test.input, test.expected, got)
+		}
+	}
+}
+
+func TestHTMLRefs(t *testing.T) {
+	data := []byte(`<base href="/root/"><a href="a.html?x=1&amp;y=2">a</a><link rel="stylesheet" href='s.css'>` +
+		`<img src=i.png><script src="j.js"></script><a name="anchor"><div src="no">` +
+		`<style>body { background: url(bg.png) }</style><p style="background: url('p.png')">`)
+	base, refs := htmlRefs(data)
+	if base == nil || base.value != "/root/" {
+		t.Errorf("Ожидался base %q, но получен %v", "/root/", base)
+	}
+	var values []string
+	for _, r := range refs {
+		values = append(values, r.value)
+		if raw := string(data[r.start:r.end]); raw != r.value && !r.html {
+			t.Errorf("Границы ссылки %q указывают на %q", r.value, raw)
+		}
+	}
+	expected := []string{"a.html?x=1&y=2", "s.css", "i.png", "j.js", "bg.png", "p.png"}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("Ожидались ссылки %q, но получено %q", expected, values)
+	}
+}
+
+func TestCSSRefs(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{`body { background: url(img/bg.png) }`, []string{"img/bg.png"}},
+		{`a { background: URL( "x y.png" ) } b { src: url('f.woff') }`, []string{"x y.png", "f.woff"}},
+		{`@import "base.css"; @import url(print.css) print;`, []string{"base.css", "print.css"}},
+		{`/* url(commented.png) */ p { content: "url(string.png)" }`, nil},
+		{`p { background-image: myurl(no.png), url() }`, nil},
+	}
+
+	for _, test := range tests {
+		var got []string
+		for _, r := range cssRefs([]byte(test.input)) {
+			if raw := test.input[r.start:r.end]; raw != r.value {
+				t.Errorf("Границы ссылки %q указывают на %q", r.value, raw)
+			}
+			got = append(got, r.value)
+		}
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("Для %q ожидалось %q, но получено %q", test.input, test.expected, got)
+		}
+	}
+}
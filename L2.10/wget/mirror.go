@@ -0,0 +1,292 @@
+package wget
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"L2/internal/cli"
+)
+
+// mirror — рекурсивная загрузка сайта. Страницы обходятся в ширину от стартовой,
+// по ссылкам из HTML и CSS на тот же хост, не глубже depth переходов. Каждый адрес
+// загружается один раз и сохраняется в prefix/host/path, как у wget.
+// Загрузки идут параллельно, см. crawl.go.
+type mirror struct {
+	client    *http.Client
+	start     *url.URL
+	depth     int
+	prefix    string
+	convert   bool // -k: после загрузки переписать ссылки на локальные файлы
+	extension bool // -E: добавлять .html к именам HTML-страниц
+	stdio     cli.Stdio
+
+	workers    int           // -j: число одновременных загрузок
+	perHost    int           // --per-host: одновременных загрузок с одного хоста
+	wait       time.Duration // --wait: пауза между запросами к одному хосту
+	randomWait bool          // --random-wait: пауза от 0.5 до 1.5 wait
+
+	// mu защищает всё ниже, а также вывод в stdio.
+	mu        sync.Mutex
+	depths    map[string]int        // адрес → наименьшее известное число переходов до него
+	outlinks  map[string][]*url.URL // адрес → ссылки загруженной по нему страницы
+	local     map[string]string     // адрес → файл, в который он загружен
+	redirects map[string]string     // адрес → конечный адрес, если он загружен отдельно
+	hosts     map[string]*hostLimit
+	downloads []download
+	bytes     int64
+	failed    bool
+}
+
+// contentKind — вид загруженного файла: от него зависит, где искать ссылки.
+type contentKind int
+
+const (
+	otherContent contentKind = iota
+	htmlContent
+	cssContent
+)
+
+// download — загруженный файл: конечный адрес, имя файла и вид содержимого.
+type download struct {
+	url  *url.URL
+	file string
+	kind contentKind
+}
+
+func runMirror(ctx context.Context, opts WgetOptions, stdio cli.Stdio) int {
+	start, err := url.Parse(opts.url)
+	if err != nil || (start.Scheme != "http" && start.Scheme != "https") || start.Host == "" {
+		return cli.Errorf(stdio, "wget", cli.ExitUsage, "%s: only absolute http and https URLs can be mirrored", opts.url)
+	}
+	normalize(start)
+	m := &mirror{
+		client:    http.DefaultClient,
+		start:     start,
+		depth:     opts.depth,
+		prefix:    opts.prefix,
+		convert:   opts.convertLinks,
+		extension: opts.adjustExtension,
+		stdio:     stdio,
+
+		workers:    opts.workers,
+		perHost:    opts.perHost,
+		wait:       opts.wait,
+		randomWait: opts.randomWait,
+
+		depths:    make(map[string]int),
+		outlinks:  make(map[string][]*url.URL),
+		local:     make(map[string]string),
+		redirects: make(map[string]string),
+		hosts:     make(map[string]*hostLimit),
+	}
+	m.run(ctx)
+	fmt.Fprintf(stdio.Out, "Downloaded %d files, %d bytes\n", len(m.downloads), m.bytes)
+	// После прерывания ссылки тоже переписываются: на незагруженные страницы
+	// они станут абсолютными, и частичная копия сайта останется рабочей.
+	if m.convert {
+		m.convertLinks()
+	}
+	if ctx.Err() != nil {
+		return cli.Errorf(stdio, "wget", exitInterrupted, "interrupted")
+	}
+	if m.failed {
+		return cli.ExitFailure
+	}
+	return cli.ExitOK
+}
+
+// fetch загружает адрес u в файл и, если это HTML или CSS, сохраняет в m.outlinks
+// ссылки из него на тот же хост. После перенаправления файл называется по конечному адресу.
+func (m *mirror) fetch(ctx context.Context, u *url.URL) error {
+	resp, err := get(ctx, m.client, u.String())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	final := resp.Request.URL
+	if final.String() != u.String() {
+		if !m.sameHost(final) {
+			return fmt.Errorf("redirected to another host: %s", final)
+		}
+		m.mu.Lock()
+		_, seen := m.depths[final.String()]
+		if seen {
+			// Конечный адрес уже загружен или стоит в очереди.
+			m.redirects[u.String()] = final.String()
+		} else {
+			m.depths[final.String()] = m.depths[u.String()]
+		}
+		m.mu.Unlock()
+		if seen {
+			return nil
+		}
+	}
+
+	kind := contentKindOf(resp.Header.Get("Content-Type"))
+	name, err := localPath(m.prefix, final)
+	if err != nil {
+		return err
+	}
+	if m.extension && kind == htmlContent && !hasHTMLExtension(name) {
+		name += ".html"
+	}
+	// HTML и CSS нужно разобрать целиком, остальное копируется потоком.
+	var body io.Reader = resp.Body
+	var content strings.Builder
+	if kind != otherContent {
+		body = io.TeeReader(resp.Body, &content)
+	}
+	n, err := saveFile(name, body)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.bytes += n
+	m.downloads = append(m.downloads, download{final, name, kind})
+	m.local[u.String()] = name
+	m.local[final.String()] = name
+	fmt.Fprintf(m.stdio.Out, "Downloaded: %s to file %s\n", final, name)
+	m.mu.Unlock()
+
+	if kind == otherContent {
+		return nil
+	}
+	links := m.links(final, kind, []byte(content.String()))
+	m.mu.Lock()
+	m.outlinks[u.String()] = links
+	m.outlinks[final.String()] = links
+	m.mu.Unlock()
+	return nil
+}
+
+// saveFile записывает r во временный файл рядом с name и переименовывает его в name,
+// только когда данные получены полностью. Поэтому прерванная загрузка не оставляет
+// обрезанных файлов, а уже загруженный ранее файл не портится.
+func saveFile(name string, r io.Reader) (int64, error) {
+	dir := filepath.Dir(name)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return 0, err
+	}
+	file, err := os.CreateTemp(dir, "."+filepath.Base(name)+".*.part")
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(file, r)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(file.Name(), 0o644)
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), name)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return 0, err
+	}
+	return n, nil
+}
+
+// links возвращает адреса из ссылок страницы, по которым нужно пройти:
+// http и https на тот же хост.
+func (m *mirror) links(pageURL *url.URL, kind contentKind, data []byte) []*url.URL {
+	base, refs := pageRefs(kind, data)
+	var links []*url.URL
+	for _, r := range refs {
+		u, err := resolve(pageURL, base, r.value)
+		if err != nil || !m.sameHost(u) {
+			continue
+		}
+		normalize(u)
+		links = append(links, u)
+	}
+	return links
+}
+
+// pageRefs возвращает ссылки из HTML или CSS.
+func pageRefs(kind contentKind, data []byte) (base *attr, refs []ref) {
+	if kind == cssContent {
+		return nil, cssRefs(data)
+	}
+	return htmlRefs(data)
+}
+
+// resolve переводит ссылку страницы pageURL в абсолютный адрес с учётом <base href>.
+func resolve(pageURL *url.URL, base *attr, link string) (*url.URL, error) {
+	if base != nil {
+		if u, err := pageURL.Parse(strings.TrimSpace(base.value)); err == nil {
+			pageURL = u
+		}
+	}
+	return pageURL.Parse(strings.TrimSpace(link))
+}
+
+// normalize приводит адрес к виду, по которому повторы узнаются в очереди:
+// убирает фрагмент после # и заменяет пустой путь на "/".
+func normalize(u *url.URL) {
+	u.Fragment, u.RawFragment = "", ""
+	if u.Path == "" {
+		u.Path = "/"
+	}
+}
+
+// sameHost сообщает, что u — адрес http или https на хосте стартовой страницы.
+func (m *mirror) sameHost(u *url.URL) bool {
+	return (u.Scheme == "http" || u.Scheme == "https") && strings.EqualFold(u.Host, m.start.Host)
+}
+
+// queryEscaper экранирует в строке запроса разделители каталогов, чтобы запрос
+// оставался частью имени файла.
+var queryEscaper = strings.NewReplacer("/", "%2F", `\`, "%5C")
+
+// localPath возвращает имя файла для адреса u: prefix/host/path. Для адреса,
+// оканчивающегося на /, файл называется index.html, строка запроса добавляется
+// к имени после '?'. Путь очищается от "..", а разделители каталогов в запросе
+// экранируются; имя вне prefix/host считается ошибкой.
+func localPath(prefix string, u *url.URL) (string, error) {
+	p := u.Path
+	if p == "" || strings.HasSuffix(p, "/") {
+		p += "index.html"
+	}
+	p = path.Clean("/" + p)
+	if u.RawQuery != "" {
+		p += "?" + queryEscaper.Replace(u.RawQuery)
+	}
+	root := filepath.Join(prefix, u.Host)
+	name := filepath.Join(root, filepath.FromSlash(p))
+	rel, err := filepath.Rel(root, name)
+	if err != nil || !filepath.IsLocal(u.Host) || !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("unsafe file name for %s", u)
+	}
+	return name, nil
+}
+
+// contentKindOf определяет вид содержимого по заголовку Content-Type.
+func contentKindOf(contentType string) contentKind {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/html", "application/xhtml+xml":
+		return htmlContent
+	case "text/css":
+		return cssContent
+	}
+	return otherContent
+}
+
+// hasHTMLExtension сообщает, что имя файла оканчивается на .html или .htm.
+func hasHTMLExtension(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".html" || ext == ".htm"
+}
@@ -0,0 +1,184 @@
+package wget
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"L2/internal/cli"
+)
+
+// fixtureSite — небольшой сайт для тестов рекурсивной загрузки: путь → содержимое.
+var fixtureSite = map[string]string{
+	"/": `<!DOCTYPE html>
+<html><head>
+<link rel="stylesheet" href="/css/style.css">
+<script src="js/app.js"></script>
+<script>var s = "<a href='/in-script.html'>";</script>
+</head><body>
+<!-- <a href="/commented.html"> -->
+<img src="/img/logo.png" alt="logo">
+<a href="about.html#team">About</a>
+<a href="/docs">Docs</a>
+<a href="http://other.example/page.html">Other site</a>
+<a href="mailto:admin@example.com">Mail</a>
+<a href="/missing.html">Broken</a>
+<a href="/">Home</a>
+</body></html>`,
+	"/about.html":    `<a href="/">Home</a> <a href="deep/1.html">Deep</a>`,
+	"/deep/1.html":   `<a href="2.html">Deeper</a>`,
+	"/deep/2.html":   `<p>The end</p>`,
+	"/docs/":         `<base href="/docs/"><a href="page?id=1&amp;lang=en">Page</a>`,
+	"/docs/page":     `<a href="../about.html">Back</a>`,
+	"/css/style.css": `body { color: black }`,
+	"/js/app.js":     `console.log("<a href='/not-a-link.html'>")`,
+	"/img/logo.png":  "\x89PNG",
+}
+
+// newFixtureServer запускает сервер с сайтом site. Тип содержимого определяется
+// по расширению: .css, .js и .png, остальное — HTML. /docs перенаправляется на /docs/.
+func newFixtureServer(t *testing.T, site map[string]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/docs" {
+			http.Redirect(w, r, "/docs/", http.StatusMovedPermanently)
+			return
+		}
+		body, ok := site[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		switch path.Ext(r.URL.Path) {
+		case ".css":
+			w.Header().Set("Content-Type", "text/css")
+		case ".js":
+			w.Header().Set("Content-Type", "text/javascript")
+		case ".png":
+			w.Header().Set("Content-Type", "image/png")
+		default:
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// listFiles возвращает пути всех файлов в dir относительно него.
+func listFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			rel, _ := filepath.Rel(dir, path)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+	return files
+}
+
+func TestMirror(t *testing.T) {
+	server := newFixtureServer(t, fixtureSite)
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	tests := []struct {
+		depth    string
+		expected []string
+	}{
+		{"1", []string{"about.html", "css/style.css", "docs/index.html", "img/logo.png", "index.html", "js/app.js"}},
+		{"2", []string{"about.html", "css/style.css", "deep/1.html", "docs/index.html", "docs/page?id=1&lang=en", "img/logo.png", "index.html", "js/app.js"}},
+		{"0", []string{"about.html", "css/style.css", "deep/1.html", "deep/2.html", "docs/index.html", "docs/page?id=1&lang=en", "img/logo.png", "index.html", "js/app.js"}},
+	}
+
+	for _, test := range tests {
+		dir := t.TempDir()
+		var stdout, stderr bytes.Buffer
+		code := Main([]string{"-r", "-l", test.depth, "-P", dir, server.URL + "/"}, cli.Stdio{Out: &stdout, Err: &stderr})
+		// Ссылка на /missing.html битая, поэтому код завершения — ошибка.
+		if code != cli.ExitFailure || !strings.Contains(stderr.String(), "/missing.html: server responded 404") {
+			t.Errorf("Для -l %s ожидался код %d и ошибка 404, но получен код %d: %q", test.depth, cli.ExitFailure, code, stderr.String())
+		}
+		if files := listFiles(t, filepath.Join(dir, host)); !reflect.DeepEqual(files, test.expected) {
+			t.Errorf("Для -l %s ожидались файлы %q, но получено %q", test.depth, test.expected, files)
+		}
+	}
+
+	dir := t.TempDir()
+	var stdout bytes.Buffer
+	Main([]string{"-r", "-P", dir, server.URL}, cli.Stdio{Out: &stdout, Err: &bytes.Buffer{}})
+	data, err := os.ReadFile(filepath.Join(dir, host, "index.html"))
+	if err != nil || string(data) != fixtureSite["/"] {
+		t.Errorf("Содержимое index.html не совпадает с исходным: %q, %v", data, err)
+	}
+	if !strings.Contains(stdout.String(), "Downloaded 9 files") {
+		t.Errorf("Ожидалась сводка о 9 файлах, но получено %q", stdout.String())
+	}
+}
+
+func TestLocalPath(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"http://example.com", "out/example.com/index.html"},
+		{"http://example.com/", "out/example.com/index.html"},
+		{"http://example.com/a/b.html", "out/example.com/a/b.html"},
+		{"http://example.com/a/", "out/example.com/a/index.html"},
+		{"http://example.com:8080/x?q=1", "out/example.com:8080/x?q=1"},
+		{"http://example.com/../../etc/passwd", "out/example.com/etc/passwd"},
+		{"http://example.com/%D0%BF%D1%80%D0%B8%D0%B2%D0%B5%D1%82", "out/example.com/привет"},
+		{"http://example.com/a?/../../../../../tmp/evil", "out/example.com/a?%2F..%2F..%2F..%2F..%2F..%2Ftmp%2Fevil"},
+		{`http://example.com/a?..\..\evil`, `out/example.com/a?..%5C..%5Cevil`},
+		// Имя вне out/host — ошибка.
+		{"http://../a", ""},
+	}
+
+	for _, test := range tests {
+		u, err := url.Parse(test.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := localPath("out", u)
+		if test.expected == "" {
+			if err == nil {
+				t.Errorf("Для %q ожидалась ошибка, но получено %q", test.url, got)
+			}
+			continue
+		}
+		if err != nil || got != filepath.FromSlash(test.expected) {
+			t.Errorf("Для %q ожидалось %q, но получено %q (%v)", test.url, test.expected, got, err)
+		}
+	}
+}
+
+func TestMainSingleFile(t *testing.T) {
+	server := newFixtureServer(t, fixtureSite)
+	output := filepath.Join(t.TempDir(), "about.html")
+	var stdout, stderr bytes.Buffer
+	if code := Main([]string{"-output", output, server.URL + "/about.html"}, cli.Stdio{Out: &stdout, Err: &stderr}); code != cli.ExitOK {
+		t.Fatalf("Ожидался код 0, но получен %d: %q", code, stderr.String())
+	}
+	if data, _ := os.ReadFile(output); string(data) != fixtureSite["/about.html"] {
+		t.Errorf("Неверное содержимое файла: %q", data)
+	}
+	if code := Main([]string{"-output", output, server.URL + "/missing.html"}, cli.Stdio{Out: &stdout, Err: &stderr}); code != cli.ExitFailure {
+		t.Errorf("Для несуществующей страницы ожидался код 1, но получен %d", code)
+	}
+}
@@ -0,0 +1,154 @@
+package wget
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// result — чем закончилась загрузка файла.
+type result struct {
+	offset    int64 // с какого байта продолжена загрузка; 0 — файл загружен с начала
+	complete  bool  // -c: файл уже был загружен полностью, ничего не скачано
+	restarted bool  // -c: сервер не продолжил загрузку, и файл загружен заново
+}
+
+// wget скачивает страницу url в файл filename. С resume загрузка продолжается
+// с конца существующего файла (wget -c): серверу отправляется Range, а если
+// известны ETag или Last-Modified прерванной загрузки — ещё и If-Range. Ответ
+// 206 дописывается в конец файла; ответ 200 — файл изменился на сервере
+// или сервер не поддерживает Range — записывается заново.
+func wget(ctx context.Context, client *http.Client, url string, filename string, resume bool) (result, error) {
+	var res result
+	header := make(http.Header)
+	if resume {
+		if info, err := os.Stat(filename); err == nil && info.Mode().IsRegular() && info.Size() > 0 {
+			res.offset = info.Size()
+			header.Set("Range", fmt.Sprintf("bytes=%d-", res.offset))
+			if state, ok := loadResumeState(filename); ok && state.URL == url {
+				if validator := state.validator(); validator != "" {
+					header.Set("If-Range", validator)
+				}
+			}
+		}
+	}
+
+	resp, err := request(ctx, client, url, header)
+	if err != nil {
+		return res, err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	switch {
+	case res.offset > 0 && resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// Запрошен диапазон за концом файла: если размер на сервере совпадает
+		// с локальным, файл уже загружен.
+		if size, ok := completeLength(resp.Header.Get("Content-Range")); ok && size == res.offset {
+			removeResumeState(filename)
+			return result{offset: res.offset, complete: true}, nil
+		}
+		return res, fmt.Errorf("the local file is larger than the file on the server (%s)", resp.Status)
+	case res.offset > 0 && resp.StatusCode == http.StatusPartialContent:
+		if start, ok := rangeStart(resp.Header.Get("Content-Range")); !ok || start != res.offset {
+			return res, fmt.Errorf("server sent an unexpected range: %q", resp.Header.Get("Content-Range"))
+		}
+		flags = os.O_WRONLY | os.O_APPEND
+	case resp.StatusCode >= 200 && resp.StatusCode <= 299:
+		res.restarted = res.offset > 0
+		res.offset = 0
+	default:
+		return res, fmt.Errorf("server responded %s", resp.Status)
+	}
+
+	file, err := os.OpenFile(filename, flags, 0o666)
+	if err != nil {
+		return res, fmt.Errorf("error when creating a file: %v", err)
+	}
+	defer file.Close()
+	saveResumeState(filename, url, resp.Header)
+
+	if _, err = io.Copy(file, resp.Body); err != nil {
+		return res, fmt.Errorf("file write error: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		return res, fmt.Errorf("file write error: %v", err)
+	}
+	removeResumeState(filename)
+	return res, nil
+}
+
+// rangeStart возвращает первый байт из заголовка "Content-Range: bytes first-last/size".
+func rangeStart(contentRange string) (int64, bool) {
+	spec, ok := strings.CutPrefix(contentRange, "bytes ")
+	if !ok {
+		return 0, false
+	}
+	first, _, ok := strings.Cut(spec, "-")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(first, 10, 64)
+	return n, err == nil
+}
+
+// completeLength возвращает размер файла из заголовка "Content-Range: bytes */size",
+// которым сервер отвечает на диапазон за концом файла.
+func completeLength(contentRange string) (int64, bool) {
+	size, ok := strings.CutPrefix(contentRange, "bytes */")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(size, 10, 64)
+	return n, err == nil
+}
+
+// resumeState — сведения о незавершённой загрузке для -c: адрес и версия файла
+// на сервере. Хранятся рядом с файлом, пока загрузка не завершится.
+type resumeState struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// validator возвращает значение для If-Range: ETag или, если его нет или он
+// слабый (W/"..." в If-Range не допускается), Last-Modified.
+func (s resumeState) validator() string {
+	if s.ETag != "" && !strings.HasPrefix(s.ETag, "W/") {
+		return s.ETag
+	}
+	return s.LastModified
+}
+
+// resumeStatePath возвращает имя скрытого файла со сведениями о загрузке filename.
+func resumeStatePath(filename string) string {
+	return filepath.Join(filepath.Dir(filename), "."+filepath.Base(filename)+".wget")
+}
+
+func loadResumeState(filename string) (resumeState, bool) {
+	var state resumeState
+	data, err := os.ReadFile(resumeStatePath(filename))
+	if err != nil || json.Unmarshal(data, &state) != nil {
+		return state, false
+	}
+	return state, true
+}
+
+// saveResumeState запоминает версию загружаемого файла. Ошибка не мешает загрузке:
+// без сведений -c продолжит её без проверки If-Range.
+func saveResumeState(filename, url string, header http.Header) {
+	state := resumeState{URL: url, ETag: header.Get("ETag"), LastModified: header.Get("Last-Modified")}
+	if data, err := json.Marshal(state); err == nil {
+		os.WriteFile(resumeStatePath(filename), data, 0o644)
+	}
+}
+
+func removeResumeState(filename string) {
+	os.Remove(resumeStatePath(filename))
+}
@@ -0,0 +1,168 @@
+package wget
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"L2/internal/cli"
+)
+
+func TestResume(t *testing.T) {
+	content := strings.Repeat("0123456789", 100)
+	modTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var ranges []string
+	var broken atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range")+"|"+r.Header.Get("If-Range"))
+		switch r.URL.Path {
+		case "/file", "/changed":
+			etag := `"v1"`
+			if r.URL.Path == "/changed" {
+				etag = `"v2"`
+			}
+			w.Header().Set("ETag", etag)
+			if broken.Load() {
+				// Соединение обрывается посреди ответа.
+				w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+				w.Write([]byte(content[:300]))
+				w.(http.Flusher).Flush()
+				panic(http.ErrAbortHandler)
+			}
+			http.ServeContent(w, r, "file", modTime, strings.NewReader(content))
+		case "/norange":
+			w.Write([]byte(content))
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	output := filepath.Join(dir, "file")
+	state := resumeStatePath(output)
+	run := func(args ...string) (int, string) {
+		var stdout, stderr bytes.Buffer
+		code := Main(append([]string{"-output", output}, args...), cli.Stdio{Out: &stdout, Err: &stderr})
+		return code, stdout.String() + stderr.String()
+	}
+	check := func(name string, expected string) {
+		t.Helper()
+		data, err := os.ReadFile(output)
+		if err != nil || string(data) != expected {
+			t.Errorf("%s: ожидалось %d байт, но получено %d (%v)", name, len(expected), len(data), err)
+		}
+	}
+
+	// Оборванная загрузка оставляет начало файла и сведения о версии, -c её продолжает.
+	broken.Store(true)
+	if code, out := run(server.URL + "/file"); code != cli.ExitFailure {
+		t.Fatalf("Оборванная загрузка должна завершиться ошибкой, но получен код %d: %s", code, out)
+	}
+	broken.Store(false)
+	check("оборванная загрузка", content[:300])
+	if _, err := os.Stat(state); err != nil {
+		t.Fatalf("После обрыва должны остаться сведения о загрузке: %v", err)
+	}
+	ranges = nil
+	if code, out := run("-c", server.URL+"/file"); code != cli.ExitOK || !strings.Contains(out, "continued from byte 300") {
+		t.Errorf("Ожидалось продолжение с 300 байта, но получен код %d: %s", code, out)
+	}
+	check("продолжение", content)
+	if len(ranges) != 1 || ranges[0] != `bytes=300-|"v1"` {
+		t.Errorf("Ожидался запрос с Range и If-Range, но получено %q", ranges)
+	}
+	if _, err := os.Stat(state); err == nil {
+		t.Error("После завершения загрузки сведения о ней должны удаляться")
+	}
+
+	tests := []struct {
+		name     string
+		partial  string
+		state    *resumeState
+		args     []string
+		expected string
+		request  string
+		message  string
+	}{
+		{"без сведений о версии", content[:500], nil, []string{"-c", "/file"}, content, "bytes=500-|", "continued from byte 500"},
+		{"по Last-Modified", content[:500], &resumeState{URL: "/file", ETag: `W/"weak"`, LastModified: modTime.Format(http.TimeFormat)},
+			[]string{"-c", "/file"}, content, "bytes=500-|" + modTime.Format(http.TimeFormat), "continued from byte 500"},
+		{"файл изменился", "old", &resumeState{URL: "/changed", ETag: `"v1"`}, []string{"-c", "/changed"}, content, `bytes=3-|"v1"`, "downloaded from the start"},
+		{"сервер без Range", content[:500], nil, []string{"-c", "/norange"}, content, "bytes=500-|", "downloaded from the start"},
+		{"файл уже загружен", content, nil, []string{"-c", "/file"}, content, "bytes=1000-|", "already fully retrieved"},
+		{"без -c", "old", nil, []string{"/file"}, content, "|", "Downloaded: "},
+	}
+	for _, test := range tests {
+		os.WriteFile(output, []byte(test.partial), 0o644)
+		os.Remove(state)
+		if test.state != nil {
+			test.state.URL = server.URL + test.state.URL
+			saveResumeState(output, test.state.URL, http.Header{"Etag": {test.state.ETag}, "Last-Modified": {test.state.LastModified}})
+		}
+		ranges = nil
+		args := append([]string{}, test.args...)
+		args[len(args)-1] = server.URL + args[len(args)-1]
+		code, out := run(args...)
+		if code != cli.ExitOK || !strings.Contains(out, test.message) {
+			t.Errorf("%s: ожидался код 0 и сообщение %q, но получен код %d: %s", test.name, test.message, code, out)
+		}
+		check(test.name, test.expected)
+		if len(ranges) != 1 || ranges[0] != test.request {
+			t.Errorf("%s: ожидался запрос %q, но получено %q", test.name, test.request, ranges)
+		}
+	}
+
+	// Локальный файл больше файла на сервере — ошибка, файл не трогается.
+	os.WriteFile(output, []byte(content+"extra"), 0o644)
+	if code, _ := run("-c", server.URL+"/file"); code != cli.ExitFailure {
+		t.Errorf("Для файла больше серверного ожидался код 1, но получен %d", code)
+	}
+	check("файл больше серверного", content+"extra")
+}
+
+func TestRangeHeaders(t *testing.T) {
+	if n, ok := rangeStart("bytes 300-999/1000"); !ok || n != 300 {
+		t.Errorf("rangeStart вернул %d, %v", n, ok)
+	}
+	if _, ok := rangeStart("items 1-2/3"); ok {
+		t.Error("rangeStart должен отклонять единицы, отличные от bytes")
+	}
+	if n, ok := completeLength("bytes */1000"); !ok || n != 1000 {
+		t.Errorf("completeLength вернул %d, %v", n, ok)
+	}
+	if _, ok := completeLength("bytes 0-1/2"); ok {
+		t.Error("completeLength должен принимать только bytes */size")
+	}
+}
+
+func TestResumeCancel(t *testing.T) {
+	// Прерванная загрузка без -c оставляет начало файла, и её можно продолжить.
+	ctx, cancel := context.WithCancel(context.Background())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Last-Modified", "Wed, 01 May 2024 12:00:00 GMT")
+		w.Write([]byte("partial"))
+		w.(http.Flusher).Flush()
+		time.AfterFunc(100*time.Millisecond, cancel)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	output := filepath.Join(t.TempDir(), "file")
+	if _, err := wget(ctx, http.DefaultClient, server.URL, output, false); err == nil {
+		t.Fatal("Ожидалась ошибка прерванной загрузки")
+	}
+	state, ok := loadResumeState(output)
+	if !ok || state.URL != server.URL || state.validator() != "Wed, 01 May 2024 12:00:00 GMT" {
+		t.Errorf("Сведения о прерванной загрузке неверны: %+v, %v", state, ok)
+	}
+	if data, _ := os.ReadFile(output); string(data) != "partial" {
+		t.Errorf("Должно остаться начало файла, но получено %q", data)
+	}
+}
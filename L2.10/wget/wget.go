@@ -0,0 +1,136 @@
+// Package wget реализует утилиту wget из задания L2.10: загрузку одной страницы
+// в файл и, с -r, рекурсивную загрузку сайта с сохранением структуры каталогов.
+package wget
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"L2/internal/cli"
+)
+
+// WgetOptions хранит значения ключей командной строки.
+type WgetOptions struct {
+	url       string
+	output    string
+	resume    bool
+	recursive bool
+	depth     int
+	prefix    string
+
+	convertLinks    bool
+	adjustExtension bool
+
+	workers    int
+	perHost    int
+	wait       time.Duration
+	randomWait bool
+}
+
+func parseWgetOptions(args []string, stdio cli.Stdio) (WgetOptions, int, bool) {
+	var opts WgetOptions
+	fs := cli.NewFlagSet("wget", stdio)
+	fs.StringVar(&opts.url, "url", "", "URL веб-страницы для скачивания (или первый аргумент)")
+	fs.StringVar(&opts.output, "output", "output.html", "Имя файла для сохранения")
+	fs.BoolVar(&opts.resume, "c", false, "Продолжить прерванную загрузку файла -output с того места, где она остановилась")
+	fs.BoolVar(&opts.recursive, "r", false, "Скачать сайт рекурсивно: страницы, картинки, стили и скрипты с того же хоста")
+	fs.IntVar(&opts.depth, "l", 5, "Максимальная глубина рекурсии для -r, 0 — без ограничения")
+	fs.StringVar(&opts.prefix, "P", ".", "Каталог, в котором для -r создаётся каталог сайта")
+	fs.BoolVar(&opts.convertLinks, "k", false, "После -r переписать ссылки в HTML и CSS на локальные файлы")
+	fs.BoolVar(&opts.convertLinks, "convert-links", false, "То же, что -k")
+	fs.BoolVar(&opts.adjustExtension, "E", false, "Для -r добавлять .html к именам HTML-страниц")
+	fs.BoolVar(&opts.adjustExtension, "adjust-extension", false, "То же, что -E")
+	fs.IntVar(&opts.workers, "j", 8, "Число одновременных загрузок для -r")
+	fs.IntVar(&opts.perHost, "per-host", 4, "Число одновременных загрузок с одного хоста")
+	fs.Func("wait", "Пауза между запросами к одному хосту: секунды или число с суффиксом s, m, h, d", func(s string) error {
+		var err error
+		opts.wait, err = cli.ParseDuration(s)
+		return err
+	})
+	fs.BoolVar(&opts.randomWait, "random-wait", false, "Случайная пауза от 0.5 до 1.5 значения --wait")
+	if code, ok := cli.ParseFlags(fs, args); !ok {
+		return opts, code, false
+	}
+	if opts.url == "" {
+		opts.url = fs.Arg(0)
+	}
+	if opts.url == "" {
+		fmt.Fprintln(stdio.Err, "Usage: wget [-r [-l depth] [-P dir] [-k] [-E] [-j n] [--per-host n] [--wait t] [--random-wait]] [-c] [-output <filename>] <URL>")
+		return opts, cli.ExitUsage, false
+	}
+	if opts.depth < 0 {
+		fmt.Fprintln(stdio.Err, "wget: -l must not be negative")
+		return opts, cli.ExitUsage, false
+	}
+	if opts.workers < 1 || opts.perHost < 1 {
+		fmt.Fprintln(stdio.Err, "wget: -j and --per-host must be positive")
+		return opts, cli.ExitUsage, false
+	}
+	return opts, cli.ExitOK, true
+}
+
+// Main запускает wget с аргументами args.
+func Main(args []string, stdio cli.Stdio) int {
+	opts, code, ok := parseWgetOptions(args, stdio)
+	if !ok {
+		return code
+	}
+	// Первый Ctrl+C останавливает загрузку, второй — завершает процесс сразу.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	context.AfterFunc(ctx, stop)
+	if opts.recursive {
+		return runMirror(ctx, opts, stdio)
+	}
+
+	res, err := wget(ctx, http.DefaultClient, opts.url, opts.output, opts.resume)
+	if err != nil {
+		return cli.Errorf(stdio, "wget", cli.ExitFailure, "%v", err)
+	}
+	switch {
+	case res.complete:
+		fmt.Fprintf(stdio.Out, "The file %s is already fully retrieved, nothing to do\n", opts.output)
+	case res.offset > 0:
+		fmt.Fprintf(stdio.Out, "Downloaded: %s to file %s, continued from byte %d\n", opts.url, opts.output, res.offset)
+	default:
+		if res.restarted {
+			fmt.Fprintln(stdio.Out, "The file has changed on the server or the server does not support ranges, downloaded from the start")
+		}
+		fmt.Fprintf(stdio.Out, "Downloaded: %s to file %s\n", opts.url, opts.output)
+	}
+	return cli.ExitOK
+}
+
+// get выполняет GET-запрос. Ответ с кодом не из 2xx считается ошибкой.
+func get(ctx context.Context, client *http.Client, url string) (*http.Response, error) {
+	resp, err := request(ctx, client, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("server responded %s", resp.Status)
+	}
+	return resp, nil
+}
+
+// request выполняет GET-запрос с дополнительными заголовками header, не проверяя код ответа.
+func request(ctx context.Context, client *http.Client, url string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %v", err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to establish a connection to the site: %v", err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,172 @@
+// Package sorter реализует утилиту sort из задания L2.4.
+package sorter
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"L2/internal/cli"
+)
+
+type SortOptions struct {
+	column       int
+	numeric      bool
+	reverseOrder bool
+	uniqueOnly   bool
+	outputFile   string
+}
+
+type SortableLines struct {
+	lines   []string
+	options SortOptions
+}
+
+func (s SortableLines) Len() int           { return len(s.lines) }
+func (s SortableLines) Swap(i, j int)      { s.lines[i], s.lines[j] = s.lines[j], s.lines[i] }
+func (s SortableLines) Less(i, j int) bool { return lineComparison(s.lines[i], s.lines[j], s.options) }
+
+// Main запускает sort с аргументами args.
+// Если файл не указан, строки читаются из stdin. Результат печатается в stdout,
+// а с -o FILE записывается в FILE (-o - — тоже stdout), как у sort из coreutils:
+// в multi-call бинаре l2 утилиты соединяются конвейерами, поэтому вывод идёт в stdout.
+// Чтобы отсортировать файл на месте, его передают и в -o: sort -o file.txt file.txt.
+func Main(args []string, stdio cli.Stdio) int {
+	opts, code, ok := parseCommandLine(args, stdio)
+	if !ok {
+		return code
+	}
+	filePath := opts.inputFile
+
+	lines, err := loadLines(filePath, stdio.In)
+	if err != nil {
+		return cli.Errorf(stdio, "sort", cli.ExitFailure, "Ошибка загрузки файла: %v", err)
+	}
+
+	sortedLines, err := sortLines(lines, opts.SortOptions)
+	if err != nil {
+		return cli.Errorf(stdio, "sort", cli.ExitFailure, "Ошибка сортировки: %v", err)
+	}
+
+	switch {
+	case opts.outputFile != "" && opts.outputFile != "-":
+		err = saveLines(opts.outputFile, sortedLines)
+	default:
+		err = writeLines(stdio.Out, sortedLines)
+	}
+	if err != nil {
+		return cli.Errorf(stdio, "sort", cli.ExitFailure, "Ошибка записи: %v", err)
+	}
+	return cli.ExitOK
+}
+
+type commandLine struct {
+	SortOptions
+	inputFile string
+}
+
+func parseCommandLine(args []string, stdio cli.Stdio) (commandLine, int, bool) {
+	var opts commandLine
+	fs := cli.NewFlagSet("sort", stdio)
+	fs.IntVar(&opts.column, "k", 1, "Указать колонку для сортировки")
+	fs.BoolVar(&opts.numeric, "n", false, "Числовая сортировка")
+	fs.BoolVar(&opts.reverseOrder, "r", false, "Обратный порядок сортировки")
+	fs.BoolVar(&opts.uniqueOnly, "u", false, "Только уникальные строки")
+	fs.StringVar(&opts.outputFile, "o", "", "Файл для записи результата ('-' — stdout)")
+	if code, ok := cli.ParseFlags(fs, args); !ok {
+		return opts, code, false
+	}
+	opts.inputFile = fs.Arg(0)
+	return opts, cli.ExitOK, true
+}
+
+func loadLines(filePath string, stdin io.Reader) ([]string, error) {
+	file, err := cli.OpenInput(filePath, stdin)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return cli.ReadLines(file)
+}
+
+func saveLines(filePath string, lines []string) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	if err := writeLines(file, lines); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func writeLines(w io.Writer, lines []string) error {
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func lineComparison(line1, line2 string, opts SortOptions) bool {
+	parts1 := strings.Fields(line1)
+	parts2 := strings.Fields(line2)
+
+	if opts.column > len(parts1) || opts.column > len(parts2) {
+		return line1 < line2
+	}
+
+	var comparison int
+	if opts.numeric {
+		num1, err1 := strconv.Atoi(parts1[opts.column-1])
+		num2, err2 := strconv.Atoi(parts2[opts.column-1])
+		if err1 != nil || err2 != nil {
+			comparison = strings.Compare(parts1[opts.column-1], parts2[opts.column-1])
+		} else {
+			comparison = num1 - num2
+		}
+	} else {
+		comparison = strings.Compare(parts1[opts.column-1], parts2[opts.column-1])
+	}
+
+	return comparison < 0
+}
+
+func uniqueLines(lines []string) []string {
+	existing := make(map[string]struct{})
+	var result []string
+	for _, line := range lines {
+		if _, found := existing[line]; !found {
+			existing[line] = struct{}{}
+			result = append(result, line)
+		}
+	}
+	return result
+}
+
+func reverseOrder(lines []string) {
+	for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+		lines[i], lines[j] = lines[j], lines[i]
+	}
+}
+
+func sortLines(lines []string, opts SortOptions) ([]string, error) {
+	if opts.uniqueOnly {
+		lines = uniqueLines(lines)
+	}
+
+	sortable := SortableLines{lines: lines, options: opts}
+	sort.Sort(sortable)
+
+	if opts.reverseOrder {
+		reverseOrder(lines)
+	}
+
+	return lines, nil
+}
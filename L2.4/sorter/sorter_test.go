@@ -0,0 +1,72 @@
+package sorter
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"L2/internal/cli"
+)
+
+func TestMainOutput(t *testing.T) {
+	const input = "b\na\nc\n"
+
+	tests := []struct {
+		name     string
+		args     []string // INPUT и OUTPUT заменяются путями к файлам
+		expected string   // ожидаемый stdout
+		output   string   // ожидаемое содержимое OUTPUT
+	}{
+		{"файл в stdout", []string{"INPUT"}, "a\nb\nc\n", ""},
+		{"-o -", []string{"-r", "-o", "-", "INPUT"}, "c\nb\na\n", ""},
+		{"-o в файл", []string{"-o", "OUTPUT", "INPUT"}, "", "a\nb\nc\n"},
+	}
+
+	for _, test := range tests {
+		dir := t.TempDir()
+		inputFile := filepath.Join(dir, "input.txt")
+		outputFile := filepath.Join(dir, "output.txt")
+		if err := os.WriteFile(inputFile, []byte(input), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		args := make([]string, len(test.args))
+		for i, arg := range test.args {
+			switch arg {
+			case "INPUT":
+				arg = inputFile
+			case "OUTPUT":
+				arg = outputFile
+			}
+			args[i] = arg
+		}
+
+		var out, errOut bytes.Buffer
+		if code := Main(args, cli.Stdio{In: bytes.NewReader(nil), Out: &out, Err: &errOut}); code != cli.ExitOK {
+			t.Errorf("%s: ожидался код %d, получен %d (stderr: %s)", test.name, cli.ExitOK, code, errOut.String())
+			continue
+		}
+		if out.String() != test.expected {
+			t.Errorf("%s: ожидалось %q, получено %q", test.name, test.expected, out.String())
+		}
+		if data, _ := os.ReadFile(inputFile); string(data) != input {
+			t.Errorf("%s: входной файл изменён: %q", test.name, data)
+		}
+		if data, _ := os.ReadFile(outputFile); string(data) != test.output {
+			t.Errorf("%s: в %s ожидалось %q, получено %q", test.name, outputFile, test.output, data)
+		}
+	}
+
+	// Сортировка на месте: входной файл передаётся и в -o.
+	file := filepath.Join(t.TempDir(), "input.txt")
+	if err := os.WriteFile(file, []byte(input), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var out, errOut bytes.Buffer
+	if code := Main([]string{"-o", file, file}, cli.Stdio{Out: &out, Err: &errOut}); code != cli.ExitOK || out.Len() != 0 {
+		t.Errorf("-o file file: ожидался код 0 без вывода, получен код %d и %q (stderr: %s)", code, out.String(), errOut.String())
+	}
+	if data, _ := os.ReadFile(file); string(data) != "a\nb\nc\n" {
+		t.Errorf("-o file file: ожидалось %q, получено %q", "a\nb\nc\n", data)
+	}
+}
@@ -0,0 +1,154 @@
+// Package grep реализует утилиту grep из задания L2.6.
+package grep
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"L2/internal/cli"
+)
+
+// Опции для утилиты grep
+type GrepOptions struct {
+	afterContext  int
+	beforeContext int
+	context       int
+	countOnly     bool
+	ignoreCase    bool
+	invertMatch   bool
+	fixedMatch    bool
+	printLineNum  bool
+}
+
+// Парсинг опций командной строки
+func parseGrepOptions(args []string, stdio cli.Stdio) (GrepOptions, []string, int, bool) {
+	var opts GrepOptions
+	fs := cli.NewFlagSet("grep", stdio)
+	fs.IntVar(&opts.afterContext, "A", 0, "Печатать +N строк после совпадения")
+	fs.IntVar(&opts.beforeContext, "B", 0, "Печатать +N строк до совпадения")
+	fs.IntVar(&opts.context, "C", 0, "Печатать ±N строк вокруг совпадения")
+	fs.BoolVar(&opts.countOnly, "c", false, "Выводить только количество совпадений")
+	fs.BoolVar(&opts.ignoreCase, "i", false, "Игнорировать регистр")
+	fs.BoolVar(&opts.invertMatch, "v", false, "Инвертировать совпадение")
+	fs.BoolVar(&opts.fixedMatch, "F", false, "Точное совпадение строки")
+	fs.BoolVar(&opts.printLineNum, "n", false, "Печатать номер строки")
+	if code, ok := cli.ParseFlags(fs, args); !ok {
+		return opts, nil, code, false
+	}
+
+	// Если указан флаг -C, устанавливаем значения -A и -B
+	if opts.context > 0 {
+		opts.beforeContext = opts.context
+		opts.afterContext = opts.context
+	}
+
+	return opts, fs.Args(), cli.ExitOK, true
+}
+
+// Основная функция для обработки поиска. Возвращает количество совпавших строк.
+func grep(w io.Writer, lines []string, pattern string, opts GrepOptions) (int, error) {
+	if opts.ignoreCase {
+		pattern = strings.ToLower(pattern)
+	}
+
+	var regex *regexp.Regexp
+	var err error
+	if !opts.fixedMatch {
+		regex, err = regexp.Compile(pattern)
+		if err != nil {
+			return 0, fmt.Errorf("Ошибка компиляции регулярного выражения: %v", err)
+		}
+	}
+
+	lineCount := len(lines)
+	var count int
+
+	for i, line := range lines {
+		lineToMatch := line
+		if opts.ignoreCase {
+			lineToMatch = strings.ToLower(line)
+		}
+
+		match := (opts.fixedMatch && lineToMatch == pattern) || (!opts.fixedMatch && regex.MatchString(lineToMatch))
+		if opts.invertMatch {
+			match = !match
+		}
+
+		if match {
+			count++
+			if opts.countOnly {
+				continue
+			}
+
+			start := max(0, i-opts.beforeContext)
+			end := min(lineCount, i+opts.afterContext+1)
+
+			for j := start; j < end; j++ {
+				if opts.printLineNum {
+					fmt.Fprintf(w, "%d:%s\n", j+1, lines[j])
+				} else {
+					fmt.Fprintln(w, lines[j])
+				}
+			}
+		}
+	}
+
+	if opts.countOnly {
+		fmt.Fprintln(w, count)
+	}
+	return count, nil
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// Main запускает grep с аргументами args: grep [флаги] PATTERN [FILE].
+// Если файл не указан, строки читаются из stdin.
+// Код завершения как у grep: 0 — есть совпадения, 1 — совпадений нет, 2 — ошибка.
+func Main(args []string, stdio cli.Stdio) int {
+	opts, operands, code, ok := parseGrepOptions(args, stdio)
+	if !ok {
+		return code
+	}
+	if len(operands) == 0 {
+		return cli.Errorf(stdio, "grep", cli.ExitUsage, "Укажите шаблон для поиска.")
+	}
+	pattern := operands[0]
+	filePath := ""
+	if len(operands) > 1 {
+		filePath = operands[1]
+	}
+
+	file, err := cli.OpenInput(filePath, stdio.In)
+	if err != nil {
+		return cli.Errorf(stdio, "grep", cli.ExitUsage, "Ошибка открытия файла: %v", err)
+	}
+	defer file.Close()
+
+	lines, err := cli.ReadLines(file)
+	if err != nil {
+		return cli.Errorf(stdio, "grep", cli.ExitUsage, "Ошибка чтения файла: %v", err)
+	}
+
+	count, err := grep(stdio.Out, lines, pattern, opts)
+	if err != nil {
+		return cli.Errorf(stdio, "grep", cli.ExitUsage, "%v", err)
+	}
+	if count == 0 {
+		return cli.ExitFailure
+	}
+	return cli.ExitOK
+}
@@ -0,0 +1,110 @@
+package awk
+
+import "regexp"
+
+// program — разобранная awk-программа: блоки BEGIN, правила "шаблон { действие }" и блоки END.
+type program struct {
+	begin [][]stmt
+	rules []rule
+	end   [][]stmt
+}
+
+// rule — правило программы. Пустой pattern совпадает с любой записью,
+// а nil action означает действие по умолчанию: print $0.
+type rule struct {
+	pattern expr
+	action  []stmt
+}
+
+type stmt interface{ stmtNode() }
+
+type (
+	printStmt struct{ args []expr }
+	exprStmt  struct{ e expr }
+	blockStmt struct{ body []stmt }
+	nextStmt  struct{}
+	ifStmt    struct {
+		cond      expr
+		then, els []stmt
+	}
+	forInStmt struct {
+		key, array string
+		body       []stmt
+	}
+)
+
+func (*printStmt) stmtNode() {}
+func (*exprStmt) stmtNode()  {}
+func (*blockStmt) stmtNode() {}
+func (*nextStmt) stmtNode()  {}
+func (*ifStmt) stmtNode()    {}
+func (*forInStmt) stmtNode() {}
+
+type expr interface{ exprNode() }
+
+type (
+	numberExpr struct{ value float64 }
+	stringExpr struct{ value string }
+	// regexExpr вне оператора ~ означает проверку $0 ~ /re/.
+	regexExpr struct{ re *regexp.Regexp }
+	fieldExpr struct{ index expr }
+	varExpr   struct{ name string }
+	indexExpr struct {
+		array string
+		key   expr
+	}
+	assignExpr struct {
+		target expr
+		op     tokenKind
+		value  expr
+	}
+	incrExpr struct {
+		target expr
+		op     tokenKind
+		prefix bool
+	}
+	unaryExpr struct {
+		op      tokenKind
+		operand expr
+	}
+	binaryExpr struct {
+		op          tokenKind
+		left, right expr
+	}
+	matchExpr struct {
+		left, re expr
+		negate   bool
+	}
+	concatExpr struct{ left, right expr }
+	inExpr     struct {
+		key   expr
+		array string
+	}
+	callExpr struct {
+		name string
+		args []expr
+	}
+)
+
+func (*numberExpr) exprNode() {}
+func (*stringExpr) exprNode() {}
+func (*regexExpr) exprNode()  {}
+func (*fieldExpr) exprNode()  {}
+func (*varExpr) exprNode()    {}
+func (*indexExpr) exprNode()  {}
+func (*assignExpr) exprNode() {}
+func (*incrExpr) exprNode()   {}
+func (*unaryExpr) exprNode()  {}
+func (*binaryExpr) exprNode() {}
+func (*matchExpr) exprNode()  {}
+func (*concatExpr) exprNode() {}
+func (*inExpr) exprNode()     {}
+func (*callExpr) exprNode()   {}
+
+func isLValue(e expr) bool {
+	switch e.(type) {
+	case *varExpr, *fieldExpr, *indexExpr:
+		return true
+	}
+	return false
+}
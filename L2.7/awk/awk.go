@@ -0,0 +1,107 @@
+// Package awk реализует упрощённый awk для обработки полей, дополняющий утилиту cut.
+//
+// Поддерживаются правила "шаблон { действие }", блоки BEGIN и END, ссылки на поля
+// ($1, $NF, $0), переменные и ассоциативные массивы, числовые и строковые сравнения,
+// проверка регулярных выражений (/re/, ~, !~), арифметика и присваивания (+=, ++),
+// конкатенация, print, if/else, for (k in arr), next и функции length, substr, index,
+// tolower, toupper, int. Агрегаты считаются обычными переменными:
+//
+//	awk -F , '$3 > 100 { sum += $3; count++ } END { print sum, count }'
+package awk
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"L2/internal/cli"
+)
+
+// assignments — значения ключа -v var=value, который можно указать несколько раз.
+type assignments []string
+
+func (a *assignments) String() string { return strings.Join(*a, ",") }
+
+func (a *assignments) Set(s string) error {
+	if name, _, ok := strings.Cut(s, "="); !ok || name == "" {
+		return fmt.Errorf("expected var=value, got %q", s)
+	}
+	*a = append(*a, s)
+	return nil
+}
+
+// Main запускает awk с аргументами args: awk [-F fs] [-v var=value] 'program' [file ...].
+// Без файлов строки читаются из stdin, "-" тоже означает stdin.
+func Main(args []string, stdio cli.Stdio) int {
+	var fieldSeparator string
+	var vars assignments
+	fs := cli.NewFlagSet("awk", stdio)
+	fs.StringVar(&fieldSeparator, "F", " ", "Field separator: one character, or a regular expression")
+	fs.Var(&vars, "v", "Assign a variable before the program starts, e.g. -v limit=100")
+	if code, ok := cli.ParseFlags(fs, splitAttachedSeparator(args)); !ok {
+		return code
+	}
+	if fs.NArg() == 0 {
+		return cli.Errorf(stdio, "awk", cli.ExitUsage, "missing program text")
+	}
+
+	prog, err := parse(fs.Arg(0))
+	if err != nil {
+		return cli.Errorf(stdio, "awk", cli.ExitUsage, "%v", err)
+	}
+
+	in := newInterpreter(prog, stdio.Out)
+	in.setVar("FS", strValue(unescapeSeparator(fieldSeparator)))
+	for _, assignment := range vars {
+		name, val, _ := strings.Cut(assignment, "=")
+		in.setVar(name, inputValue(val))
+	}
+
+	paths := fs.Args()[1:]
+	if len(paths) == 0 {
+		paths = []string{"-"}
+	}
+	var inputs []io.Reader
+	for _, path := range paths {
+		file, err := cli.OpenInput(path, stdio.In)
+		if err != nil {
+			return cli.Errorf(stdio, "awk", cli.ExitFailure, "%v", err)
+		}
+		defer file.Close()
+		inputs = append(inputs, file)
+	}
+
+	if err := in.run(inputs); err != nil {
+		return cli.Errorf(stdio, "awk", cli.ExitFailure, "%v", err)
+	}
+	return cli.ExitOK
+}
+
+// splitAttachedSeparator разрешает привычную для awk запись -F, (без пробела),
+// которую пакет flag не понимает.
+func splitAttachedSeparator(args []string) []string {
+	var result []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--" || !strings.HasPrefix(arg, "-"):
+			return append(result, args[i:]...)
+		case (arg == "-F" || arg == "-v") && i+1 < len(args):
+			result = append(result, arg, args[i+1])
+			i++
+		case strings.HasPrefix(arg, "-F") && len(arg) > 2 && arg[2] != '=':
+			result = append(result, "-F", arg[2:])
+		default:
+			result = append(result, arg)
+		}
+	}
+	return result
+}
+
+// unescapeSeparator позволяет передать табуляцию как -F '\t'.
+func unescapeSeparator(fs string) string {
+	if fs == `\t` {
+		return "\t"
+	}
+	return fs
+}
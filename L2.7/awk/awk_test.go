@@ -0,0 +1,177 @@
+package awk
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"L2/internal/cli"
+)
+
+func runProgram(t *testing.T, src, input, fs string) (string, error) {
+	t.Helper()
+	prog, err := parse(src)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	in := newInterpreter(prog, &out)
+	if fs != "" {
+		in.setVar("FS", strValue(fs))
+	}
+	err = in.run([]io.Reader{strings.NewReader(input)})
+	return out.String(), err
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected []tokenKind
+	}{
+		{`$3 > 100`, []tokenKind{tokDollar, tokNumber, tokGt, tokNumber, tokEOF}},
+		{`/a\/b/ { n++ }`, []tokenKind{tokRegex, tokLBrace, tokName, tokIncr, tokRBrace, tokEOF}},
+		{`x / 2 / 3`, []tokenKind{tokName, tokSlash, tokNumber, tokSlash, tokNumber, tokEOF}},
+		{`$1 !~ "a" && b != 1e3`, []tokenKind{tokDollar, tokNumber, tokNotMatch, tokString, tokAnd, tokName, tokNe, tokNumber, tokEOF}},
+		{"BEGIN { s += 1 } # comment\nEND", []tokenKind{tokBegin, tokLBrace, tokName, tokAddAssign, tokNumber, tokRBrace, tokNewline, tokEnd, tokEOF}},
+		{`length($0)`, []tokenKind{tokFunc, tokLParen, tokDollar, tokNumber, tokRParen, tokEOF}},
+	}
+
+	for _, test := range tests {
+		tokens, err := tokenize(test.src)
+		if err != nil {
+			t.Errorf("%q: не ожидалась ошибка: %v", test.src, err)
+			continue
+		}
+		var kinds []tokenKind
+		for _, tok := range tokens {
+			kinds = append(kinds, tok.kind)
+		}
+		if len(kinds) != len(test.expected) {
+			t.Errorf("%q: ожидалось %v, получено %v", test.src, test.expected, kinds)
+			continue
+		}
+		for i := range kinds {
+			if kinds[i] != test.expected[i] {
+				t.Errorf("%q: ожидалось %v, получено %v", test.src, test.expected, kinds)
+				break
+			}
+		}
+	}
+
+	tokens, _ := tokenize(`"a\tb\"c"`)
+	if tokens[0].text != "a\tb\"c" {
+		t.Errorf("escape-последовательности в строке обработаны неверно: %q", tokens[0].text)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	programs := []string{
+		`{ print $1`,
+		`$1 > { print }`,
+		`{ print "unterminated }`,
+		`{ 1 = 2 }`,
+		`{ print $1 > "file" }`,
+		`/unterminated`,
+		`{ x = substr("a") }`,
+		`{ for (k a) print k }`,
+		`{ if x print }`,
+		`{ print @ }`,
+		`/(/`,
+	}
+	for _, src := range programs {
+		if _, err := parse(src); err == nil {
+			t.Errorf("%q: ожидалась синтаксическая ошибка", src)
+		}
+	}
+}
+
+func TestPrograms(t *testing.T) {
+	const sales = "alice 120 north\nbob 80 south\ncarol 300 north\ndave 100 east\n"
+
+	tests := []struct {
+		name     string
+		src      string
+		input    string
+		fs       string
+		expected string
+	}{
+		{"numeric filter", `$2 > 100 { print $1, $2 }`, sales, "", "alice 120\ncarol 300\n"},
+		{"default action", `$3 == "north"`, sales, "", "alice 120 north\ncarol 300 north\n"},
+		{"regex pattern", `/^[bc]/ { print $1 }`, sales, "", "bob\ncarol\n"},
+		{"match operator", `$1 ~ "a" && $1 !~ /^d/ { print $1 }`, sales, "", "alice\ncarol\n"},
+		{"sum and count", `{ s += $2; n++ } END { print s, n, s / n }`, sales, "", "600 4 150\n"},
+		{"group by", `{ t[$3] += $2 } END { for (k in t) print k, t[k] }`, sales, "", "east 100\nnorth 420\nsouth 80\n"},
+		{"begin only", `BEGIN { print 1 + 2 * 3, 7 % 4, -2 - 1 }`, "", "", "7 3 -3\n"},
+		{"concatenation", `{ print $1 "-" NR }`, "x\ny\n", "", "x-1\ny-2\n"},
+		{"NF and $NF", `{ print NF, $NF, $(NF-1) }`, "a b c\n", "", "3 c b\n"},
+		{"field separator", `{ print $2 }`, "a,b,c\n", ",", "b\n"},
+		{"regex separator", `{ print $2 }`, "a1b22c\n", "[0-9]+", "b\n"},
+		{"string comparison", `$1 < "b" { print }`, "a\nb\nc\n", "", "a\n"},
+		{"numeric vs string fields", `$1 < $2 { print "lt" }`, "9 10\n", "", "lt\n"},
+		{"if else and next", `{ if ($1 > 1) print "big"; else { print "small"; next }; print "after" }`, "1\n2\n", "", "small\nbig\nafter\n"},
+		{"field assignment", `{ $2 = "X"; print; print NF }`, "a b c\n", "", "a X c\n3\n"},
+		{"builtins", `{ print length($1), substr($1, 2, 3), index($1, "c"), toupper($1), int(3.9) }`, "abcdef\n", "", "6 bcd 3 ABCDEF 3\n"},
+		{"in operator", `{ seen[$1]++ } END { if ("a" in seen) print seen["a"] }`, "a\nb\na\n", "", "2\n"},
+		{"uninitialized", `END { print x + 0, "[" x "]", (x == 0), (x == "") }`, "", "", "0 [] 1 1\n"},
+		{"print parens", `{ print($2, $1) }`, "a b\n", "", "b a\n"},
+		{"comparison in parens", `{ print ($1 > 2) }`, "3\n", "", "1\n"},
+		{"floating point", `{ print $1 / 3 }`, "1\n", "", "0.333333\n"},
+		{"empty line has no fields", `{ print NF }`, "\n   \n", "", "0\n0\n"},
+		{"multi-line program", "$2 >= 100 {\n\tcount++\n}\nEND {\n\tprint count\n}", sales, "", "3\n"},
+	}
+
+	for _, test := range tests {
+		out, err := runProgram(t, test.src, test.input, test.fs)
+		if err != nil {
+			t.Errorf("%s: не ожидалась ошибка: %v", test.name, err)
+			continue
+		}
+		if out != test.expected {
+			t.Errorf("%s: ожидалось %q, получено %q", test.name, test.expected, out)
+		}
+	}
+}
+
+func TestRuntimeErrors(t *testing.T) {
+	programs := []string{
+		`{ print $1 / 0 }`,
+		`{ print $1 % 0 }`,
+		`{ print $(-1) }`,
+		`{ print $1 ~ "(" }`,
+	}
+	for _, src := range programs {
+		if _, err := runProgram(t, src, "1\n", ""); err == nil {
+			t.Errorf("%q: ожидалась ошибка выполнения", src)
+		}
+	}
+}
+
+func TestMainArgs(t *testing.T) {
+	tests := []struct {
+		args     []string
+		stdin    string
+		expected string
+		code     int
+	}{
+		{[]string{"-F,", "$2 > limit { print $1 }", "-"}, "a,5\nb,50\n", "a\nb\n", cli.ExitOK},
+		{[]string{"-F", ",", "-v", "limit=10", "$2 > limit { print $1 }"}, "a,5\nb,50\n", "b\n", cli.ExitOK},
+		{[]string{"-F", `\t`, "{ print $2 }"}, "a\tb c\n", "b c\n", cli.ExitOK},
+		{[]string{"-v", "novalue", "{ print }"}, "", "", cli.ExitUsage},
+		{[]string{}, "", "", cli.ExitUsage},
+		{[]string{"{ print $1 / 0 }"}, "1\n", "", cli.ExitFailure},
+		{[]string{"{ print }", "does-not-exist.txt"}, "", "", cli.ExitFailure},
+	}
+
+	for _, test := range tests {
+		var out, errOut bytes.Buffer
+		stdio := cli.Stdio{In: strings.NewReader(test.stdin), Out: &out, Err: &errOut}
+		code := Main(test.args, stdio)
+		if code != test.code {
+			t.Errorf("%v: ожидался код %d, получен %d (stderr: %s)", test.args, test.code, code, errOut.String())
+		}
+		if out.String() != test.expected {
+			t.Errorf("%v: ожидалось %q, получено %q", test.args, test.expected, out.String())
+		}
+	}
+}
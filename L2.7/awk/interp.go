@@ -0,0 +1,484 @@
+package awk
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"L2/L2.7/cut"
+	"L2/internal/cli"
+)
+
+// arity — допустимое число аргументов встроенной функции.
+type arity struct{ min, max int }
+
+var builtinFuncs = map[string]arity{
+	"length":  {0, 1},
+	"substr":  {2, 3},
+	"index":   {2, 2},
+	"tolower": {1, 1},
+	"toupper": {1, 1},
+	"int":     {1, 1},
+}
+
+// errNext прерывает обработку текущей записи (оператор next).
+var errNext = errors.New("next")
+
+type runtimeError struct{ err error }
+
+// interpreter выполняет программу над потоком записей.
+// Поля записи разбиваются тем же Splitter, что и в утилите cut.
+type interpreter struct {
+	prog   *program
+	out    *bufio.Writer
+	vars   map[string]value
+	arrays map[string]map[string]value
+
+	record   string
+	fields   []string
+	nr       int
+	splitFS  string
+	splitter cut.Splitter
+	regexps  map[string]*regexp.Regexp
+}
+
+func newInterpreter(prog *program, out io.Writer) *interpreter {
+	return &interpreter{
+		prog: prog,
+		out:  bufio.NewWriter(out),
+		vars: map[string]value{
+			"FS":  strValue(" "),
+			"OFS": strValue(" "),
+			"ORS": strValue("\n"),
+		},
+		arrays:  make(map[string]map[string]value),
+		regexps: make(map[string]*regexp.Regexp),
+	}
+}
+
+// run выполняет BEGIN, правила для каждой строки из inputs и END.
+// Если в программе только BEGIN, входные данные не читаются.
+func (in *interpreter) run(inputs []io.Reader) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			re, ok := r.(runtimeError)
+			if !ok {
+				panic(r)
+			}
+			err = re.err
+		}
+		if flushErr := in.out.Flush(); err == nil {
+			err = flushErr
+		}
+	}()
+
+	for _, block := range in.prog.begin {
+		if err := in.execBlock(block); err != nil && !errors.Is(err, errNext) {
+			return err
+		}
+	}
+	if len(in.prog.rules) == 0 && len(in.prog.end) == 0 {
+		return nil
+	}
+
+	for _, input := range inputs {
+		lines := cli.NewLineReader(input)
+		for lines.Next() {
+			in.nr++
+			in.setRecord(lines.Text())
+			if err := in.processRecord(); err != nil {
+				return err
+			}
+		}
+		if err := lines.Err(); err != nil {
+			return err
+		}
+	}
+
+	for _, block := range in.prog.end {
+		if err := in.execBlock(block); err != nil && !errors.Is(err, errNext) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (in *interpreter) processRecord() error {
+	for _, r := range in.prog.rules {
+		if r.pattern != nil && !in.eval(r.pattern).Bool() {
+			continue
+		}
+		var err error
+		if r.action == nil {
+			err = in.print(nil)
+		} else {
+			err = in.execBlock(r.action)
+		}
+		if errors.Is(err, errNext) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (in *interpreter) execBlock(body []stmt) error {
+	for _, s := range body {
+		if err := in.exec(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (in *interpreter) exec(s stmt) error {
+	switch s := s.(type) {
+	case *printStmt:
+		return in.print(s.args)
+	case *exprStmt:
+		in.eval(s.e)
+	case *blockStmt:
+		return in.execBlock(s.body)
+	case *nextStmt:
+		return errNext
+	case *ifStmt:
+		if in.eval(s.cond).Bool() {
+			return in.execBlock(s.then)
+		}
+		return in.execBlock(s.els)
+	case *forInStmt:
+		array := in.arrays[s.array]
+		keys := make([]string, 0, len(array))
+		for key := range array {
+			keys = append(keys, key)
+		}
+		// Порядок обхода в awk не определён; сортируем ключи, чтобы вывод был воспроизводимым.
+		sort.Strings(keys)
+		for _, key := range keys {
+			if _, ok := array[key]; !ok {
+				continue
+			}
+			in.setVar(s.key, inputValue(key))
+			if err := in.execBlock(s.body); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (in *interpreter) print(args []expr) error {
+	var line string
+	if len(args) == 0 {
+		line = in.record
+	} else {
+		parts := make([]string, len(args))
+		for i, arg := range args {
+			parts[i] = in.eval(arg).String()
+		}
+		line = strings.Join(parts, in.getVar("OFS").String())
+	}
+	_, err := in.out.WriteString(line + in.getVar("ORS").String())
+	return err
+}
+
+func (in *interpreter) fail(format string, args ...any) {
+	panic(runtimeError{fmt.Errorf(format, args...)})
+}
+
+func (in *interpreter) eval(e expr) value {
+	switch e := e.(type) {
+	case *numberExpr:
+		return numValue(e.value)
+	case *stringExpr:
+		return strValue(e.value)
+	case *regexExpr:
+		return boolValue(e.re.MatchString(in.record))
+	case *fieldExpr:
+		return inputValue(in.getField(in.fieldIndex(e.index)))
+	case *varExpr:
+		return in.getVar(e.name)
+	case *indexExpr:
+		return in.array(e.array)[in.eval(e.key).String()]
+	case *assignExpr:
+		v := in.eval(e.value)
+		if e.op != tokAssign {
+			v = in.arith(assignArith[e.op], in.eval(e.target), v)
+		}
+		in.assign(e.target, v)
+		return v
+	case *incrExpr:
+		old := numValue(in.eval(e.target).Number())
+		delta := 1.0
+		if e.op == tokDecr {
+			delta = -1
+		}
+		updated := numValue(old.num + delta)
+		in.assign(e.target, updated)
+		if e.prefix {
+			return updated
+		}
+		return old
+	case *unaryExpr:
+		v := in.eval(e.operand)
+		switch e.op {
+		case tokNot:
+			return boolValue(!v.Bool())
+		case tokMinus:
+			return numValue(-v.Number())
+		}
+		return numValue(v.Number())
+	case *binaryExpr:
+		return in.evalBinary(e)
+	case *matchExpr:
+		matched := in.regexOf(e.re).MatchString(in.eval(e.left).String())
+		return boolValue(matched != e.negate)
+	case *concatExpr:
+		return strValue(in.eval(e.left).String() + in.eval(e.right).String())
+	case *inExpr:
+		_, ok := in.arrays[e.array][in.eval(e.key).String()]
+		return boolValue(ok)
+	case *callExpr:
+		return in.call(e)
+	}
+	in.fail("unsupported expression %T", e)
+	return value{}
+}
+
+var assignArith = map[tokenKind]tokenKind{
+	tokAddAssign: tokPlus,
+	tokSubAssign: tokMinus,
+	tokMulAssign: tokStar,
+	tokDivAssign: tokSlash,
+	tokModAssign: tokPercent,
+}
+
+func (in *interpreter) evalBinary(e *binaryExpr) value {
+	switch e.op {
+	case tokAnd:
+		return boolValue(in.eval(e.left).Bool() && in.eval(e.right).Bool())
+	case tokOr:
+		return boolValue(in.eval(e.left).Bool() || in.eval(e.right).Bool())
+	}
+
+	left, right := in.eval(e.left), in.eval(e.right)
+	switch e.op {
+	case tokEq:
+		return boolValue(compareValues(left, right) == 0)
+	case tokNe:
+		return boolValue(compareValues(left, right) != 0)
+	case tokLt:
+		return boolValue(compareValues(left, right) < 0)
+	case tokLe:
+		return boolValue(compareValues(left, right) <= 0)
+	case tokGt:
+		return boolValue(compareValues(left, right) > 0)
+	case tokGe:
+		return boolValue(compareValues(left, right) >= 0)
+	}
+	return in.arith(e.op, left, right)
+}
+
+func (in *interpreter) arith(op tokenKind, left, right value) value {
+	x, y := left.Number(), right.Number()
+	switch op {
+	case tokPlus:
+		return numValue(x + y)
+	case tokMinus:
+		return numValue(x - y)
+	case tokStar:
+		return numValue(x * y)
+	case tokSlash:
+		if y == 0 {
+			in.fail("division by zero")
+		}
+		return numValue(x / y)
+	case tokPercent:
+		if y == 0 {
+			in.fail("division by zero in %%")
+		}
+		return numValue(math.Mod(x, y))
+	}
+	in.fail("unsupported operator")
+	return value{}
+}
+
+// regexOf возвращает регулярное выражение для правой части ~: литерал /re/
+// или строку, которая компилируется при выполнении (с кешированием).
+func (in *interpreter) regexOf(e expr) *regexp.Regexp {
+	if lit, ok := e.(*regexExpr); ok {
+		return lit.re
+	}
+	pattern := in.eval(e).String()
+	if re, ok := in.regexps[pattern]; ok {
+		return re
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		in.fail("invalid regular expression %q: %v", pattern, err)
+	}
+	in.regexps[pattern] = re
+	return re
+}
+
+func (in *interpreter) call(e *callExpr) value {
+	args := make([]value, len(e.args))
+	for i, arg := range e.args {
+		args[i] = in.eval(arg)
+	}
+
+	switch e.name {
+	case "length":
+		if len(args) == 0 {
+			return numValue(float64(len([]rune(in.record))))
+		}
+		return numValue(float64(len([]rune(args[0].String()))))
+	case "substr":
+		runes := []rune(args[0].String())
+		// Позиции в awk считаются с 1; выход за границы обрезается.
+		start := int(math.Round(args[1].Number()))
+		end := len(runes) + 1
+		if len(args) == 3 {
+			end = start + int(math.Round(args[2].Number()))
+		}
+		start = max(start, 1)
+		end = min(end, len(runes)+1)
+		if start >= end {
+			return strValue("")
+		}
+		return strValue(string(runes[start-1 : end-1]))
+	case "index":
+		idx := strings.Index(args[0].String(), args[1].String())
+		if idx < 0 {
+			return numValue(0)
+		}
+		return numValue(float64(len([]rune(args[0].String()[:idx])) + 1))
+	case "tolower":
+		return strValue(strings.ToLower(args[0].String()))
+	case "toupper":
+		return strValue(strings.ToUpper(args[0].String()))
+	case "int":
+		return numValue(math.Trunc(args[0].Number()))
+	}
+	in.fail("unknown function %s", e.name)
+	return value{}
+}
+
+func (in *interpreter) array(name string) map[string]value {
+	array, ok := in.arrays[name]
+	if !ok {
+		array = make(map[string]value)
+		in.arrays[name] = array
+	}
+	return array
+}
+
+func (in *interpreter) assign(target expr, v value) {
+	switch t := target.(type) {
+	case *varExpr:
+		in.setVar(t.name, v)
+	case *fieldExpr:
+		in.setField(in.fieldIndex(t.index), v.String())
+	case *indexExpr:
+		in.array(t.array)[in.eval(t.key).String()] = v
+	}
+}
+
+func (in *interpreter) getVar(name string) value {
+	switch name {
+	case "NR":
+		return numValue(float64(in.nr))
+	case "NF":
+		return numValue(float64(len(in.fields)))
+	}
+	return in.vars[name]
+}
+
+func (in *interpreter) setVar(name string, v value) {
+	switch name {
+	case "NR":
+		in.nr = int(v.Number())
+	case "NF":
+		n := int(v.Number())
+		if n < 0 {
+			in.fail("NF cannot be negative")
+		}
+		for len(in.fields) < n {
+			in.fields = append(in.fields, "")
+		}
+		in.fields = in.fields[:n]
+		in.rebuildRecord()
+	default:
+		in.vars[name] = v
+	}
+}
+
+func (in *interpreter) fieldIndex(e expr) int {
+	n := in.eval(e).Number()
+	if n < 0 {
+		in.fail("negative field index $%s", formatNumber(n))
+	}
+	return int(n)
+}
+
+func (in *interpreter) getField(i int) string {
+	if i == 0 {
+		return in.record
+	}
+	if i <= len(in.fields) {
+		return in.fields[i-1]
+	}
+	return ""
+}
+
+func (in *interpreter) setField(i int, s string) {
+	if i == 0 {
+		in.setRecord(s)
+		return
+	}
+	for len(in.fields) < i {
+		in.fields = append(in.fields, "")
+	}
+	in.fields[i-1] = s
+	in.rebuildRecord()
+}
+
+func (in *interpreter) rebuildRecord() {
+	in.record = strings.Join(in.fields, in.getVar("OFS").String())
+}
+
+// setRecord сохраняет запись и разбивает её на поля согласно текущему FS:
+// " " — по пробельным символам с отбрасыванием крайних, один символ — по нему буквально,
+// более длинное значение — как регулярное выражение.
+func (in *interpreter) setRecord(s string) {
+	in.record = s
+	fs := in.getVar("FS").String()
+	if fs != in.splitFS || in.splitFS == "" {
+		splitter, err := splitterFor(fs)
+		if err != nil {
+			in.fail("%v", err)
+		}
+		in.splitFS, in.splitter = fs, splitter
+	}
+	in.fields = in.splitter.Split(s)
+	if len(in.fields) == 1 && in.fields[0] == "" {
+		in.fields = nil
+	}
+}
+
+func splitterFor(fs string) (cut.Splitter, error) {
+	switch {
+	case fs == " ":
+		return cut.NewSplitter("", `[ \t\n]+`)
+	case len([]rune(fs)) == 1:
+		return cut.NewSplitter(fs, "")
+	}
+	return cut.NewSplitter("", fs)
+}
@@ -0,0 +1,307 @@
+package awk
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNewline
+	tokNumber
+	tokString
+	tokRegex
+	tokName
+	tokFunc // имя встроенной функции
+	tokBegin
+	tokEnd
+	tokPrint
+	tokIf
+	tokElse
+	tokFor
+	tokIn
+	tokNext
+	tokDollar
+	tokLBrace
+	tokRBrace
+	tokLParen
+	tokRParen
+	tokLBracket
+	tokRBracket
+	tokSemicolon
+	tokComma
+	tokAssign    // =
+	tokAddAssign // +=
+	tokSubAssign // -=
+	tokMulAssign // *=
+	tokDivAssign // /=
+	tokModAssign // %=
+	tokIncr      // ++
+	tokDecr      // --
+	tokPlus
+	tokMinus
+	tokStar
+	tokSlash
+	tokPercent
+	tokEq
+	tokNe
+	tokLt
+	tokLe
+	tokGt
+	tokGe
+	tokMatch    // ~
+	tokNotMatch // !~
+	tokAnd
+	tokOr
+	tokNot
+)
+
+var keywords = map[string]tokenKind{
+	"BEGIN": tokBegin,
+	"END":   tokEnd,
+	"print": tokPrint,
+	"if":    tokIf,
+	"else":  tokElse,
+	"for":   tokFor,
+	"in":    tokIn,
+	"next":  tokNext,
+}
+
+type token struct {
+	kind tokenKind
+	text string
+	line int
+	col  int
+}
+
+// lexer разбивает текст программы на токены. Символ '/' считается началом
+// регулярного выражения, если предыдущий токен не может завершать операнд
+// (так же решает неоднозначность и настоящий awk).
+type lexer struct {
+	src    []rune
+	pos    int
+	line   int
+	col    int
+	last   tokenKind
+	tokens []token
+}
+
+func tokenize(src string) ([]token, error) {
+	lx := &lexer{src: []rune(src), line: 1, col: 1, last: tokNewline}
+	for {
+		tok, err := lx.next()
+		if err != nil {
+			return nil, err
+		}
+		lx.tokens = append(lx.tokens, tok)
+		lx.last = tok.kind
+		if tok.kind == tokEOF {
+			return lx.tokens, nil
+		}
+	}
+}
+
+func (lx *lexer) peek(offset int) rune {
+	if lx.pos+offset < len(lx.src) {
+		return lx.src[lx.pos+offset]
+	}
+	return 0
+}
+
+func (lx *lexer) advance() rune {
+	r := lx.src[lx.pos]
+	lx.pos++
+	if r == '\n' {
+		lx.line++
+		lx.col = 1
+	} else {
+		lx.col++
+	}
+	return r
+}
+
+func (lx *lexer) errorf(format string, args ...any) error {
+	return fmt.Errorf("syntax error at %d:%d: %s", lx.line, lx.col, fmt.Sprintf(format, args...))
+}
+
+func (lx *lexer) next() (token, error) {
+	for lx.pos < len(lx.src) {
+		r := lx.peek(0)
+		switch {
+		case r == ' ' || r == '\t' || r == '\r':
+			lx.advance()
+		case r == '\\' && lx.peek(1) == '\n':
+			lx.advance()
+			lx.advance()
+		case r == '#':
+			for lx.pos < len(lx.src) && lx.peek(0) != '\n' {
+				lx.advance()
+			}
+		default:
+			return lx.scan()
+		}
+	}
+	return token{kind: tokEOF, line: lx.line, col: lx.col}, nil
+}
+
+func (lx *lexer) scan() (token, error) {
+	line, col := lx.line, lx.col
+	tok := func(kind tokenKind, text string) (token, error) {
+		return token{kind: kind, text: text, line: line, col: col}, nil
+	}
+
+	r := lx.peek(0)
+	switch {
+	case r == '\n':
+		lx.advance()
+		return tok(tokNewline, "\n")
+	case isDigit(r) || (r == '.' && isDigit(lx.peek(1))):
+		return tok(tokNumber, lx.scanNumber())
+	case isLetter(r):
+		start := lx.pos
+		for lx.pos < len(lx.src) && (isLetter(lx.peek(0)) || isDigit(lx.peek(0))) {
+			lx.advance()
+		}
+		word := string(lx.src[start:lx.pos])
+		if kind, ok := keywords[word]; ok {
+			return tok(kind, word)
+		}
+		if _, ok := builtinFuncs[word]; ok {
+			return tok(tokFunc, word)
+		}
+		return tok(tokName, word)
+	case r == '"':
+		s, err := lx.scanString()
+		if err != nil {
+			return token{}, err
+		}
+		return tok(tokString, s)
+	case r == '/' && !endsOperand(lx.last):
+		re, err := lx.scanRegex()
+		if err != nil {
+			return token{}, err
+		}
+		return tok(tokRegex, re)
+	}
+
+	lx.advance()
+	two := string([]rune{r, lx.peek(0)})
+	if kind, ok := twoCharOps[two]; ok {
+		lx.advance()
+		return tok(kind, two)
+	}
+	if kind, ok := oneCharOps[r]; ok {
+		return tok(kind, string(r))
+	}
+	return token{}, fmt.Errorf("syntax error at %d:%d: unexpected character %q", line, col, r)
+}
+
+var twoCharOps = map[string]tokenKind{
+	"+=": tokAddAssign, "-=": tokSubAssign, "*=": tokMulAssign, "/=": tokDivAssign, "%=": tokModAssign,
+	"++": tokIncr, "--": tokDecr, "==": tokEq, "!=": tokNe, "<=": tokLe, ">=": tokGe,
+	"!~": tokNotMatch, "&&": tokAnd, "||": tokOr,
+}
+
+var oneCharOps = map[rune]tokenKind{
+	'{': tokLBrace, '}': tokRBrace, '(': tokLParen, ')': tokRParen, '[': tokLBracket, ']': tokRBracket,
+	';': tokSemicolon, ',': tokComma, '=': tokAssign, '+': tokPlus, '-': tokMinus, '*': tokStar,
+	'/': tokSlash, '%': tokPercent, '<': tokLt, '>': tokGt, '~': tokMatch, '!': tokNot, '$': tokDollar,
+}
+
+// endsOperand сообщает, может ли токен завершать операнд выражения:
+// после него '/' — деление, иначе — начало регулярного выражения.
+func endsOperand(kind tokenKind) bool {
+	switch kind {
+	case tokNumber, tokString, tokRegex, tokName, tokRParen, tokRBracket, tokIncr, tokDecr:
+		return true
+	}
+	return false
+}
+
+func (lx *lexer) scanNumber() string {
+	start := lx.pos
+	for isDigit(lx.peek(0)) {
+		lx.advance()
+	}
+	if lx.peek(0) == '.' {
+		lx.advance()
+		for isDigit(lx.peek(0)) {
+			lx.advance()
+		}
+	}
+	if (lx.peek(0) == 'e' || lx.peek(0) == 'E') &&
+		(isDigit(lx.peek(1)) || ((lx.peek(1) == '+' || lx.peek(1) == '-') && isDigit(lx.peek(2)))) {
+		lx.advance()
+		lx.advance()
+		for isDigit(lx.peek(0)) {
+			lx.advance()
+		}
+	}
+	return string(lx.src[start:lx.pos])
+}
+
+func (lx *lexer) scanString() (string, error) {
+	lx.advance() // открывающая кавычка
+	var sb strings.Builder
+	for {
+		if lx.pos >= len(lx.src) || lx.peek(0) == '\n' {
+			return "", lx.errorf("unterminated string")
+		}
+		r := lx.advance()
+		switch r {
+		case '"':
+			return sb.String(), nil
+		case '\\':
+			if lx.pos >= len(lx.src) {
+				return "", lx.errorf("unterminated string")
+			}
+			switch e := lx.advance(); e {
+			case 'n':
+				sb.WriteRune('\n')
+			case 't':
+				sb.WriteRune('\t')
+			case 'r':
+				sb.WriteRune('\r')
+			case '\\', '"', '/':
+				sb.WriteRune(e)
+			default:
+				sb.WriteRune('\\')
+				sb.WriteRune(e)
+			}
+		default:
+			sb.WriteRune(r)
+		}
+	}
+}
+
+func (lx *lexer) scanRegex() (string, error) {
+	lx.advance() // открывающий '/'
+	var sb strings.Builder
+	for {
+		if lx.pos >= len(lx.src) || lx.peek(0) == '\n' {
+			return "", lx.errorf("unterminated regular expression")
+		}
+		r := lx.advance()
+		switch {
+		case r == '/':
+			return sb.String(), nil
+		case r == '\\' && lx.peek(0) == '/':
+			sb.WriteRune(lx.advance())
+		case r == '\\' && lx.pos < len(lx.src):
+			sb.WriteRune(r)
+			sb.WriteRune(lx.advance())
+		default:
+			sb.WriteRune(r)
+		}
+	}
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isLetter(r rune) bool {
+	return r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
@@ -0,0 +1,462 @@
+package awk
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// parser — рекурсивный спуск по токенам. Приоритеты операторов (от низшего к высшему):
+// присваивание, ||, &&, in, ~ !~, сравнения, конкатенация, + -, * / %, унарные ! -, ++ --, $.
+type parser struct {
+	tokens []token
+	pos    int
+	// inPrint запрещает '>' на верхнем уровне аргументов print:
+	// в awk это перенаправление вывода, которое не поддерживается.
+	inPrint bool
+}
+
+type parseError struct{ err error }
+
+func parse(src string) (prog *program, err error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	defer func() {
+		if r := recover(); r != nil {
+			pe, ok := r.(parseError)
+			if !ok {
+				panic(r)
+			}
+			prog, err = nil, pe.err
+		}
+	}()
+	return p.parseProgram(), nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) advance() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) errorf(tok token, format string, args ...any) {
+	panic(parseError{fmt.Errorf("syntax error at %d:%d: %s", tok.line, tok.col, fmt.Sprintf(format, args...))})
+}
+
+func (p *parser) expect(kind tokenKind, what string) token {
+	tok := p.peek()
+	if tok.kind != kind {
+		p.errorf(tok, "expected %s, got %s", what, describe(tok))
+	}
+	return p.advance()
+}
+
+func describe(tok token) string {
+	switch tok.kind {
+	case tokEOF:
+		return "end of program"
+	case tokNewline:
+		return "newline"
+	}
+	return strconv.Quote(tok.text)
+}
+
+func (p *parser) skipNewlines() {
+	for p.peek().kind == tokNewline {
+		p.advance()
+	}
+}
+
+func (p *parser) skipTerminators() {
+	for p.peek().kind == tokNewline || p.peek().kind == tokSemicolon {
+		p.advance()
+	}
+}
+
+func (p *parser) parseProgram() *program {
+	prog := &program{}
+	for {
+		p.skipTerminators()
+		tok := p.peek()
+		switch tok.kind {
+		case tokEOF:
+			return prog
+		case tokBegin:
+			p.advance()
+			p.skipNewlines()
+			prog.begin = append(prog.begin, p.parseBlock())
+		case tokEnd:
+			p.advance()
+			p.skipNewlines()
+			prog.end = append(prog.end, p.parseBlock())
+		case tokLBrace:
+			prog.rules = append(prog.rules, rule{action: p.parseBlock()})
+		default:
+			r := rule{pattern: p.parseExpr()}
+			if p.peek().kind == tokLBrace {
+				r.action = p.parseBlock()
+			} else {
+				p.expectTerminator()
+			}
+			prog.rules = append(prog.rules, r)
+		}
+	}
+}
+
+func (p *parser) expectTerminator() {
+	switch p.peek().kind {
+	case tokNewline, tokSemicolon:
+		p.advance()
+	case tokEOF, tokRBrace:
+	default:
+		p.errorf(p.peek(), "unexpected %s", describe(p.peek()))
+	}
+}
+
+func (p *parser) parseBlock() []stmt {
+	p.expect(tokLBrace, "'{'")
+	body := []stmt{}
+	for {
+		p.skipTerminators()
+		if p.peek().kind == tokRBrace {
+			p.advance()
+			return body
+		}
+		if p.peek().kind == tokEOF {
+			p.errorf(p.peek(), "missing '}'")
+		}
+		body = append(body, p.parseStmt())
+	}
+}
+
+func (p *parser) parseStmt() stmt {
+	switch p.peek().kind {
+	case tokLBrace:
+		return &blockStmt{body: p.parseBlock()}
+	case tokIf:
+		return p.parseIf()
+	case tokFor:
+		return p.parseForIn()
+	case tokNext:
+		p.advance()
+		p.expectTerminator()
+		return &nextStmt{}
+	case tokPrint:
+		p.advance()
+		s := &printStmt{args: p.parsePrintArgs()}
+		if p.peek().kind == tokGt {
+			p.errorf(p.peek(), "output redirection is not supported")
+		}
+		p.expectTerminator()
+		return s
+	}
+	s := &exprStmt{e: p.parseExpr()}
+	p.expectTerminator()
+	return s
+}
+
+// parseBody разбирает тело if/for: отдельный оператор или блок, возможно с новой строки.
+func (p *parser) parseBody() []stmt {
+	p.skipNewlines()
+	if p.peek().kind == tokLBrace {
+		return p.parseBlock()
+	}
+	return []stmt{p.parseStmt()}
+}
+
+func (p *parser) parseIf() stmt {
+	p.advance()
+	p.expect(tokLParen, "'(' after if")
+	s := &ifStmt{cond: p.parseExpr()}
+	p.expect(tokRParen, "')'")
+	s.then = p.parseBody()
+
+	saved := p.pos
+	p.skipTerminators()
+	if p.peek().kind == tokElse {
+		p.advance()
+		s.els = p.parseBody()
+	} else {
+		p.pos = saved
+	}
+	return s
+}
+
+func (p *parser) parseForIn() stmt {
+	p.advance()
+	p.expect(tokLParen, "'(' after for")
+	key := p.expect(tokName, "loop variable").text
+	p.expect(tokIn, "'in'")
+	array := p.expect(tokName, "array name").text
+	p.expect(tokRParen, "')'")
+	return &forInStmt{key: key, array: array, body: p.parseBody()}
+}
+
+func (p *parser) parsePrintArgs() []expr {
+	switch p.peek().kind {
+	case tokNewline, tokSemicolon, tokRBrace, tokEOF:
+		return nil
+	}
+
+	// print (a, b) — список аргументов в скобках.
+	if p.peek().kind == tokLParen {
+		saved := p.pos
+		if args, ok := p.tryParenList(); ok {
+			return args
+		}
+		p.pos = saved
+	}
+
+	p.inPrint = true
+	defer func() { p.inPrint = false }()
+	args := []expr{p.parseExpr()}
+	for p.peek().kind == tokComma {
+		p.advance()
+		p.skipNewlines()
+		args = append(args, p.parseExpr())
+	}
+	return args
+}
+
+func (p *parser) tryParenList() (args []expr, ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, isParseErr := r.(parseError); !isParseErr {
+				panic(r)
+			}
+			args, ok = nil, false
+		}
+	}()
+	p.advance()
+	args = []expr{p.parseExpr()}
+	for p.peek().kind == tokComma {
+		p.advance()
+		p.skipNewlines()
+		args = append(args, p.parseExpr())
+	}
+	p.expect(tokRParen, "')'")
+	switch p.peek().kind {
+	case tokNewline, tokSemicolon, tokRBrace, tokEOF:
+		return args, true
+	}
+	return nil, false
+}
+
+func (p *parser) parseExpr() expr {
+	return p.parseAssign()
+}
+
+var assignOps = map[tokenKind]bool{
+	tokAssign: true, tokAddAssign: true, tokSubAssign: true,
+	tokMulAssign: true, tokDivAssign: true, tokModAssign: true,
+}
+
+func (p *parser) parseAssign() expr {
+	left := p.parseOr()
+	if tok := p.peek(); assignOps[tok.kind] {
+		if !isLValue(left) {
+			p.errorf(tok, "cannot assign to this expression")
+		}
+		p.advance()
+		p.skipNewlines()
+		return &assignExpr{target: left, op: tok.kind, value: p.parseAssign()}
+	}
+	return left
+}
+
+func (p *parser) parseOr() expr {
+	left := p.parseAnd()
+	for p.peek().kind == tokOr {
+		p.advance()
+		p.skipNewlines()
+		left = &binaryExpr{op: tokOr, left: left, right: p.parseAnd()}
+	}
+	return left
+}
+
+func (p *parser) parseAnd() expr {
+	left := p.parseIn()
+	for p.peek().kind == tokAnd {
+		p.advance()
+		p.skipNewlines()
+		left = &binaryExpr{op: tokAnd, left: left, right: p.parseIn()}
+	}
+	return left
+}
+
+func (p *parser) parseIn() expr {
+	left := p.parseMatch()
+	for p.peek().kind == tokIn {
+		p.advance()
+		left = &inExpr{key: left, array: p.expect(tokName, "array name").text}
+	}
+	return left
+}
+
+func (p *parser) parseMatch() expr {
+	left := p.parseComparison()
+	for p.peek().kind == tokMatch || p.peek().kind == tokNotMatch {
+		negate := p.advance().kind == tokNotMatch
+		left = &matchExpr{left: left, re: p.parseComparison(), negate: negate}
+	}
+	return left
+}
+
+func (p *parser) parseComparison() expr {
+	left := p.parseConcat()
+	switch kind := p.peek().kind; kind {
+	case tokGt:
+		if p.inPrint {
+			return left
+		}
+		fallthrough
+	case tokEq, tokNe, tokLt, tokLe, tokGe:
+		p.advance()
+		return &binaryExpr{op: kind, left: left, right: p.parseConcat()}
+	}
+	return left
+}
+
+// startsConcatOperand сообщает, может ли токен начинать следующий операнд конкатенации.
+func startsConcatOperand(kind tokenKind) bool {
+	switch kind {
+	case tokNumber, tokString, tokName, tokFunc, tokDollar, tokLParen:
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseConcat() expr {
+	left := p.parseAdditive()
+	for startsConcatOperand(p.peek().kind) {
+		left = &concatExpr{left: left, right: p.parseAdditive()}
+	}
+	return left
+}
+
+func (p *parser) parseAdditive() expr {
+	left := p.parseMultiplicative()
+	for p.peek().kind == tokPlus || p.peek().kind == tokMinus {
+		op := p.advance().kind
+		left = &binaryExpr{op: op, left: left, right: p.parseMultiplicative()}
+	}
+	return left
+}
+
+func (p *parser) parseMultiplicative() expr {
+	left := p.parseUnary()
+	for p.peek().kind == tokStar || p.peek().kind == tokSlash || p.peek().kind == tokPercent {
+		op := p.advance().kind
+		left = &binaryExpr{op: op, left: left, right: p.parseUnary()}
+	}
+	return left
+}
+
+func (p *parser) parseUnary() expr {
+	switch p.peek().kind {
+	case tokNot, tokMinus, tokPlus:
+		op := p.advance().kind
+		return &unaryExpr{op: op, operand: p.parseUnary()}
+	}
+	return p.parseIncr()
+}
+
+func (p *parser) parseIncr() expr {
+	if tok := p.peek(); tok.kind == tokIncr || tok.kind == tokDecr {
+		p.advance()
+		target := p.parsePrimary()
+		if !isLValue(target) {
+			p.errorf(tok, "%s requires a variable or field", tok.text)
+		}
+		return &incrExpr{target: target, op: tok.kind, prefix: true}
+	}
+	e := p.parsePrimary()
+	if tok := p.peek(); isLValue(e) && (tok.kind == tokIncr || tok.kind == tokDecr) {
+		p.advance()
+		return &incrExpr{target: e, op: tok.kind}
+	}
+	return e
+}
+
+func (p *parser) parsePrimary() expr {
+	tok := p.advance()
+	switch tok.kind {
+	case tokNumber:
+		value, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			p.errorf(tok, "invalid number %s", tok.text)
+		}
+		return &numberExpr{value: value}
+	case tokString:
+		return &stringExpr{value: tok.text}
+	case tokRegex:
+		re, err := regexp.Compile(tok.text)
+		if err != nil {
+			p.errorf(tok, "invalid regular expression: %v", err)
+		}
+		return &regexExpr{re: re}
+	case tokDollar:
+		if next := p.peek(); next.kind == tokIncr || next.kind == tokDecr || next.kind == tokMinus {
+			return &fieldExpr{index: p.parseUnary()}
+		}
+		return &fieldExpr{index: p.parsePrimary()}
+	case tokName:
+		if p.peek().kind == tokLBracket {
+			p.advance()
+			key := p.parseExpr()
+			p.expect(tokRBracket, "']'")
+			return &indexExpr{array: tok.text, key: key}
+		}
+		return &varExpr{name: tok.text}
+	case tokFunc:
+		return p.parseCall(tok)
+	case tokLParen:
+		inPrint := p.inPrint
+		p.inPrint = false
+		e := p.parseExpr()
+		p.inPrint = inPrint
+		p.expect(tokRParen, "')'")
+		return e
+	}
+	p.errorf(tok, "unexpected %s", describe(tok))
+	return nil
+}
+
+func (p *parser) parseCall(name token) expr {
+	call := &callExpr{name: name.text}
+	if p.peek().kind != tokLParen {
+		// length без скобок — длина $0.
+		if name.text == "length" {
+			return call
+		}
+		p.errorf(p.peek(), "expected '(' after %s", name.text)
+	}
+	p.advance()
+	inPrint := p.inPrint
+	p.inPrint = false
+	if p.peek().kind != tokRParen {
+		call.args = append(call.args, p.parseExpr())
+		for p.peek().kind == tokComma {
+			p.advance()
+			p.skipNewlines()
+			call.args = append(call.args, p.parseExpr())
+		}
+	}
+	p.inPrint = inPrint
+	p.expect(tokRParen, "')'")
+
+	arity := builtinFuncs[name.text]
+	if len(call.args) < arity.min || len(call.args) > arity.max {
+		p.errorf(name, "wrong number of arguments to %s", name.text)
+	}
+	return call
+}
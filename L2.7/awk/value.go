@@ -0,0 +1,151 @@
+package awk
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+type valueKind int
+
+const (
+	kindUninit valueKind = iota // неинициализированная переменная: "" и 0 одновременно
+	kindNum                     // результат арифметики или числовая константа
+	kindStr                     // строковая константа или результат конкатенации
+	kindStrNum                  // поле или -v переменная, похожая на число
+)
+
+// value — значение awk. Как и в awk, одно значение может вести себя и как строка,
+// и как число; kind определяет, сравнивается ли оно как число или как строка.
+type value struct {
+	kind valueKind
+	str  string
+	num  float64
+}
+
+func numValue(n float64) value {
+	return value{kind: kindNum, num: n}
+}
+
+func strValue(s string) value {
+	return value{kind: kindStr, str: s}
+}
+
+func boolValue(b bool) value {
+	if b {
+		return numValue(1)
+	}
+	return numValue(0)
+}
+
+// inputValue создаёт значение из входных данных: если строка выглядит как число,
+// сравнения с ним будут числовыми.
+func inputValue(s string) value {
+	if n, ok := looksNumeric(s); ok {
+		return value{kind: kindStrNum, str: s, num: n}
+	}
+	return strValue(s)
+}
+
+func (v value) String() string {
+	if v.kind == kindNum {
+		return formatNumber(v.num)
+	}
+	return v.str
+}
+
+func (v value) Number() float64 {
+	switch v.kind {
+	case kindNum, kindStrNum:
+		return v.num
+	case kindStr:
+		return numberPrefix(v.str)
+	}
+	return 0
+}
+
+func (v value) Bool() bool {
+	switch v.kind {
+	case kindNum, kindStrNum:
+		return v.num != 0
+	case kindStr:
+		return v.str != ""
+	}
+	return false
+}
+
+func (v value) isNumeric() bool {
+	return v.kind != kindStr
+}
+
+// compareValues сравнивает числа численно, а всё остальное — как строки.
+func compareValues(a, b value) int {
+	if a.isNumeric() && b.isNumeric() {
+		switch x, y := a.Number(), b.Number(); {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a.String(), b.String())
+}
+
+func formatNumber(n float64) string {
+	if n == math.Trunc(n) && math.Abs(n) < 1e16 {
+		return strconv.FormatInt(int64(n), 10)
+	}
+	return strconv.FormatFloat(n, 'g', 6, 64)
+}
+
+// numberPrefix возвращает число в начале строки, как awk при преобразовании "12abc" в 12.
+func numberPrefix(s string) float64 {
+	s = strings.TrimLeft(s, " \t\n")
+	n, _ := strconv.ParseFloat(s[:numericPrefixLen(s)], 64)
+	return n
+}
+
+func looksNumeric(s string) (float64, bool) {
+	s = strings.Trim(s, " \t\n")
+	if s == "" || numericPrefixLen(s) != len(s) {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	return n, err == nil
+}
+
+func numericPrefixLen(s string) int {
+	i := 0
+	if i < len(s) && (s[i] == '+' || s[i] == '-') {
+		i++
+	}
+	digits := 0
+	for i < len(s) && isDigit(rune(s[i])) {
+		i++
+		digits++
+	}
+	if i < len(s) && s[i] == '.' {
+		i++
+		for i < len(s) && isDigit(rune(s[i])) {
+			i++
+			digits++
+		}
+	}
+	if digits == 0 {
+		return 0
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		j := i + 1
+		if j < len(s) && (s[j] == '+' || s[j] == '-') {
+			j++
+		}
+		if j < len(s) && isDigit(rune(s[j])) {
+			for j < len(s) && isDigit(rune(s[j])) {
+				j++
+			}
+			i = j
+		}
+	}
+	return i
+}
@@ -0,0 +1,298 @@
+// Package cut реализует утилиту cut из задания L2.7.
+package cut
+
+import (
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"L2/internal/cli"
+)
+
+// CutOptions хранит значения ключей командной строки.
+// В режиме csv разделитель по умолчанию — запятая, а -f может содержать имена колонок из заголовка.
+// Без reorder поля выводятся как в POSIX cut: в порядке следования во входной строке и без повторов.
+type CutOptions struct {
+	filePath       string
+	fields         string
+	delimiter      string
+	delimiterRegex string
+	onlySeparated  bool
+	csvMode        bool
+	header         bool
+	reorder        bool
+}
+
+func parseCutOptions(args []string, stdio cli.Stdio) (CutOptions, int, bool) {
+	var opts CutOptions
+	var tsvMode bool
+	fs := cli.NewFlagSet("cut", stdio)
+	fs.StringVar(&opts.fields, "f", "", "Specify fields (columns) to cut, e.g. '1,3' or 'user_id,date' with --header")
+	fs.StringVar(&opts.delimiter, "d", "\t", "Specify a custom delimiter (default: TAB, or ',' with --csv)")
+	fs.StringVar(&opts.delimiterRegex, "D", "", "Split on a regular expression instead of -d, e.g. '\\s+' (awk-style)")
+	fs.BoolVar(&opts.onlySeparated, "s", false, "Only print lines with the delimiter")
+	fs.StringVar(&opts.filePath, "file", "", "Path to input file (default: the first argument or STDIN)")
+	fs.BoolVar(&opts.csvMode, "csv", false, "Parse input as CSV: respect quoted fields and multi-line records")
+	fs.BoolVar(&tsvMode, "tsv", false, "Same as --csv with TAB as the delimiter")
+	fs.BoolVar(&opts.header, "header", false, "Treat the first line as a header and allow selecting fields by name")
+	fs.BoolVar(&opts.reorder, "reorder", false, "Output fields in the order given by -f, duplicates allowed")
+	if code, ok := cli.ParseFlags(fs, args); !ok {
+		return opts, code, false
+	}
+	if opts.filePath == "" {
+		opts.filePath = fs.Arg(0)
+	}
+
+	delimiterSet := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "d" {
+			delimiterSet = true
+		}
+	})
+	switch {
+	case tsvMode:
+		opts.csvMode = true
+		if !delimiterSet {
+			opts.delimiter = "\t"
+		}
+	case opts.csvMode && !delimiterSet:
+		opts.delimiter = ","
+	}
+	return opts, cli.ExitOK, true
+}
+
+// Main запускает cut с аргументами args.
+func Main(args []string, stdio cli.Stdio) int {
+	opts, code, ok := parseCutOptions(args, stdio)
+	if !ok {
+		return code
+	}
+	if err := customCut(opts, stdio); err != nil {
+		return cli.Errorf(stdio, "cut", cli.ExitFailure, "%v", err)
+	}
+	return cli.ExitOK
+}
+
+func customCut(opts CutOptions, stdio cli.Stdio) error {
+	file, err := cli.OpenInput(opts.filePath, stdio.In)
+	if err != nil {
+		return fmt.Errorf("cannot open file: %v", err)
+	}
+	defer file.Close()
+
+	if opts.csvMode {
+		if opts.delimiterRegex != "" {
+			return errors.New("-D cannot be used together with --csv")
+		}
+		return cutCSV(file, stdio.Out, opts)
+	}
+	return cutLines(file, stdio.Out, opts)
+}
+
+// Splitter разбивает строку на колонки по строковому разделителю
+// или, если задан pattern, по регулярному выражению. Используется также утилитой awk.
+type Splitter struct {
+	delimiter string
+	pattern   *regexp.Regexp
+}
+
+// NewSplitter создаёт Splitter. Пустой pattern означает разбиение по delimiter.
+func NewSplitter(delimiter, pattern string) (Splitter, error) {
+	if pattern == "" {
+		return Splitter{delimiter: delimiter}, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return Splitter{}, fmt.Errorf("invalid delimiter pattern: %v", err)
+	}
+	return Splitter{pattern: re}, nil
+}
+
+// Split по регулярному выражению отбрасывает пустые поля от совпадений в начале и конце строки,
+// как awk делает это для пробельного разделителя: "  a  b " с '\s+' даёт [a b].
+func (s Splitter) Split(line string) []string {
+	if s.pattern == nil {
+		return strings.Split(line, s.delimiter)
+	}
+	columns := s.pattern.Split(line, -1)
+	if len(columns) > 1 && columns[0] == "" {
+		columns = columns[1:]
+	}
+	if len(columns) > 1 && columns[len(columns)-1] == "" {
+		columns = columns[:len(columns)-1]
+	}
+	return columns
+}
+
+// Separated сообщает, встречается ли разделитель в строке.
+func (s Splitter) Separated(line string) bool {
+	if s.pattern == nil {
+		return strings.Contains(line, s.delimiter)
+	}
+	return s.pattern.MatchString(line)
+}
+
+func cutLines(r io.Reader, w io.Writer, opts CutOptions) error {
+	splitter, err := NewSplitter(opts.delimiter, opts.delimiterRegex)
+	if err != nil {
+		return err
+	}
+
+	var fieldIndices []int
+	if !opts.header {
+		indices, err := opts.fieldIndices(nil)
+		if err != nil {
+			return err
+		}
+		fieldIndices = indices
+	}
+
+	lines := cli.NewLineReader(r)
+	headerPending := opts.header
+	for lines.Next() {
+		line := lines.Text()
+		columns := splitter.Split(line)
+		if headerPending {
+			headerPending = false
+			indices, err := opts.fieldIndices(columns)
+			if err != nil {
+				return err
+			}
+			fieldIndices = indices
+		}
+		if opts.onlySeparated && !splitter.Separated(line) {
+			continue
+		}
+		printSelectedFields(w, columns, fieldIndices)
+	}
+	return lines.Err()
+}
+
+// cutCSV читает записи через encoding/csv, поэтому разделитель внутри кавычек
+// и переводы строк внутри полей не разбивают запись. Вывод также идёт в формате csv.
+func cutCSV(r io.Reader, w io.Writer, opts CutOptions) error {
+	comma, size := utf8.DecodeRuneInString(opts.delimiter)
+	if size == 0 || size != len(opts.delimiter) {
+		return fmt.Errorf("csv delimiter must be a single character, got %q", opts.delimiter)
+	}
+
+	reader := csv.NewReader(r)
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	writer := csv.NewWriter(w)
+	writer.Comma = comma
+
+	var fieldIndices []int
+	if !opts.header {
+		indices, err := opts.fieldIndices(nil)
+		if err != nil {
+			return err
+		}
+		fieldIndices = indices
+	}
+
+	headerPending := opts.header
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("cannot parse csv: %v", err)
+		}
+		if headerPending {
+			headerPending = false
+			indices, err := opts.fieldIndices(record)
+			if err != nil {
+				return err
+			}
+			fieldIndices = indices
+		}
+		if opts.onlySeparated && len(record) < 2 {
+			continue
+		}
+		if selected := selectFields(record, fieldIndices); len(selected) > 0 {
+			if err := writer.Write(selected); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// fieldIndices возвращает индексы выбранных колонок в порядке вывода.
+func (opts CutOptions) fieldIndices(header []string) ([]int, error) {
+	indices, err := resolveFields(opts.fields, header)
+	if err != nil || opts.reorder {
+		return indices, err
+	}
+	sort.Ints(indices)
+	unique := indices[:0]
+	for _, idx := range indices {
+		if len(unique) == 0 || idx != unique[len(unique)-1] {
+			unique = append(unique, idx)
+		}
+	}
+	return unique, nil
+}
+
+// resolveFields переводит список полей из -f в индексы колонок (с нуля).
+// Поле задаётся номером с единицы либо, если передан заголовок, именем колонки.
+func resolveFields(fields string, header []string) ([]int, error) {
+	if fields == "" {
+		return nil, nil
+	}
+	var indices []int
+	for _, f := range strings.Split(fields, ",") {
+		f = strings.TrimSpace(f)
+		if number, err := strconv.Atoi(f); err == nil {
+			if number < 1 {
+				return nil, fmt.Errorf("fields are numbered from 1: %q", f)
+			}
+			indices = append(indices, number-1)
+			continue
+		}
+		index := headerIndex(header, f)
+		if index < 0 {
+			if header == nil {
+				return nil, fmt.Errorf("invalid field %q: use a number or --header to select by name", f)
+			}
+			return nil, fmt.Errorf("unknown field %q", f)
+		}
+		indices = append(indices, index)
+	}
+	return indices, nil
+}
+
+func headerIndex(header []string, name string) int {
+	for i, column := range header {
+		if strings.TrimSpace(column) == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func selectFields(columns []string, indices []int) []string {
+	var output []string
+	for _, idx := range indices {
+		if idx >= 0 && idx < len(columns) {
+			output = append(output, columns[idx])
+		}
+	}
+	return output
+}
+
+func printSelectedFields(w io.Writer, columns []string, indices []int) {
+	if output := selectFields(columns, indices); len(output) > 0 {
+		fmt.Fprintln(w, strings.Join(output, "\t"))
+	}
+}
@@ -0,0 +1,128 @@
+package cut
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCutLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		opts     CutOptions
+		expected string
+		hasError bool
+	}{
+		{
+			name:     "tab fields",
+			input:    "a\tb\tc\n1\t2\t3\n",
+			opts:     CutOptions{fields: "1,3", delimiter: "\t"},
+			expected: "a\tc\n1\t3\n",
+		},
+		{
+			name:     "only separated",
+			input:    "a,b\nnodelim\n",
+			opts:     CutOptions{fields: "2", delimiter: ",", onlySeparated: true},
+			expected: "b\n",
+		},
+		{
+			name:     "header names",
+			input:    "id,name,age\n1,Bob,25\n",
+			opts:     CutOptions{fields: "age,id", delimiter: ",", header: true},
+			expected: "id\tage\n1\t25\n",
+		},
+		{
+			name:     "reorder with duplicates",
+			input:    "a b c\n",
+			opts:     CutOptions{fields: "3,1,3", delimiter: " ", reorder: true},
+			expected: "c\ta\tc\n",
+		},
+		{
+			name:     "regex delimiter",
+			input:    "  a   b\tc  \nnospace\n",
+			opts:     CutOptions{fields: "3,2", delimiterRegex: `\s+`, onlySeparated: true, reorder: true},
+			expected: "c\tb\n",
+		},
+		{
+			name:     "invalid regex",
+			input:    "a\n",
+			opts:     CutOptions{fields: "1", delimiterRegex: "("},
+			hasError: true,
+		},
+		{
+			name:     "name without header",
+			input:    "id,name\n",
+			opts:     CutOptions{fields: "name", delimiter: ","},
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		var out bytes.Buffer
+		err := cutLines(strings.NewReader(test.input), &out, test.opts)
+		if test.hasError {
+			if err == nil {
+				t.Errorf("%s: ожидалась ошибка, но её не произошло", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: не ожидалась ошибка, но произошла: %v", test.name, err)
+		} else if out.String() != test.expected {
+			t.Errorf("%s: ожидалось %q, но получено %q", test.name, test.expected, out.String())
+		}
+	}
+}
+
+func TestCutCSV(t *testing.T) {
+	input, err := os.ReadFile("../test_files/4.csv")
+	if err != nil {
+		t.Fatalf("не удалось прочитать файл: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		opts     CutOptions
+		expected string
+		hasError bool
+	}{
+		{
+			name:     "quoted comma",
+			opts:     CutOptions{fields: "2", delimiter: ",", csvMode: true},
+			expected: "name\n\"Smith, John\"\nAlice\nBob\n",
+		},
+		{
+			name:     "header names and multi-line field",
+			opts:     CutOptions{fields: "user_id,comment", delimiter: ",", csvMode: true, header: true},
+			expected: "user_id,comment\n1,\"said \"\"hi\"\"\"\n2,\"multi\nline\"\n3,\n",
+		},
+		{
+			name:     "unknown header",
+			opts:     CutOptions{fields: "email", delimiter: ",", csvMode: true, header: true},
+			hasError: true,
+		},
+		{
+			name:     "multi-character delimiter",
+			opts:     CutOptions{fields: "1", delimiter: "::", csvMode: true},
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		var out bytes.Buffer
+		err := cutCSV(bytes.NewReader(input), &out, test.opts)
+		if test.hasError {
+			if err == nil {
+				t.Errorf("%s: ожидалась ошибка, но её не произошло", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: не ожидалась ошибка, но произошла: %v", test.name, err)
+		} else if out.String() != test.expected {
+			t.Errorf("%s: ожидалось %q, но получено %q", test.name, test.expected, out.String())
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Package coreutils содержит встроенные команды l2sh на Go: cat, ls, mkdir, rm, cp,
+// touch и which. Они работают в процессе шелла и нужны там, где нет coreutils,
+// например в минимальных контейнерах. Подключаются через shell.Register(Commands()...).
+package coreutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"L2/L2.9/shell"
+	"L2/internal/cli"
+)
+
+// exitInterrupted — код завершения прерванной команды, как после Ctrl+C.
+const exitInterrupted = 130
+
+// command — встроенная команда: имя, синтаксис и реализация.
+type command struct {
+	name  string
+	usage string
+	run   func(ctx context.Context, io shell.IO, args []string) int
+}
+
+func (c command) Name() string  { return c.name }
+func (c command) Usage() string { return c.usage }
+
+func (c command) Run(ctx context.Context, io shell.IO, args []string) int {
+	return c.run(ctx, io, args)
+}
+
+// Commands возвращает все команды пакета.
+func Commands() []shell.Builtin {
+	return []shell.Builtin{
+		command{"cat", "cat [-n] [file ...]", cat},
+		command{"ls", "ls [-1adl] [file ...]", ls},
+		command{"mkdir", "mkdir [-p] dir ...", mkdir},
+		command{"rm", "rm [-fr] file ...", rm},
+		command{"cp", "cp [-r] source dest | cp [-r] source ... dir", cp},
+		command{"touch", "touch [-c] file ...", touch},
+		command{"which", "which [-a] name ...", which},
+	}
+}
+
+// parseOptions разбирает короткие флаги из allowed. Флаги можно объединять (-rf),
+// "--" завершает флаги, "-" считается операндом. Неизвестный флаг — ошибка
+// использования: она печатается вместе с синтаксисом, и ok == false.
+func parseOptions(io shell.IO, args []string, allowed, usage string) (opts map[byte]bool, operands []string, ok bool) {
+	opts = make(map[byte]bool)
+	name := args[0]
+	args = args[1:]
+	for len(args) > 0 && len(args[0]) > 1 && args[0][0] == '-' {
+		arg := args[0]
+		args = args[1:]
+		if arg == "--" {
+			break
+		}
+		for i := 1; i < len(arg); i++ {
+			if strings.IndexByte(allowed, arg[i]) < 0 {
+				fmt.Fprintf(io.Err, "%s: invalid option -- '%c'\n", name, arg[i])
+				fmt.Fprintln(io.Err, "usage:", usage)
+				return nil, nil, false
+			}
+			opts[arg[i]] = true
+		}
+	}
+	return opts, args, true
+}
+
+// errorf печатает сообщение об ошибке команды name, как cli.Errorf, и возвращает code.
+func errorf(io shell.IO, name string, code int, format string, args ...any) int {
+	return cli.Errorf(cli.Stdio(io.Stdio), name, code, format, args...)
+}
+
+// fail печатает ошибку операции над файлом в виде "name: file: причина",
+// где file — путь так, как его указал пользователь.
+func fail(io shell.IO, name, file string, err error) {
+	errorf(io, name, cli.ExitFailure, "%s: %v", file, unwrapPath(err))
+}
+
+// unwrapPath убирает из ошибки абсолютный путь, построенный через IO.Path.
+func unwrapPath(err error) error {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return pathErr.Err
+	}
+	var linkErr *os.LinkError
+	if errors.As(err, &linkErr) {
+		return linkErr.Err
+	}
+	return err
+}
@@ -0,0 +1,137 @@
+package coreutils
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"L2/L2.9/shell"
+)
+
+func init() {
+	shell.Register(Commands()...)
+}
+
+// TestCommands выполняет скрипты подряд в одном каталоге. PATH указывает
+// на несуществующий каталог, поэтому все команды должны быть встроенными.
+func TestCommands(t *testing.T) {
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "bin")
+	var stdout, stderr bytes.Buffer
+	in := &shell.Interpreter{
+		Stdin:  strings.NewReader("from stdin\n"),
+		Stdout: &stdout,
+		Stderr: &stderr,
+		Dir:    dir,
+		Env:    []string{"PATH=/nonexistent"},
+	}
+
+	tests := []struct {
+		script   string
+		status   int
+		expected string
+	}{
+		{"mkdir -p a/b/c && mkdir d && ls", 0, "a\nd\n"},
+		{"mkdir d", 1, ""},
+		{"mkdir", 2, ""},
+		{"touch a/x a/.hidden && touch -c missing && ls a; ls -a a", 0, "b\nx\n.\n..\n.hidden\nb\nx\n"},
+		{"echo one > a/b/f; echo two >> a/b/f; cat -n a/b/f a/b/f", 0, "     1\tone\n     2\ttwo\n     3\tone\n     4\ttwo\n"},
+		{"cat - a/b/f", 0, "from stdin\none\ntwo\n"},
+		{"cat missing a/x", 1, ""},
+		{"ls -d a a/x; ls a/x a/b", 0, "a\na/x\na/x\n\na/b:\nc\nf\n"},
+		{"ls missing", 1, ""},
+		{"ls -R", 2, ""},
+		{"cp a/b/f g && cat g && cp a d", 1, "one\ntwo\n"},
+		{"cp -r a d && ls d/a d/a/b", 0, "d/a:\nb\nx\n\nd/a/b:\nc\nf\n"},
+		{"cp g a/x d; ls d", 0, "a\ng\nx\n"},
+		{"cp g a/x missing", 1, ""},
+		{"cp -r a a/b", 1, ""},
+		{"cp g g", 1, ""},
+		{"cd a && ls; cd ..", 0, "b\nx\n"},
+		{"rm a", 1, ""},
+		{"rm -rf a g missing && ls", 0, "d\n"},
+		{"rm missing", 1, ""},
+		{"rm -rf . ..", 1, ""},
+		{"rm -fr /", 1, ""},
+		{"which cat", 1, ""},
+		{"mkdir bin && echo '#!/bin/sh' > bin/tool && PATH=bin:/nonexistent which tool", 1, ""},
+		{"type cat; type -t ls", 0, "cat is a shell builtin\nbuiltin\n"},
+		{"help cp", 0, "cp: cp [-r] source dest | cp [-r] source ... dir\n"},
+	}
+	for _, test := range tests {
+		stdout.Reset()
+		status, err := in.Run(context.Background(), test.script)
+		if err != nil {
+			t.Errorf("Для скрипта %q получена ошибка %v", test.script, err)
+		}
+		if status != test.status {
+			t.Errorf("Для скрипта %q ожидался код %d, но получен %d (stderr: %q)", test.script, test.status, status, stderr.String())
+		}
+		if stdout.String() != test.expected {
+			t.Errorf("Для скрипта %q ожидалось %q, но получено %q", test.script, test.expected, stdout.String())
+		}
+		stderr.Reset()
+	}
+
+	// which находит только исполняемые файлы, относительный каталог в PATH считается от каталога шелла.
+	if err := os.Chmod(filepath.Join(bin, "tool"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	stdout.Reset()
+	if status, _ := in.Run(context.Background(), "PATH=bin:/nonexistent:bin which -a tool; cd bin; which ./tool"); status != 0 || stdout.String() != "bin/tool\nbin/tool\n./tool\n" {
+		t.Errorf("which вывел %q с кодом %d", stdout.String(), status)
+	}
+}
+
+func TestLongListing(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "file"), []byte("12345"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("file", filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+	var stdout bytes.Buffer
+	io := shell.IO{Stdio: shell.Stdio{Out: &stdout, Err: &stdout}, Dir: dir}
+	if status := ls(context.Background(), io, []string{"ls", "-l", "file", "link"}); status != 0 {
+		t.Fatalf("ls -l завершился с кодом %d: %q", status, stdout.String())
+	}
+	lines := strings.Split(strings.TrimSuffix(stdout.String(), "\n"), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "-rw-r----- 1 ") || !strings.Contains(lines[0], " 5 ") ||
+		!strings.HasPrefix(lines[1], "lrwxrwxrwx 1 ") || !strings.HasSuffix(lines[1], " link -> file") {
+		t.Errorf("Неверный подробный список: %q", stdout.String())
+	}
+}
+
+func TestModeString(t *testing.T) {
+	tests := []struct {
+		mode     os.FileMode
+		expected string
+	}{
+		{0o644, "-rw-r--r--"},
+		{os.ModeDir | 0o755, "drwxr-xr-x"},
+		{os.ModeDir | os.ModeSticky | 0o777, "drwxrwxrwt"},
+		{os.ModeSetuid | 0o755, "-rwsr-xr-x"},
+		{os.ModeSetgid | 0o640, "-rw-r-S---"},
+		{os.ModeSymlink | 0o777, "lrwxrwxrwx"},
+		{os.ModeNamedPipe | 0o600, "prw-------"},
+	}
+	for _, test := range tests {
+		if got := modeString(test.mode); got != test.expected {
+			t.Errorf("Для %v ожидалось %q, но получено %q", test.mode, test.expected, got)
+		}
+	}
+}
+
+func TestCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var out bytes.Buffer
+	io := shell.IO{Stdio: shell.Stdio{In: strings.NewReader("data"), Out: &out, Err: &out}, Dir: t.TempDir()}
+	if status := cat(ctx, io, []string{"cat"}); status != exitInterrupted || out.Len() != 0 {
+		t.Errorf("Прерванный cat вернул код %d и вывел %q", status, out.String())
+	}
+}
@@ -0,0 +1,312 @@
+package coreutils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"syscall"
+	"time"
+
+	"L2/L2.9/shell"
+	"L2/internal/cli"
+)
+
+// ctxReader прекращает чтение, когда команду прервали.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r ctxReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+// cat печатает файлы подряд; "-" и пустой список — stdin. С -n строки нумеруются
+// сквозной нумерацией через все файлы, как в GNU cat.
+func cat(ctx context.Context, sio shell.IO, args []string) int {
+	opts, files, ok := parseOptions(sio, args, "n", "cat [-n] [file ...]")
+	if !ok {
+		return cli.ExitUsage
+	}
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	status := cli.ExitOK
+	line := 0
+	for _, name := range files {
+		if ctx.Err() != nil {
+			return exitInterrupted
+		}
+		path := name
+		if name != "-" {
+			path = sio.Path(name)
+		}
+		file, err := cli.OpenInput(path, sio.In)
+		if err != nil {
+			fail(sio, "cat", name, err)
+			status = cli.ExitFailure
+			continue
+		}
+		r := ctxReader{ctx, file}
+		if opts['n'] {
+			lines := cli.NewLineReader(r)
+			for lines.Next() {
+				line++
+				fmt.Fprintf(sio.Out, "%6d\t%s", line, lines.Bytes())
+				if lines.HasNewline() {
+					fmt.Fprintln(sio.Out)
+				}
+			}
+			err = lines.Err()
+		} else {
+			_, err = io.Copy(sio.Out, r)
+		}
+		file.Close()
+		if ctx.Err() != nil {
+			return exitInterrupted
+		}
+		if err != nil {
+			fail(sio, "cat", name, err)
+			status = cli.ExitFailure
+		}
+	}
+	return status
+}
+
+// entry — файл для вывода ls: имя, под которым его показывать, и сведения о нём.
+type entry struct {
+	name string
+	path string
+	info fs.FileInfo
+}
+
+// ls печатает содержимое каталогов по одному имени в строке, отсортированное по имени.
+// Файлы из аргументов выводятся первыми, затем каталоги с заголовком "dir:",
+// если аргументов несколько. Флаги: -a — показывать скрытые файлы, -l — подробный
+// формат, -d — выводить сами каталоги, а не их содержимое, -1 — для совместимости.
+func ls(ctx context.Context, sio shell.IO, args []string) int {
+	opts, names, ok := parseOptions(sio, args, "1adl", "ls [-1adl] [file ...]")
+	if !ok {
+		return cli.ExitUsage
+	}
+	if len(names) == 0 {
+		names = []string{"."}
+	}
+
+	status := cli.ExitOK
+	var files, dirs []entry
+	for _, name := range names {
+		path := sio.Path(name)
+		// Символическую ссылку на каталог ls раскрывает, только если не нужны подробности о ней самой.
+		stat := os.Stat
+		if opts['l'] || opts['d'] {
+			stat = os.Lstat
+		}
+		info, err := stat(path)
+		if err != nil {
+			fail(sio, "ls", name, err)
+			status = cli.ExitFailure
+			continue
+		}
+		if info.IsDir() && !opts['d'] {
+			dirs = append(dirs, entry{name, path, info})
+		} else {
+			files = append(files, entry{name, path, info})
+		}
+	}
+
+	l := lister{out: sio.Out, long: opts['l']}
+	l.print(sortEntries(files), false)
+	for i, dir := range sortEntries(dirs) {
+		if ctx.Err() != nil {
+			return exitInterrupted
+		}
+		if len(files) > 0 || i > 0 {
+			fmt.Fprintln(sio.Out)
+		}
+		if len(names) > 1 {
+			fmt.Fprintf(sio.Out, "%s:\n", dir.name)
+		}
+		entries, err := readDir(dir.path, opts['a'])
+		if err != nil {
+			fail(sio, "ls", dir.name, err)
+			status = cli.ExitFailure
+		}
+		l.print(sortEntries(entries), true)
+	}
+	return status
+}
+
+// readDir читает каталог; с all в список попадают скрытые файлы, "." и "..".
+func readDir(dir string, all bool) ([]entry, error) {
+	dirEntries, err := os.ReadDir(dir)
+	var entries []entry
+	if all {
+		for _, name := range []string{".", ".."} {
+			if info, err := os.Lstat(filepath.Join(dir, name)); err == nil {
+				entries = append(entries, entry{name, filepath.Join(dir, name), info})
+			}
+		}
+	}
+	for _, d := range dirEntries {
+		if !all && d.Name()[0] == '.' {
+			continue
+		}
+		info, infoErr := d.Info()
+		if infoErr != nil {
+			// Файл удалили между чтением каталога и stat.
+			continue
+		}
+		entries = append(entries, entry{d.Name(), filepath.Join(dir, d.Name()), info})
+	}
+	return entries, err
+}
+
+func sortEntries(entries []entry) []entry {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].name < entries[j].name })
+	return entries
+}
+
+// lister печатает списки файлов для ls; имена владельцев кэшируются.
+type lister struct {
+	out    io.Writer
+	long   bool
+	users  map[uint32]string
+	groups map[uint32]string
+}
+
+// print выводит файлы: имена или, для -l, строки подробного формата с выровненными
+// колонками. Для содержимого каталога в подробном формате первой идёт строка
+// "total" с числом занятых блоков по 1 КиБ.
+func (l *lister) print(entries []entry, dir bool) {
+	if !l.long {
+		for _, e := range entries {
+			fmt.Fprintln(l.out, e.name)
+		}
+		return
+	}
+
+	rows := make([][]string, len(entries))
+	var widths [4]int
+	var blocks int64
+	for i, e := range entries {
+		var nlink uint64 = 1
+		var uid, gid uint32
+		if st, ok := e.info.Sys().(*syscall.Stat_t); ok {
+			nlink, uid, gid = uint64(st.Nlink), st.Uid, st.Gid
+			blocks += st.Blocks
+		}
+		rows[i] = []string{
+			strconv.FormatUint(nlink, 10),
+			l.owner(&l.users, uid, func(id string) (string, error) {
+				u, err := user.LookupId(id)
+				if err != nil {
+					return "", err
+				}
+				return u.Username, nil
+			}),
+			l.owner(&l.groups, gid, func(id string) (string, error) {
+				g, err := user.LookupGroupId(id)
+				if err != nil {
+					return "", err
+				}
+				return g.Name, nil
+			}),
+			strconv.FormatInt(e.info.Size(), 10),
+		}
+		for j, field := range rows[i] {
+			widths[j] = max(widths[j], len(field))
+		}
+	}
+
+	if dir {
+		fmt.Fprintf(l.out, "total %d\n", blocks/2)
+	}
+	for i, e := range entries {
+		row := rows[i]
+		name := e.name
+		if e.info.Mode()&fs.ModeSymlink != 0 {
+			if target, err := os.Readlink(e.path); err == nil {
+				name += " -> " + target
+			}
+		}
+		fmt.Fprintf(l.out, "%s %*s %-*s %-*s %*s %s %s\n", modeString(e.info.Mode()),
+			widths[0], row[0], widths[1], row[1], widths[2], row[2], widths[3], row[3],
+			modTime(e.info.ModTime()), name)
+	}
+}
+
+// owner возвращает имя пользователя или группы по id; неизвестный id выводится числом.
+func (l *lister) owner(cache *map[uint32]string, id uint32, lookup func(string) (string, error)) string {
+	if *cache == nil {
+		*cache = make(map[uint32]string)
+	}
+	if name, ok := (*cache)[id]; ok {
+		return name
+	}
+	name, err := lookup(strconv.FormatUint(uint64(id), 10))
+	if err != nil {
+		name = strconv.FormatUint(uint64(id), 10)
+	}
+	(*cache)[id] = name
+	return name
+}
+
+// modeString возвращает права в формате ls: тип файла и три тройки rwx
+// с учётом setuid, setgid и sticky-бита.
+func modeString(mode fs.FileMode) string {
+	b := []byte("----------")
+	switch {
+	case mode.IsDir():
+		b[0] = 'd'
+	case mode&fs.ModeSymlink != 0:
+		b[0] = 'l'
+	case mode&fs.ModeNamedPipe != 0:
+		b[0] = 'p'
+	case mode&fs.ModeSocket != 0:
+		b[0] = 's'
+	case mode&fs.ModeCharDevice != 0:
+		b[0] = 'c'
+	case mode&fs.ModeDevice != 0:
+		b[0] = 'b'
+	}
+	const rwx = "rwx"
+	for i := 0; i < 9; i++ {
+		if mode&(1<<uint(8-i)) != 0 {
+			b[i+1] = rwx[i%3]
+		}
+	}
+	special := func(pos int, set bool, lower, upper byte) {
+		if !set {
+			return
+		}
+		if b[pos] == 'x' {
+			b[pos] = lower
+		} else {
+			b[pos] = upper
+		}
+	}
+	special(3, mode&fs.ModeSetuid != 0, 's', 'S')
+	special(6, mode&fs.ModeSetgid != 0, 's', 'S')
+	special(9, mode&fs.ModeSticky != 0, 't', 'T')
+	return string(b)
+}
+
+// modTime форматирует время изменения как ls: для файлов старше полугода
+// или из будущего вместо времени суток выводится год.
+func modTime(t time.Time) string {
+	if age := time.Since(t); age < 0 || age > 182*24*time.Hour {
+		return t.Format("Jan _2  2006")
+	}
+	return t.Format("Jan _2 15:04")
+}
@@ -0,0 +1,276 @@
+package coreutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"L2/L2.9/shell"
+	"L2/internal/cli"
+)
+
+// errInterrupted останавливает рекурсивные rm и cp, когда команду прервали.
+var errInterrupted = errors.New("interrupted")
+
+// mkdir создаёт каталоги; с -p — вместе с родительскими и без ошибки для существующих.
+func mkdir(ctx context.Context, sio shell.IO, args []string) int {
+	opts, dirs, ok := parseOptions(sio, args, "p", "mkdir [-p] dir ...")
+	if !ok {
+		return cli.ExitUsage
+	}
+	if len(dirs) == 0 {
+		return errorf(sio, "mkdir", cli.ExitUsage, "missing operand")
+	}
+
+	status := cli.ExitOK
+	for _, dir := range dirs {
+		var err error
+		if opts['p'] {
+			err = os.MkdirAll(sio.Path(dir), 0o777)
+		} else {
+			err = os.Mkdir(sio.Path(dir), 0o777)
+		}
+		if err != nil {
+			fail(sio, "mkdir", dir, err)
+			status = cli.ExitFailure
+		}
+	}
+	return status
+}
+
+// rm удаляет файлы, а с -r (-R) — каталоги вместе с содержимым. С -f несуществующие
+// файлы пропускаются молча. Удалять "/", "." и ".." rm отказывается.
+func rm(ctx context.Context, sio shell.IO, args []string) int {
+	opts, files, ok := parseOptions(sio, args, "frR", "rm [-fr] file ...")
+	if !ok {
+		return cli.ExitUsage
+	}
+	force, recursive := opts['f'], opts['r'] || opts['R']
+	if len(files) == 0 && !force {
+		return errorf(sio, "rm", cli.ExitUsage, "missing operand")
+	}
+
+	status := cli.ExitOK
+	for _, name := range files {
+		path := filepath.Clean(sio.Path(name))
+		if base := filepath.Base(filepath.Clean(name)); base == "." || base == ".." {
+			errorf(sio, "rm", cli.ExitFailure, "refusing to remove '.' or '..' directory: skipping '%s'", name)
+			status = cli.ExitFailure
+			continue
+		}
+		if path == "/" {
+			errorf(sio, "rm", cli.ExitFailure, "it is dangerous to operate recursively on '/'")
+			status = cli.ExitFailure
+			continue
+		}
+
+		info, err := os.Lstat(path)
+		switch {
+		case err != nil && force && errors.Is(err, fs.ErrNotExist):
+			continue
+		case err != nil:
+			fail(sio, "rm", name, err)
+			status = cli.ExitFailure
+			continue
+		case info.IsDir() && !recursive:
+			errorf(sio, "rm", cli.ExitFailure, "%s: is a directory", name)
+			status = cli.ExitFailure
+			continue
+		}
+
+		if err := removeTree(ctx, path); errors.Is(err, errInterrupted) {
+			return exitInterrupted
+		} else if err != nil {
+			fail(sio, "rm", name, err)
+			status = cli.ExitFailure
+		}
+	}
+	return status
+}
+
+// removeTree удаляет path вместе с содержимым, проверяя перед каждым файлом,
+// не прервали ли команду.
+func removeTree(ctx context.Context, path string) error {
+	if ctx.Err() != nil {
+		return errInterrupted
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if err := removeTree(ctx, filepath.Join(path, e.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return os.Remove(path)
+}
+
+// cp копирует файл в файл или несколько файлов в каталог, сохраняя права доступа.
+// Каталоги копируются только с -r (-R); символические ссылки внутри них
+// копируются ссылками.
+func cp(ctx context.Context, sio shell.IO, args []string) int {
+	const usage = "cp [-r] source dest | cp [-r] source ... dir"
+	opts, operands, ok := parseOptions(sio, args, "rR", usage)
+	if !ok {
+		return cli.ExitUsage
+	}
+	if len(operands) < 2 {
+		errorf(sio, "cp", cli.ExitUsage, "missing file operand")
+		fmt.Fprintln(sio.Err, "usage:", usage)
+		return cli.ExitUsage
+	}
+	recursive := opts['r'] || opts['R']
+	sources, dest := operands[:len(operands)-1], operands[len(operands)-1]
+
+	destInfo, err := os.Stat(sio.Path(dest))
+	intoDir := err == nil && destInfo.IsDir()
+	if len(sources) > 1 && !intoDir {
+		return errorf(sio, "cp", cli.ExitFailure, "target '%s' is not a directory", dest)
+	}
+
+	status := cli.ExitOK
+	for _, src := range sources {
+		target := sio.Path(dest)
+		if intoDir {
+			target = filepath.Join(target, filepath.Base(filepath.Clean(src)))
+		}
+		err := copyFile(ctx, sio.Path(src), target, recursive, true)
+		if errors.Is(err, errInterrupted) {
+			return exitInterrupted
+		}
+		if err != nil {
+			errorf(sio, "cp", cli.ExitFailure, "%s: %v", src, err)
+			status = cli.ExitFailure
+		}
+	}
+	return status
+}
+
+// copyFile копирует src в dst. Символическая ссылка из аргументов команды (top)
+// раскрывается, а внутри копируемого каталога — копируется ссылкой.
+func copyFile(ctx context.Context, src, dst string, recursive, top bool) error {
+	if ctx.Err() != nil {
+		return errInterrupted
+	}
+	stat := os.Lstat
+	if top {
+		stat = os.Stat
+	}
+	info, err := stat(src)
+	if err != nil {
+		return unwrapPath(err)
+	}
+	if dstInfo, err := os.Stat(dst); err == nil && os.SameFile(info, dstInfo) {
+		return fmt.Errorf("'%s' and '%s' are the same file", src, dst)
+	}
+
+	mode := info.Mode()
+	switch {
+	case mode.IsDir():
+		if !recursive {
+			return errors.New("-r not specified; omitting directory")
+		}
+		if rel, err := filepath.Rel(src, dst); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return errors.New("cannot copy a directory into itself")
+		}
+		return copyDir(ctx, src, dst, mode.Perm())
+	case mode&fs.ModeSymlink != 0:
+		target, err := os.Readlink(src)
+		if err != nil {
+			return unwrapPath(err)
+		}
+		return unwrapPath(os.Symlink(target, dst))
+	case mode.IsRegular():
+		return copyRegular(ctx, src, dst, mode.Perm())
+	default:
+		return errors.New("cannot copy a special file")
+	}
+}
+
+// copyDir копирует содержимое каталога src в dst. Права каталога выставляются
+// после копирования, чтобы каталог без права записи тоже можно было заполнить.
+func copyDir(ctx context.Context, src, dst string, perm fs.FileMode) error {
+	if err := os.Mkdir(dst, perm|0o700); err != nil && !errors.Is(err, fs.ErrExist) {
+		return unwrapPath(err)
+	}
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return unwrapPath(err)
+	}
+	for _, e := range entries {
+		if err := copyFile(ctx, filepath.Join(src, e.Name()), filepath.Join(dst, e.Name()), true, false); err != nil {
+			return err
+		}
+	}
+	return unwrapPath(os.Chmod(dst, perm))
+}
+
+// copyRegular копирует обычный файл и выставляет ему права исходного файла.
+func copyRegular(ctx context.Context, src, dst string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return unwrapPath(err)
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return unwrapPath(err)
+	}
+	_, err = io.Copy(out, ctxReader{ctx, in})
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if ctx.Err() != nil {
+		return errInterrupted
+	}
+	if err != nil {
+		return unwrapPath(err)
+	}
+	return unwrapPath(os.Chmod(dst, perm))
+}
+
+// touch создаёт пустые файлы или обновляет время изменения существующих;
+// с -c несуществующие файлы не создаются.
+func touch(ctx context.Context, sio shell.IO, args []string) int {
+	opts, files, ok := parseOptions(sio, args, "c", "touch [-c] file ...")
+	if !ok {
+		return cli.ExitUsage
+	}
+	if len(files) == 0 {
+		return errorf(sio, "touch", cli.ExitUsage, "missing file operand")
+	}
+
+	status := cli.ExitOK
+	now := time.Now()
+	for _, name := range files {
+		path := sio.Path(name)
+		err := os.Chtimes(path, now, now)
+		if errors.Is(err, fs.ErrNotExist) {
+			if opts['c'] {
+				continue
+			}
+			var file *os.File
+			if file, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0o666); err == nil {
+				err = file.Close()
+			}
+		}
+		if err != nil {
+			fail(sio, "touch", name, err)
+			status = cli.ExitFailure
+		}
+	}
+	return status
+}
@@ -0,0 +1,61 @@
+package coreutils
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"L2/L2.9/shell"
+	"L2/internal/cli"
+)
+
+// which печатает путь к программе, которую шелл запустит по каждому имени,
+// а с -a — все подходящие программы из PATH. PATH берётся из окружения шелла,
+// относительные каталоги в нём считаются от текущего каталога шелла.
+// Код завершения 1, если какое-то имя не найдено.
+func which(ctx context.Context, sio shell.IO, args []string) int {
+	opts, names, ok := parseOptions(sio, args, "a", "which [-a] name ...")
+	if !ok {
+		return cli.ExitUsage
+	}
+	if len(names) == 0 {
+		return cli.ExitFailure
+	}
+
+	status := cli.ExitOK
+	for _, name := range names {
+		found := false
+		if strings.Contains(name, "/") {
+			if found = executable(sio.Path(name)); found {
+				fmt.Fprintln(sio.Out, name)
+			}
+		} else {
+			for _, dir := range filepath.SplitList(sio.Getenv("PATH")) {
+				if dir == "" {
+					dir = "."
+				}
+				file := filepath.Join(dir, name)
+				if !executable(sio.Path(file)) {
+					continue
+				}
+				fmt.Fprintln(sio.Out, file)
+				found = true
+				if !opts['a'] {
+					break
+				}
+			}
+		}
+		if !found {
+			status = cli.ExitFailure
+		}
+	}
+	return status
+}
+
+// executable сообщает, что path — обычный файл с правом на выполнение.
+func executable(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular() && info.Mode().Perm()&0o111 != 0
+}
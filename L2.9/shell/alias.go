@@ -0,0 +1,87 @@
+package shell
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// alias возвращает значение алиаса name.
+func (sh *shell) alias(name string) (string, bool) {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	value, ok := sh.aliases[name]
+	return value, ok
+}
+
+// parse разбирает команды с учётом алиасов шелла. Алиас, определённый в строке,
+// действует со следующей строки или следующего скрипта, как и в bash.
+func (sh *shell) parse(input string) (*list, error) {
+	return parseWithAliases(input, sh.alias)
+}
+
+// builtinAlias определяет алиасы (alias name=value...) и печатает их:
+// все без аргументов или указанные по имени.
+func builtinAlias(sh *shell, args []string, s streams) int {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if len(args) < 2 {
+		names := make([]string, 0, len(sh.aliases))
+		for name := range sh.aliases {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(s.stdout, "alias %s=%s\n", name, quoteValue(sh.aliases[name]))
+		}
+		return 0
+	}
+
+	status := 0
+	for _, arg := range args[1:] {
+		name, value, hasValue := strings.Cut(arg, "=")
+		if !hasValue {
+			if value, ok := sh.aliases[name]; ok {
+				fmt.Fprintf(s.stdout, "alias %s=%s\n", name, quoteValue(value))
+			} else {
+				fmt.Fprintf(s.stderr, "alias: %s: not found\n", name)
+				status = 1
+			}
+			continue
+		}
+		if name == "" || strings.ContainsAny(name, " \t\n/$`'\"\\=|&;<>()") {
+			fmt.Fprintf(s.stderr, "alias: `%s': invalid alias name\n", name)
+			status = 1
+			continue
+		}
+		if sh.aliases == nil {
+			sh.aliases = make(map[string]string)
+		}
+		sh.aliases[name] = value
+	}
+	return status
+}
+
+// builtinUnalias удаляет алиасы; unalias -a удаляет все.
+func builtinUnalias(sh *shell, args []string, s streams) int {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if len(args) < 2 {
+		fmt.Fprintln(s.stderr, "unalias: usage: unalias [-a] name [name ...]")
+		return 2
+	}
+	if args[1] == "-a" {
+		sh.aliases = nil
+		return 0
+	}
+	status := 0
+	for _, name := range args[1:] {
+		if _, ok := sh.aliases[name]; !ok {
+			fmt.Fprintf(s.stderr, "unalias: %s: not found\n", name)
+			status = 1
+			continue
+		}
+		delete(sh.aliases, name)
+	}
+	return status
+}
@@ -0,0 +1,184 @@
+package shell
+
+import (
+	"regexp"
+	"strconv"
+	"unicode/utf8"
+)
+
+// qrune — символ слова вместе с видом кавычек, в которых он записан.
+type qrune struct {
+	r     rune
+	quote quoteKind
+}
+
+func flatten(w word) []qrune {
+	var rs []qrune
+	for _, part := range w {
+		for _, r := range part.text {
+			rs = append(rs, qrune{r, part.quote})
+		}
+	}
+	return rs
+}
+
+func unflatten(rs []qrune) word {
+	var w word
+	for _, q := range rs {
+		if n := len(w); n > 0 && w[n-1].quote == q.quote {
+			w[n-1].text += string(q.r)
+			continue
+		}
+		w = append(w, wordPart{text: string(q.r), quote: q.quote})
+	}
+	return w
+}
+
+var (
+	numberSequence = regexp.MustCompile(`^(-?\d+)\.\.(-?\d+)$`)
+	letterSequence = regexp.MustCompile(`^([a-zA-Z])\.\.([a-zA-Z])$`)
+)
+
+// expandBraces раскрывает фигурные скобки вне кавычек: pre{a,b}post даёт preapost
+// и prebpost, {1..3} — 1 2 3, {a..c} — a b c. Скобки без запятой и не в виде
+// последовательности, а также внутри ${...} и $(...) остаются как есть.
+func expandBraces(w word) []word {
+	rs := flatten(w)
+	open, close, alternatives := findBraces(rs)
+	if open < 0 {
+		return []word{w}
+	}
+
+	var result []word
+	for _, alt := range alternatives {
+		combined := append(append(append([]qrune(nil), rs[:open]...), alt...), rs[close+1:]...)
+		result = append(result, expandBraces(unflatten(combined))...)
+	}
+	return result
+}
+
+// findBraces ищет первую раскрываемую пару скобок и возвращает её границы и варианты.
+func findBraces(rs []qrune) (open, close int, alternatives [][]qrune) {
+	for i := 0; i < len(rs); i++ {
+		switch {
+		case rs[i].quote != unquoted:
+		case rs[i].r == '$' || rs[i].r == '`':
+			i = skipSubstitution(rs, i)
+		case rs[i].r == '{':
+			if close, alternatives := braceAlternatives(rs, i); close >= 0 {
+				return i, close, alternatives
+			}
+		}
+	}
+	return -1, -1, nil
+}
+
+// braceAlternatives разбирает скобки, открытые в rs[open], по запятым верхнего уровня.
+func braceAlternatives(rs []qrune, open int) (int, [][]qrune) {
+	depth := 0
+	start := open + 1
+	var alternatives [][]qrune
+	for i := open; i < len(rs); i++ {
+		if rs[i].quote != unquoted {
+			continue
+		}
+		switch rs[i].r {
+		case '$', '`':
+			i = skipSubstitution(rs, i)
+		case '{':
+			depth++
+		case ',':
+			if depth == 1 {
+				alternatives = append(alternatives, rs[start:i])
+				start = i + 1
+			}
+		case '}':
+			depth--
+			if depth > 0 {
+				continue
+			}
+			if alternatives != nil {
+				return i, append(alternatives, rs[start:i])
+			}
+			if seq := sequence(rs[start:i]); seq != nil {
+				return i, seq
+			}
+			return -1, nil
+		}
+	}
+	return -1, nil
+}
+
+// sequence раскрывает содержимое скобок вида 1..5 или a..e.
+func sequence(rs []qrune) [][]qrune {
+	var text []rune
+	for _, q := range rs {
+		if q.quote != unquoted {
+			return nil
+		}
+		text = append(text, q.r)
+	}
+
+	var items []string
+	if m := numberSequence.FindStringSubmatch(string(text)); m != nil {
+		from, _ := strconv.Atoi(m[1])
+		to, _ := strconv.Atoi(m[2])
+		for n := from; ; n += step(from, to) {
+			items = append(items, strconv.Itoa(n))
+			if n == to {
+				break
+			}
+		}
+	} else if m := letterSequence.FindStringSubmatch(string(text)); m != nil {
+		from, to := int(m[1][0]), int(m[2][0])
+		for c := from; ; c += step(from, to) {
+			items = append(items, string(rune(c)))
+			if c == to {
+				break
+			}
+		}
+	} else {
+		return nil
+	}
+
+	result := make([][]qrune, 0, len(items))
+	for _, item := range items {
+		result = append(result, flatten(word{{text: item}}))
+	}
+	return result
+}
+
+func step(from, to int) int {
+	if from > to {
+		return -1
+	}
+	return 1
+}
+
+// skipSubstitution возвращает индекс последнего символа подстановки ${...}, $(...)
+// или `...`, начинающейся в rs[i]. Если это не подстановка, возвращается i.
+func skipSubstitution(rs []qrune, i int) int {
+	var text []rune
+	for j := i; j < len(rs) && rs[j].quote == unquoted; j++ {
+		text = append(text, rs[j].r)
+	}
+	s := string(text)
+	end := -1
+	switch {
+	case len(text) > 1 && text[0] == '$' && text[1] == '{':
+		end = matchingClose(s, '{', '}')
+	case len(text) > 1 && text[0] == '$' && text[1] == '(':
+		end = matchingClose(s, '(', ')')
+	case text[0] == '`':
+		for j := 1; j < len(s); j++ {
+			if s[j] == '`' && !isEscaped(s, j) {
+				end = j
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return i
+	}
+	return i + utf8.RuneCountInString(s[:end+1]) - 1
+}
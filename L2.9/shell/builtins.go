@@ -0,0 +1,269 @@
+package shell
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"syscall"
+)
+
+// builtinFunc — встроенная команда. Она выполняется в процессе шелла и работает
+// только с переданными потоками, поэтому может быть стадией конвейера.
+type builtinFunc func(sh *shell, args []string, s streams) int
+
+// coreBuiltin — стандартная встроенная команда шелла. Она работает с состоянием
+// шелла, поэтому получает его и потоки команды из IO; в реестре она хранится
+// наравне с командами из Register.
+type coreBuiltin struct {
+	name  string
+	usage string
+	run   builtinFunc
+}
+
+func (b coreBuiltin) Name() string  { return b.name }
+func (b coreBuiltin) Usage() string { return b.usage }
+
+func (b coreBuiltin) Run(ctx context.Context, io IO, args []string) int {
+	return b.run(io.sh, args, io.streams)
+}
+
+// Стандартные команды регистрируются в init: fg, wait и help через шелл снова
+// обращаются к реестру.
+func init() {
+	Register(
+		coreBuiltin{"cd", "cd dir", builtinCd},
+		coreBuiltin{"pwd", "pwd", builtinPwd},
+		coreBuiltin{"echo", "echo [arg ...]", builtinEcho},
+		coreBuiltin{"kill", "kill [-s SIGNAL | -SIGNAL] pid | %job ... or kill -l [signal]", builtinKill},
+		coreBuiltin{"ps", "ps [-e] [-f] [-o field,...] [--sort=[-]field,...]", builtinPs},
+		coreBuiltin{"pgrep", "pgrep [-flxno] [-u user] [-P ppid] pattern", builtinPgrep},
+		coreBuiltin{"pkill", "pkill [-SIGNAL] [-fxno] [-u user] [-P ppid] pattern", builtinPgrep},
+		coreBuiltin{"jobs", "jobs", builtinJobs},
+		coreBuiltin{"fg", "fg [%job]", builtinFg},
+		coreBuiltin{"bg", "bg [%job]", builtinBg},
+		coreBuiltin{"wait", "wait [pid | %job ...]", builtinWait},
+		coreBuiltin{"export", "export [name[=value] ...]", builtinExport},
+		coreBuiltin{"unset", "unset name ...", builtinUnset},
+		coreBuiltin{"set", "set [-o option | +o option] [--] [arg ...]", builtinSet},
+		coreBuiltin{"alias", "alias [name[=value] ...]", builtinAlias},
+		coreBuiltin{"unalias", "unalias [-a] name ...", builtinUnalias},
+		coreBuiltin{"env", "env [name=value ...] [command [arg ...]]", builtinEnv},
+		coreBuiltin{"ulimit", "ulimit [-SH] [-a] [-t|-d|-n|-v] [limit]", builtinUlimit},
+		coreBuiltin{"exit", "exit [n]", builtinExit},
+		coreBuiltin{"return", "return [n]", builtinReturn},
+		coreBuiltin{"break", "break [n]", loopBuiltin("break", flowBreak)},
+		coreBuiltin{"continue", "continue [n]", loopBuiltin("continue", flowContinue)},
+		coreBuiltin{"shift", "shift [n]", builtinShift},
+		coreBuiltin{"read", "read [name ...]", builtinRead},
+		coreBuiltin{"source", "source file [arg ...]", builtinSource},
+		coreBuiltin{".", ". file [arg ...]", builtinSource},
+		coreBuiltin{"test", "test expr", builtinTest},
+		coreBuiltin{"[", "[ expr ]", builtinTest},
+		coreBuiltin{"true", "true", func(*shell, []string, streams) int { return 0 }},
+		coreBuiltin{"false", "false", func(*shell, []string, streams) int { return 1 }},
+		coreBuiltin{":", ": [arg ...]", func(*shell, []string, streams) int { return 0 }},
+		coreBuiltin{"help", "help [name ...]", builtinHelp},
+		coreBuiltin{"type", "type [-t] name ...", builtinType},
+	)
+}
+
+// builtinCd меняет каталог всего шелла, даже если cd стоит в конвейере
+// (как в zsh, а не в bash, где стадии конвейера — подоболочки). Каталог процесса
+// не меняется: внешние команды запускаются в каталоге шелла. PWD обновляется.
+func builtinCd(sh *shell, args []string, s streams) int {
+	if len(args) < 2 {
+		fmt.Fprintln(s.stderr, "cd: missing argument")
+		return 1
+	}
+	dir := sh.path(args[1])
+	info, err := os.Stat(dir)
+	if err == nil && !info.IsDir() {
+		err = syscall.ENOTDIR
+	}
+	if err == nil && sh.restricted != nil {
+		if err = sh.restricted.checkPath(dir); errors.Is(err, errRestricted) {
+			sh.audit("denied", args, err)
+			fmt.Fprintln(s.stderr, "cd:", err)
+			return 1
+		}
+	}
+	if err != nil {
+		if pathErr, ok := err.(*os.PathError); ok {
+			err = pathErr.Err
+		}
+		fmt.Fprintln(s.stderr, "cd error:", &os.PathError{Op: "chdir", Path: args[1], Err: err})
+		return 1
+	}
+	sh.mu.Lock()
+	sh.dir = dir
+	sh.mu.Unlock()
+	sh.setVar("PWD", dir)
+	return 0
+}
+
+func builtinPwd(sh *shell, args []string, s streams) int {
+	fmt.Fprintln(s.stdout, sh.cwd())
+	return 0
+}
+
+func builtinEcho(sh *shell, args []string, s streams) int {
+	fmt.Fprintln(s.stdout, strings.Join(args[1:], " "))
+	return 0
+}
+
+// builtinExport помечает переменные как экспортируемые (export NAME или export NAME=value).
+// Без аргументов печатает экспортированные переменные.
+func builtinExport(sh *shell, args []string, s streams) int {
+	if len(args) < 2 {
+		for _, kv := range sh.environ(nil) {
+			name, value, _ := strings.Cut(kv, "=")
+			fmt.Fprintf(s.stdout, "export %s=%s\n", name, quoteValue(value))
+		}
+		return 0
+	}
+	status := 0
+	for _, arg := range args[1:] {
+		name, value, hasValue := strings.Cut(arg, "=")
+		if !isName(name) {
+			fmt.Fprintf(s.stderr, "export: `%s': not a valid identifier\n", arg)
+			status = 1
+			continue
+		}
+		if hasValue {
+			sh.setVar(name, value)
+		}
+		sh.exportVar(name)
+	}
+	return status
+}
+
+// quoteValue заключает значение в одинарные кавычки так, чтобы его можно было ввести обратно.
+func quoteValue(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+func builtinUnset(sh *shell, args []string, s streams) int {
+	for _, name := range args[1:] {
+		sh.unsetVar(name)
+	}
+	return 0
+}
+
+// shellOptions — параметры, которые включает set -o и выключает set +o.
+var shellOptions = []string{"pipefail"}
+
+func (sh *shell) option(name string) bool {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	return sh.options[name]
+}
+
+func (sh *shell) setOption(name string, on bool) {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if sh.options == nil {
+		sh.options = make(map[string]bool)
+	}
+	sh.options[name] = on
+}
+
+// builtinSet включает (-o name) и выключает (+o name) параметры шелла; остальные
+// аргументы (или все после --) становятся позиционными параметрами. Без аргументов
+// и с одним -o печатает состояние параметров.
+func builtinSet(sh *shell, args []string, s streams) int {
+	args = args[1:]
+	if len(args) == 0 || len(args) == 1 && (args[0] == "-o" || args[0] == "+o") {
+		for _, name := range shellOptions {
+			state := "off"
+			if sh.option(name) {
+				state = "on"
+			}
+			fmt.Fprintf(s.stdout, "%-15s\t%s\n", name, state)
+		}
+		return 0
+	}
+
+	for len(args) > 0 {
+		arg := args[0]
+		if arg == "--" {
+			sh.setPositional(args[1:])
+			return 0
+		}
+		if arg != "-o" && arg != "+o" {
+			if strings.HasPrefix(arg, "-") || strings.HasPrefix(arg, "+") {
+				fmt.Fprintf(s.stderr, "set: %s: invalid option\n", arg)
+				return 2
+			}
+			sh.setPositional(args)
+			return 0
+		}
+		if len(args) < 2 || !slices.Contains(shellOptions, args[1]) {
+			name := ""
+			if len(args) > 1 {
+				name = args[1]
+			}
+			fmt.Fprintf(s.stderr, "set: %s: invalid option name\n", name)
+			return 2
+		}
+		sh.setOption(args[1], arg == "-o")
+		args = args[2:]
+	}
+	return 0
+}
+
+// builtinEnv печатает окружение команды, а с аргументами "env NAME=value... cmd args..."
+// запускает cmd с дополненным окружением.
+func builtinEnv(sh *shell, args []string, s streams) int {
+	env := s.env
+	if env == nil {
+		env = sh.environ(nil)
+	}
+	rest := args[1:]
+	var assigns []string
+	for len(rest) > 0 && strings.Contains(rest[0], "=") {
+		assigns = append(assigns, rest[0])
+		rest = rest[1:]
+	}
+	env = mergeEnv(env, assigns)
+
+	if len(rest) == 0 {
+		for _, kv := range env {
+			fmt.Fprintln(s.stdout, kv)
+		}
+		return 0
+	}
+	s.env = env
+	cmd := sh.externalCommand(rest, s)
+	err := sh.startProcess(cmd)
+	if err == nil {
+		err = cmd.Wait()
+	}
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		fmt.Fprintln(s.stderr, "env:", err)
+	}
+	return exitStatus(err)
+}
+
+// mergeEnv дополняет окружение env присваиваниями NAME=value, заменяя одноимённые переменные.
+func mergeEnv(env, assigns []string) []string {
+	result := make([]string, 0, len(env)+len(assigns))
+	for _, kv := range env {
+		name, _, _ := strings.Cut(kv, "=")
+		replaced := false
+		for _, a := range assigns {
+			if strings.HasPrefix(a, name+"=") {
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			result = append(result, kv)
+		}
+	}
+	return append(result, assigns...)
+}
@@ -0,0 +1,136 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// commandStarters — слова, после которых снова начинается команда.
+var commandStarters = map[string]bool{
+	"if": true, "then": true, "elif": true, "else": true, "while": true, "until": true,
+	"do": true, "{": true, "!": true,
+}
+
+// completeLine дополняет слово перед курсором. Первое слово команды дополняется
+// именем встроенной команды, функции или программы из PATH, остальные слова
+// и слова с / — путём к файлу.
+func (sh *shell) completeLine(line []rune, pos int) (int, []string) {
+	start := pos
+	for start > 0 && (!isMeta(line[start-1]) || start > 1 && line[start-2] == '\\') {
+		start--
+	}
+	word := string(line[start:pos])
+
+	before := strings.TrimRight(string(line[:start]), " \t")
+	fields := strings.Fields(before)
+	command := before == "" || strings.ContainsRune(";|&(", rune(before[len(before)-1])) ||
+		commandStarters[fields[len(fields)-1]]
+	if command && !strings.Contains(word, "/") {
+		return start, sh.completeCommand(word)
+	}
+	return start, sh.completePath(word)
+}
+
+// completeCommand возвращает имена команд, начинающиеся с prefix.
+func (sh *shell) completeCommand(prefix string) []string {
+	names := make(map[string]bool)
+	for name := range sh.builtinUsages() {
+		names[name] = true
+	}
+	sh.mu.Lock()
+	for name := range sh.funcs {
+		names[name] = true
+	}
+	for name := range sh.aliases {
+		names[name] = true
+	}
+	sh.mu.Unlock()
+
+	path, _ := sh.getVar("PATH")
+	for _, dir := range filepath.SplitList(path) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !strings.HasPrefix(entry.Name(), prefix) || entry.IsDir() {
+				continue
+			}
+			if info, err := os.Stat(filepath.Join(dir, entry.Name())); err == nil && info.Mode().IsRegular() && info.Mode()&0o111 != 0 {
+				names[entry.Name()] = true
+			}
+		}
+	}
+
+	var result []string
+	for name := range names {
+		if strings.HasPrefix(name, prefix) {
+			result = append(result, escapeWord(name)+" ")
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// completePath возвращает пути, начинающиеся с word. К каталогам добавляется /,
+// к файлам — пробел; ~/ в начале раскрывается для поиска, но остаётся в результате.
+func (sh *shell) completePath(word string) []string {
+	word = unescapeWord(word)
+	dir, base := "", word
+	if i := strings.LastIndexByte(word, '/'); i >= 0 {
+		dir, base = word[:i+1], word[i+1:]
+	}
+	listDir := dir
+	if rest, ok := strings.CutPrefix(listDir, "~/"); ok {
+		home, _ := sh.getVar("HOME")
+		listDir = filepath.Join(home, rest)
+	}
+	listDir = sh.path(listDir)
+	if listDir == "" {
+		listDir = sh.cwd()
+	}
+
+	entries, err := os.ReadDir(listDir)
+	if err != nil {
+		return nil
+	}
+	var result []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, base) || strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".") {
+			continue
+		}
+		suffix := " "
+		if info, err := os.Stat(filepath.Join(listDir, name)); err == nil && info.IsDir() {
+			suffix = "/"
+		}
+		result = append(result, escapeWord(dir+name)+suffix)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// escapeWord экранирует обратной косой чертой символы, которые шелл разобрал бы особо.
+func escapeWord(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if isMeta(r) || strings.ContainsRune("\\'\"$`*?[]{}#", r) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+func unescapeWord(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
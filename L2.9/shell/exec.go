@@ -0,0 +1,385 @@
+package shell
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// runList выполняет команды списка по очереди с потоками base и возвращает код
+// завершения последней. Списки, завершённые &, запускаются фоновыми заданиями.
+// exit, return, break и continue прерывают список.
+func (sh *shell) runList(l *list, base streams) int {
+	if base.ctl == nil {
+		base.ctl = &control{}
+	}
+	run := func(pl *pipeline) int { return sh.runForeground(pl, base) }
+	if base.job != nil {
+		run = func(pl *pipeline) int { return sh.runPipeline(pl, base.job, base) }
+	}
+
+	status := 0
+	for _, item := range l.items {
+		if sh.cancelled() {
+			break
+		}
+		if item.background {
+			status = sh.startBackground(item, base)
+			sh.setStatus(status)
+			continue
+		}
+		status = runAndOr(item, base.ctl, run)
+		if base.ctl.interrupted() {
+			break
+		}
+	}
+	return status
+}
+
+// runAndOr выполняет конвейеры с учётом && и ||: следующий конвейер запускается,
+// только если код предыдущего результата подходит оператору. run выполняет один конвейер.
+func runAndOr(ao *andOr, ctl *control, run func(*pipeline) int) int {
+	status := run(ao.pipelines[0])
+	for i, op := range ao.ops {
+		if ctl.interrupted() {
+			break
+		}
+		if (op == tokAndIf && status != 0) || (op == tokOrIf && status == 0) {
+			continue
+		}
+		status = run(ao.pipelines[i+1])
+	}
+	return status
+}
+
+// runForeground выполняет конвейер как задание переднего плана; его код становится $?.
+func (sh *shell) runForeground(pl *pipeline, base streams) int {
+	j := newJob(pl.text, true)
+	go func() { j.finish(sh.runPipeline(pl, j, base)) }()
+	status := sh.waitForeground(j)
+	sh.setStatus(status)
+	return status
+}
+
+// startBackground запускает список как фоновое задание и сразу возвращает управление.
+// Конвейеры внутри составных команд списка выполняются в том же задании.
+func (sh *shell) startBackground(ao *andOr, base streams) int {
+	j := newJob(ao.text, false)
+	base.ctl, base.job = &control{}, j
+	// Без управления заданиями фоновое задание не должно забирать ввод шелла.
+	var null *os.File
+	if !sh.interactive && base.stdin == sh.stdin && sh.stdin == os.Stdin {
+		if f, err := os.Open(os.DevNull); err == nil {
+			null, base.stdin = f, f
+		}
+	}
+
+	sh.addJob(j)
+	go func() {
+		j.finish(runAndOr(ao, base.ctl, func(pl *pipeline) int { return sh.runPipeline(pl, j, base) }))
+		if null != nil {
+			null.Close()
+		}
+	}()
+
+	<-j.started
+	if pgid := j.pgroup(); pgid != 0 {
+		sh.mu.Lock()
+		sh.lastBackground = pgid
+		sh.mu.Unlock()
+		fmt.Fprintf(base.stderr, "[%d] %d\n", j.id, pgid)
+	} else {
+		fmt.Fprintf(base.stderr, "[%d]\n", j.id)
+	}
+	return 0
+}
+
+// runPipeline запускает все стадии конвейера в составе задания j, соединяя соседние
+// каналом os.Pipe, дожидается каждой и возвращает код завершения последней,
+// а с set -o pipefail — последний ненулевой. Встроенные команды работают в горутинах
+// наравне с внешними процессами, внешние процессы конвейера образуют новую группу
+// процессов. Первая стадия читает outer.stdin, последняя пишет в outer.stdout,
+// stderr у всех стадий общий. Стадии конвейера из нескольких команд получают свой
+// ctl: exit в них не завершает шелл.
+//
+// Если стадию не удалось запустить, остальные не запускаются, уже запущенные
+// процессы завершаются, а кодом конвейера становится код этой стадии.
+// После отмены контекста шелла запущенные процессы тоже завершаются.
+func (sh *shell) runPipeline(pl *pipeline, j *job, outer streams) int {
+	j.update(func() { j.pgid = 0 })
+	defer j.markStarted()
+
+	stdin := outer.stdin
+	var procs []*process
+	status := -1
+	for i, c := range pl.commands {
+		base := streams{stdin: stdin, stdout: outer.stdout, stderr: outer.stderr, ctl: outer.ctl, job: outer.job}
+		if len(pl.commands) > 1 {
+			base.ctl = &control{}
+		}
+		var release []io.Closer
+		if i > 0 {
+			release = append(release, stdin.(*os.File))
+		}
+		var next *os.File
+		if i < len(pl.commands)-1 {
+			pipeReader, pipeWriter, err := os.Pipe()
+			if err != nil {
+				fmt.Fprintln(outer.stderr, "Error creating pipe:", err)
+				closeAll(release)
+				status = 1
+				break
+			}
+			base.stdout = pipeWriter
+			release = append(release, pipeWriter)
+			next = pipeReader
+		}
+		p, err := sh.startCommand(c, base, func() { closeAll(release) }, j)
+		procs = append(procs, p)
+		if err != nil {
+			if next != nil {
+				next.Close()
+			}
+			status = j.waitProcesses([]*process{p}, false)
+			break
+		}
+		stdin = next
+	}
+
+	j.markStarted()
+	if status >= 0 {
+		j.killProcesses(procs)
+		j.waitProcesses(procs, false)
+	} else {
+		if sh.cancelled() {
+			// Run отменён, пока запускались стадии: waitForeground мог не застать группу.
+			j.killProcesses(procs)
+		}
+		status = j.waitProcesses(procs, sh.option("pipefail"))
+	}
+	if pl.negate {
+		status = boolStatus(status != 0)
+	}
+	return status
+}
+
+// boolStatus переводит условие в код завершения: true — 0, false — 1.
+func boolStatus(ok bool) int {
+	if ok {
+		return 0
+	}
+	return 1
+}
+
+func closeAll(closers []io.Closer) {
+	for _, c := range closers {
+		c.Close()
+	}
+}
+
+// runSimpleCommand выполняет одну команду с потоками base и возвращает код завершения.
+func (sh *shell) runSimpleCommand(cmd *simpleCommand, base streams) int {
+	if base.ctl == nil {
+		base.ctl = &control{}
+	}
+	j := newJob("", false)
+	p, _ := sh.startCommand(cmd, base, func() {}, j)
+	return j.waitProcesses([]*process{p}, false)
+}
+
+// startCommand применяет перенаправления и запускает команду в составе задания j:
+// встроенную, функцию или составную команду — в горутине, внешнюю — отдельным
+// процессом в группе задания. release вызывается, когда шеллу больше не нужны
+// потоки base (концы каналов): сразу после запуска процесса или по завершении
+// команды в горутине. Ошибка возвращается, только если не удалось запустить
+// внешний процесс; процесс стадии тогда уже завершён с кодом 126 или 127.
+// Префиксы time и timeout простой команды обрабатываются здесь же (см. wrapper).
+func (sh *shell) startCommand(cmd command, base streams, release func(), j *job) (*process, error) {
+	finished := func(status int) (*process, error) {
+		p := j.addProcess(0)
+		j.exit(p, status)
+		return p, nil
+	}
+	inShell := func(s streams, closeFiles func(), run func(streams) int) (*process, error) {
+		p := j.addProcess(0)
+		go func() {
+			status := run(s)
+			closeFiles()
+			release()
+			j.exit(p, status)
+		}()
+		return p, nil
+	}
+
+	switch c := cmd.(type) {
+	case *funcDef:
+		sh.defineFunction(c)
+		release()
+		return finished(0)
+	case interface{ redirections() []*redirect }:
+		s, closeFiles, err := sh.applyRedirects(c.redirections(), base)
+		if err != nil {
+			fmt.Fprintln(base.stderr, err)
+			release()
+			return finished(1)
+		}
+		return inShell(s, closeFiles, func(s streams) int { return sh.runCompound(cmd, s) })
+	}
+
+	simple := cmd.(*simpleCommand)
+	s, closeFiles, err := sh.applyRedirects(simple.redirs, base)
+	if err != nil {
+		fmt.Fprintln(base.stderr, err)
+		release()
+		return finished(1)
+	}
+
+	assignWords, words := splitAssignments(simple.args)
+	e := sh.expander()
+	var assigns []string
+	for _, w := range assignWords {
+		assigns = append(assigns, e.expandAssignment(w))
+	}
+	args := e.expandArgs(words)
+	if e.err != nil {
+		fmt.Fprintln(base.stderr, e.err)
+		closeFiles()
+		release()
+		return finished(1)
+	}
+	if len(args) > 0 {
+		sh.audit("run", args, nil)
+	}
+	args, w, ok := parseWrappers(args, base.stderr)
+	if !ok {
+		closeFiles()
+		release()
+		return finished(125)
+	}
+	if len(args) == 0 {
+		// Присваивания без команды меняют переменные самого шелла, а кодом
+		// становится код последней подстановки команды в них.
+		for _, kv := range assigns {
+			name, value, _ := strings.Cut(kv, "=")
+			sh.setVar(name, value)
+		}
+		closeFiles()
+		release()
+		return finished(w.runInShell(func() int { return e.status }))
+	}
+	s.env = sh.environ(assigns)
+
+	if fn, ok := sh.function(args[0]); ok && !w.external() {
+		return inShell(s, closeFiles, func(s streams) int {
+			return w.runInShell(func() int { return sh.callFunction(fn, args, s) })
+		})
+	}
+	if builtin, ok := sh.builtin(args[0]); ok && !w.external() {
+		return inShell(s, closeFiles, func(s streams) int {
+			return w.runInShell(func() int { return sh.runBuiltin(builtin, args, s) })
+		})
+	}
+
+	proc := sh.externalCommand(args, s)
+	j.mu.Lock()
+	proc.SysProcAttr = &syscall.SysProcAttr{Setpgid: true, Pgid: j.pgid}
+	// Первый процесс задания переднего плана сам забирает терминал ещё до exec,
+	// иначе он мог бы успеть прочитать терминал из фоновой группы и получить SIGTTIN.
+	if sh.interactive && j.foreground && j.pgid == 0 {
+		proc.SysProcAttr.Foreground = true
+		proc.SysProcAttr.Ctty = 0
+	}
+	w.starting()
+	err = sh.startProcess(proc)
+	if err == nil {
+		if j.pgid == 0 {
+			j.pgid = proc.Process.Pid
+		}
+		w.started(proc.Process.Pid, j.pgid == proc.Process.Pid)
+	}
+	j.mu.Unlock()
+	closeFiles()
+	release()
+	if err != nil {
+		fmt.Fprintln(s.stderr, "Command execution error:", err)
+		p, _ := finished(w.finish(nil, exitStatus(err)))
+		return p, err
+	}
+
+	p := j.addProcess(proc.Process.Pid)
+	go j.watchProcess(p, proc, w.finish)
+	return p, nil
+}
+
+// externalCommand создаёт процесс с потоками и окружением s, запускаемый в текущем
+// каталоге шелла; запускать его нужно через startProcess, чтобы учесть ulimit.
+// В ограниченном режиме запускаются только разрешённые программы. Программа ищется
+// по PATH из окружения команды, а не из окружения процесса шелла; относительные пути
+// считаются от каталога шелла.
+func (sh *shell) externalCommand(args []string, s streams) *exec.Cmd {
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = s.stdin
+	cmd.Stdout = s.stdout
+	cmd.Stderr = s.stderr
+	cmd.Dir = sh.cwd()
+	if s.env != nil {
+		cmd.Env = s.env
+		cmd.Path, cmd.Err = lookPath(args[0], envValue(s.env, "PATH"), cmd.Dir)
+	}
+	sh.restrictCommand(cmd, args)
+	return cmd
+}
+
+// lookPath ищет исполняемый файл name в каталогах path, как exec.LookPath.
+// Относительные имена и каталоги разрешаются от каталога dir.
+func lookPath(name, path, dir string) (string, error) {
+	abs := func(p string) string {
+		if filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(dir, p)
+	}
+	if strings.Contains(name, "/") {
+		return exec.LookPath(abs(name))
+	}
+	for _, d := range filepath.SplitList(path) {
+		if file, err := exec.LookPath(filepath.Join(abs(d), name)); err == nil {
+			return file, nil
+		}
+	}
+	return name, &exec.Error{Name: name, Err: exec.ErrNotFound}
+}
+
+// envValue возвращает значение переменной name из окружения env.
+func envValue(env []string, name string) string {
+	for _, kv := range env {
+		if value, ok := strings.CutPrefix(kv, name+"="); ok {
+			return value
+		}
+	}
+	return ""
+}
+
+// exitStatus переводит результат exec.Cmd.Run в код завершения: 127 — команда не найдена,
+// 126 — не удалось запустить, 128+N — процесс завершён сигналом N, иначе код процесса.
+func exitStatus(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			return 128 + int(status.Signal())
+		}
+		return exitErr.ExitCode()
+	}
+	if errors.Is(err, exec.ErrNotFound) {
+		return 127
+	}
+	return 126
+}
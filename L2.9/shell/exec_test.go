@@ -0,0 +1,113 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPipelineBuiltins(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"echo hi | cat > " + out, "hi\n"},
+		{"echo one two | tr a-z A-Z | cat > " + out, "ONE TWO\n"},
+		{"printf 'x\\ny\\n' | echo ignored > " + out, "ignored\n"},
+	}
+
+	for _, test := range tests {
+		os.Remove(out)
+		tree, err := parse(test.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sh := newShell()
+		if status := sh.runList(tree, sh.stdStreams()); status != 0 {
+			t.Errorf("Для ввода %q ожидался код 0, но получен %d", test.input, status)
+		}
+		data, _ := os.ReadFile(out)
+		if string(data) != test.expected {
+			t.Errorf("Для ввода %q ожидалось %q, но получено %q", test.input, test.expected, data)
+		}
+	}
+}
+
+func TestBackgroundJobs(t *testing.T) {
+	sh := newShell()
+	run := func(input string) int {
+		t.Helper()
+		tree, err := parse(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sh.runList(tree, sh.stdStreams())
+	}
+
+	if status := run("sleep 0.1 & false &"); status != 0 {
+		t.Errorf("Запуск в фоне должен возвращать 0, но получен %d", status)
+	}
+	list, marks := sh.jobList()
+	if len(list) != 2 || list[0].text != "sleep 0.1" || marks[list[1]] != '+' || marks[list[0]] != '-' {
+		t.Fatalf("Ожидалось два задания, последнее текущее, но получено %d", len(list))
+	}
+	if status := run("wait %2"); status != 1 {
+		t.Errorf("Ожидался код 1 для wait %%2, но получен %d", status)
+	}
+	if _, err := sh.findJob("%sl"); err != nil {
+		t.Errorf("Задание должно находиться по префиксу команды: %v", err)
+	}
+	if status := run("wait"); status != 0 {
+		t.Errorf("Ожидался код 0 для wait, но получен %d", status)
+	}
+	if list[0].state() != "Done" {
+		t.Errorf("Ожидалось состояние Done, но получено %s", list[0].state())
+	}
+	if jobs, _ := sh.jobList(); len(jobs) != 0 {
+		t.Errorf("После wait таблица заданий должна быть пустой, но в ней %d заданий", len(jobs))
+	}
+	if _, err := sh.findJob("%7"); err == nil {
+		t.Error("Ожидалась ошибка для несуществующего задания, но её не произошло")
+	}
+}
+
+func TestPipelineStatus(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	tests := []struct {
+		input  string
+		status int
+		stderr string
+	}{
+		{"false | true", 0, ""},
+		{"true | false", 1, ""},
+		{"! true | false", 0, ""},
+		{"set -o pipefail; false | true", 1, ""},
+		{"set -o pipefail; true | sh -c 'exit 3' | sh -c 'exit 4' | true", 4, ""},
+		{"set -o pipefail; set +o pipefail; false | true", 0, ""},
+		{"{ sh -c 'echo err >&2' | true; } 2> " + out, 0, "err\n"},
+		{"{ sleep 10 | l2-no-such-command; } 2> /dev/null", 127, ""},
+		{"{ l2-no-such-command | sleep 10; } 2> /dev/null", 127, ""},
+	}
+
+	for _, test := range tests {
+		os.WriteFile(out, nil, 0o644)
+		tree, err := parse(test.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sh := newShell()
+		start := time.Now()
+		if status := sh.runList(tree, sh.stdStreams()); status != test.status {
+			t.Errorf("Для ввода %q ожидался код %d, но получен %d", test.input, test.status, status)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("Для ввода %q запущенные стадии должны быть завершены, но конвейер шёл %v", test.input, elapsed)
+		}
+		if data, _ := os.ReadFile(out); string(data) != test.stderr {
+			t.Errorf("Для ввода %q ожидался stderr %q, но получено %q", test.input, test.stderr, data)
+		}
+	}
+}
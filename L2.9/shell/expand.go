@@ -0,0 +1,691 @@
+package shell
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// variable — переменная шелла. Экспортированные переменные попадают в окружение команд.
+type variable struct {
+	value    string
+	exported bool
+}
+
+// initVars заполняет переменные шелла окружением процесса; все они экспортированы.
+func (sh *shell) initVars(environ []string) {
+	sh.vars = make(map[string]*variable)
+	for _, kv := range environ {
+		if name, value, ok := strings.Cut(kv, "="); ok {
+			sh.vars[name] = &variable{value: value, exported: true}
+		}
+	}
+}
+
+func (sh *shell) getVar(name string) (string, bool) {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if v, ok := sh.vars[name]; ok {
+		return v.value, true
+	}
+	return "", false
+}
+
+func (sh *shell) setVar(name, value string) {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if v, ok := sh.vars[name]; ok {
+		v.value = value
+		return
+	}
+	sh.vars[name] = &variable{value: value}
+}
+
+func (sh *shell) exportVar(name string) {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if v, ok := sh.vars[name]; ok {
+		v.exported = true
+		return
+	}
+	sh.vars[name] = &variable{exported: true}
+}
+
+func (sh *shell) unsetVar(name string) {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	delete(sh.vars, name)
+}
+
+func (sh *shell) setStatus(status int) {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	sh.lastStatus = status
+}
+
+// environ возвращает окружение команды: экспортированные переменные и присваивания
+// перед командой (VAR=x cmd), отсортированные по имени.
+func (sh *shell) environ(assigns []string) []string {
+	sh.mu.Lock()
+	env := make(map[string]string)
+	for name, v := range sh.vars {
+		if v.exported {
+			env[name] = v.value
+		}
+	}
+	sh.mu.Unlock()
+	for _, kv := range assigns {
+		name, value, _ := strings.Cut(kv, "=")
+		env[name] = value
+	}
+
+	result := make([]string, 0, len(env))
+	for name, value := range env {
+		result = append(result, name+"="+value)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// isName сообщает, является ли s допустимым именем переменной.
+func isName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if r != '_' && !isLetter(r) && (i == 0 || r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func isLetter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+// splitAssignments отделяет присваивания NAME=value в начале команды от её слов.
+// Имя должно быть записано без кавычек.
+func splitAssignments(words []word) (assigns, rest []word) {
+	for i, w := range words {
+		if len(w) == 0 || w[0].quote != unquoted {
+			return words[:i], words[i:]
+		}
+		name, _, ok := strings.Cut(w[0].text, "=")
+		if !ok || !isName(name) {
+			return words[:i], words[i:]
+		}
+	}
+	return words, nil
+}
+
+// expander раскрывает слова одной команды. Он запоминает код последней подстановки
+// команды: у присваиваний без команды (v=$(cmd)) он становится кодом завершения.
+// err — первая ошибка подстановки (${...}: bad substitution); после неё остальные
+// подстановки не выполняются, а команда не запускается.
+type expander struct {
+	sh     *shell
+	status int
+	err    error
+}
+
+func (sh *shell) expander() *expander {
+	return &expander{sh: sh}
+}
+
+// expandArgs раскрывает слова команды в аргументы: фигурные скобки, ~, переменные
+// и подстановку команд, затем разбивает результат на поля и раскрывает шаблоны имён файлов.
+func (e *expander) expandArgs(words []word) []string {
+	args := make([]string, 0, len(words))
+	for _, w := range words {
+		for _, bw := range expandBraces(w) {
+			for _, f := range e.expandWord(e.sh.expandTilde(bw)) {
+				if matches := globFiles(f, e.sh.cwd()); len(matches) > 0 {
+					args = append(args, matches...)
+				} else {
+					args = append(args, f.text)
+				}
+			}
+		}
+	}
+	return args
+}
+
+// field — поле после подстановок. pattern — то же поле как шаблон filepath.Glob,
+// где символы из кавычек экранированы; glob означает, что вне кавычек есть *, ? или [.
+type field struct {
+	text    string
+	pattern string
+	glob    bool
+}
+
+// fieldBuilder собирает поля из частей слова.
+type fieldBuilder struct {
+	fields  []field
+	text    strings.Builder
+	pattern strings.Builder
+	glob    bool
+	has     bool
+}
+
+// write добавляет s к текущему полю. В quoted тексте символы шаблонов экранируются.
+func (b *fieldBuilder) write(s string, quoted bool) {
+	b.has = true
+	b.text.WriteString(s)
+	for _, r := range s {
+		switch {
+		case !quoted:
+			b.glob = b.glob || r == '*' || r == '?' || r == '['
+		case strings.ContainsRune(`*?[]\`, r):
+			b.pattern.WriteByte('\\')
+		}
+		b.pattern.WriteRune(r)
+	}
+}
+
+// writeSplit добавляет результат подстановки вне кавычек, разбивая его на поля
+// по пробелам, табуляциям и переводам строк.
+func (b *fieldBuilder) writeSplit(s string) {
+	for {
+		i := strings.IndexAny(s, " \t\n")
+		if i < 0 {
+			if s != "" {
+				b.write(s, false)
+			}
+			return
+		}
+		if i > 0 {
+			b.write(s[:i], false)
+		}
+		b.end()
+		s = s[i+1:]
+	}
+}
+
+func (b *fieldBuilder) end() {
+	if b.has {
+		b.fields = append(b.fields, field{text: b.text.String(), pattern: b.pattern.String(), glob: b.glob})
+	}
+	b.text.Reset()
+	b.pattern.Reset()
+	b.glob, b.has = false, false
+}
+
+// expandWord выполняет подстановки в слове. Результат подстановки вне кавычек
+// разбивается на поля по пробельным символам, поэтому слово может дать несколько
+// аргументов или ни одного ($EMPTY). Подстановки в двойных кавычках не разбиваются.
+func (e *expander) expandWord(w word) []field {
+	var b fieldBuilder
+	for _, part := range w {
+		switch part.quote {
+		case singleQuoted:
+			b.write(part.text, true)
+		case doubleQuoted:
+			if part.text == "$@" || part.text == "${@}" {
+				// "$@" даёт каждый позиционный параметр отдельным полем.
+				for i, param := range e.sh.positional() {
+					if i > 0 {
+						b.end()
+					}
+					b.write(param, true)
+				}
+				continue
+			}
+			b.write(e.expandText(part.text), true)
+		case unquoted:
+			text := part.text
+			for text != "" {
+				i := strings.IndexAny(text, "$`")
+				if i < 0 {
+					b.write(text, false)
+					break
+				}
+				if i > 0 {
+					b.write(text[:i], false)
+				}
+				value, n := e.expandOne(text[i:])
+				text = text[i+n:]
+				b.writeSplit(value)
+			}
+		}
+	}
+	b.end()
+	return b.fields
+}
+
+// expandNoSplit выполняет подстановки в слове без разбиения на поля и шаблонов:
+// так раскрываются имена файлов в перенаправлениях и значения в ${NAME:-word}.
+func (e *expander) expandNoSplit(w word) string {
+	var sb strings.Builder
+	for _, part := range e.sh.expandTilde(w) {
+		if part.quote == singleQuoted {
+			sb.WriteString(part.text)
+		} else {
+			sb.WriteString(e.expandText(part.text))
+		}
+	}
+	return sb.String()
+}
+
+// expandAssignment раскрывает присваивание NAME=value; ~ допускается в начале значения.
+func (e *expander) expandAssignment(w word) string {
+	name, value, _ := strings.Cut(w[0].text, "=")
+	rest := append(word{{text: value}}, w[1:]...)
+	return name + "=" + e.expandNoSplit(rest)
+}
+
+// expandText подставляет переменные и вывод команд в текст (содержимое двойных
+// кавычек, тело here-doc).
+func (e *expander) expandText(text string) string {
+	var sb strings.Builder
+	for {
+		i := strings.IndexAny(text, "$`")
+		if i < 0 {
+			sb.WriteString(text)
+			return sb.String()
+		}
+		sb.WriteString(text[:i])
+		value, n := e.expandOne(text[i:])
+		sb.WriteString(value)
+		text = text[i+n:]
+	}
+}
+
+// expandOne разбирает подстановку в начале text (text[0] — $ или `) и возвращает
+// её значение и длину. $ без имени остаётся как есть.
+func (e *expander) expandOne(text string) (string, int) {
+	if e.err != nil {
+		return "", len(text)
+	}
+	if text[0] == '`' {
+		end := strings.IndexByte(text[1:], '`')
+		for end >= 0 && isEscaped(text[1:], end) {
+			next := strings.IndexByte(text[end+2:], '`')
+			if next < 0 {
+				end = -1
+				break
+			}
+			end += next + 1
+		}
+		if end < 0 {
+			return text, len(text)
+		}
+		return e.commandSubst(unescapeBackquote(text[1 : end+1])), end + 2
+	}
+
+	if len(text) < 2 {
+		return "$", 1
+	}
+	switch c := text[1]; {
+	case c == '{':
+		end := matchingClose(text, '{', '}')
+		if end < 0 {
+			return text, len(text)
+		}
+		return e.expandParam(text[2:end]), end + 1
+	case c == '(':
+		end := matchingClose(text, '(', ')')
+		if end < 0 {
+			return text, len(text)
+		}
+		return e.commandSubst(text[2:end]), end + 1
+	case strings.IndexByte("?$!#@*", c) >= 0 || c >= '0' && c <= '9':
+		return e.sh.special(c), 2
+	case c == '_' || isLetter(rune(c)):
+		n := 2
+		for n < len(text) && (text[n] == '_' || isLetter(rune(text[n])) || (text[n] >= '0' && text[n] <= '9')) {
+			n++
+		}
+		value, _ := e.sh.getVar(text[1:n])
+		return value, n
+	}
+	return "$", 1
+}
+
+// isEscaped сообщает, экранирован ли символ s[i] нечётным числом обратных косых черт.
+func isEscaped(s string, i int) bool {
+	n := 0
+	for i > 0 && s[i-1] == '\\' {
+		n++
+		i--
+	}
+	return n%2 == 1
+}
+
+// unescapeBackquote снимает экранирование \$, \` и \\ внутри `...`.
+func unescapeBackquote(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && strings.IndexByte("$`\\", s[i+1]) >= 0 {
+			i++
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
+// commandSubst выполняет script в копии шелла (см. subshell) и возвращает его
+// вывод без завершающих переводов строк: cd, присваивания и функции внутри $(...)
+// не меняют сам шелл. Вывод читается через канал, поэтому внешние команды пишут
+// в него напрямую.
+func (e *expander) commandSubst(script string) string {
+	tree, err := e.sh.parse(script)
+	if err != nil {
+		fmt.Fprintln(e.sh.stderr, "Error:", err)
+		e.status = 2
+		return ""
+	}
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		fmt.Fprintln(e.sh.stderr, "Error creating pipe:", err)
+		e.status = 1
+		return ""
+	}
+	output := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(reader)
+		reader.Close()
+		output <- data
+	}()
+
+	sub := e.sh.subshell()
+	e.status = sub.runList(tree, streams{stdin: sub.stdin, stdout: writer, stderr: sub.stderr, ctl: &control{}})
+	writer.Close()
+	return strings.TrimRight(string(<-output), "\n")
+}
+
+// expandTilde заменяет ~ и ~user в начале слова домашним каталогом.
+// Результат считается взятым в кавычки: он не разбивается и не раскрывается как шаблон.
+func (sh *shell) expandTilde(w word) word {
+	if len(w) == 0 || w[0].quote != unquoted || !strings.HasPrefix(w[0].text, "~") {
+		return w
+	}
+	text := w[0].text
+	end := strings.IndexByte(text, '/')
+	if end < 0 {
+		if len(w) > 1 {
+			// ~"user" — имя в кавычках, подстановки нет.
+			return w
+		}
+		end = len(text)
+	}
+
+	var home string
+	if name := text[1:end]; name == "" {
+		var ok bool
+		if home, ok = sh.getVar("HOME"); !ok {
+			return w
+		}
+	} else {
+		u, err := user.Lookup(name)
+		if err != nil {
+			return w
+		}
+		home = u.HomeDir
+	}
+
+	result := word{{text: home, quote: singleQuoted}}
+	if end < len(text) {
+		result = append(result, wordPart{text: text[end:]})
+	}
+	return append(result, w[1:]...)
+}
+
+// globFiles раскрывает поле-шаблон в список файлов по правилам filepath.Glob.
+// Относительный шаблон раскрывается в каталоге dir, а имена остаются относительными
+// и начинаются так же, как шаблон (./*.go даёт ./a.go).
+// Как и в sh, * и ? не совпадают с начальной точкой в имени, если точка не указана явно.
+// Если совпадений нет, возвращается nil и шаблон остаётся аргументом как есть.
+func globFiles(f field, dir string) []string {
+	if !f.glob {
+		return nil
+	}
+	pattern := f.pattern
+	// prefix — каталоги в начале шаблона без метасимволов, base — они же от dir.
+	var prefix, base string
+	if !filepath.IsAbs(pattern) {
+		literal := pattern
+		if i := strings.IndexAny(pattern, "*?[\\"); i >= 0 {
+			literal = pattern[:i]
+		}
+		prefix = pattern[:strings.LastIndexByte(literal, '/')+1]
+		base = filepath.Join(dir, prefix)
+		pattern = filepath.Join(escapeGlob(base), pattern[len(prefix):])
+	}
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil
+	}
+	if base != "" {
+		for i, match := range matches {
+			rel, _ := filepath.Rel(base, match)
+			matches[i] = prefix + rel
+		}
+	}
+	patternParts := strings.Split(f.pattern, "/")
+	visible := matches[:0]
+	for _, match := range matches {
+		hidden := false
+		for i, part := range strings.Split(match, "/") {
+			if i < len(patternParts) && strings.HasPrefix(part, ".") && !strings.HasPrefix(patternParts[i], ".") &&
+				strings.ContainsAny(patternParts[i], "*?[") {
+				hidden = true
+				break
+			}
+		}
+		if !hidden {
+			visible = append(visible, match)
+		}
+	}
+	return visible
+}
+
+// escapeGlob экранирует метасимволы шаблона в пути, чтобы он совпадал только сам с собой.
+func escapeGlob(path string) string {
+	var sb strings.Builder
+	for _, r := range path {
+		if strings.ContainsRune("*?[\\", r) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// special возвращает значение специальных параметров $?, $$, $!, $#, $@, $*
+// и позиционных параметров $0–$9.
+func (sh *shell) special(c byte) string {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	switch c {
+	case '0':
+		return sh.name
+	case '#':
+		return strconv.Itoa(len(sh.params))
+	case '@', '*':
+		return strings.Join(sh.params, " ")
+	}
+	if c >= '1' && c <= '9' {
+		return sh.param(int(c - '0'))
+	}
+	switch c {
+	case '?':
+		return strconv.Itoa(sh.lastStatus)
+	case '$':
+		return strconv.Itoa(os.Getpid())
+	case '!':
+		if sh.lastBackground != 0 {
+			return strconv.Itoa(sh.lastBackground)
+		}
+	}
+	return ""
+}
+
+// param возвращает позиционный параметр $n или "", если его нет. Вызывается под sh.mu.
+func (sh *shell) param(n int) string {
+	if n <= len(sh.params) {
+		return sh.params[n-1]
+	}
+	return ""
+}
+
+// positionalParam возвращает параметр ${N} и признак того, что он задан.
+func (sh *shell) positionalParam(digits string) (string, bool) {
+	n, _ := strconv.Atoi(digits)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if n == 0 {
+		return sh.name, true
+	}
+	return sh.param(n), n <= len(sh.params)
+}
+
+// matchingClose возвращает индекс символа close, закрывающего ${ или $( в начале text, или -1.
+func matchingClose(text string, open, close byte) int {
+	depth := 0
+	var quote byte
+	for i := 1; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else if c == '\\' && quote == '"' {
+				i++
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '\\':
+			i++
+		case c == open:
+			depth++
+		case c == close:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// expandParam вычисляет ${expr}: ${NAME}, ${NAME:-word}, ${NAME:=word}, ${NAME:+word}
+// и те же формы без двоеточия, где проверяется только, задана ли переменная.
+func (e *expander) expandParam(expr string) string {
+	if len(expr) == 1 && strings.ContainsRune("?$!#@*", rune(expr[0])) {
+		return e.sh.special(expr[0])
+	}
+
+	name := expr
+	digits := expr != "" && expr[0] >= '0' && expr[0] <= '9'
+	for i, r := range expr {
+		isDigit := r >= '0' && r <= '9'
+		if digits && !isDigit || !digits && r != '_' && !isLetter(r) && (i == 0 || !isDigit) {
+			name = expr[:i]
+			break
+		}
+	}
+	if name == "" {
+		return e.badSubstitution(expr)
+	}
+	value, set := e.sh.getVar(name)
+	if digits {
+		value, set = e.sh.positionalParam(name)
+	}
+	rest := expr[len(name):]
+	if rest == "" {
+		return value
+	}
+
+	checkEmpty := strings.HasPrefix(rest, ":")
+	rest = strings.TrimPrefix(rest, ":")
+	if rest == "" {
+		return e.badSubstitution(expr)
+	}
+	op, arg := rest[0], rest[1:]
+	present := set && (!checkEmpty || value != "")
+	switch op {
+	case '-':
+		if !present {
+			return e.expandNoSplit(paramWord(arg))
+		}
+	case '=':
+		if !present {
+			value = e.expandNoSplit(paramWord(arg))
+			if e.err != nil {
+				return ""
+			}
+			e.sh.setVar(name, value)
+		}
+	case '+':
+		if present {
+			return e.expandNoSplit(paramWord(arg))
+		}
+		return ""
+	default:
+		return e.badSubstitution(expr)
+	}
+	return value
+}
+
+// badSubstitution запоминает ошибку в ${expr} и возвращает пустое значение.
+func (e *expander) badSubstitution(expr string) string {
+	if e.err == nil {
+		e.err = fmt.Errorf("${%s}: bad substitution", expr)
+	}
+	return ""
+}
+
+// paramWord разбирает слово внутри ${NAME:-word}: кавычки в нём снимаются,
+// а пробелы, в отличие от командной строки, не разделяют слова.
+func paramWord(text string) word {
+	lx := &lexer{src: []rune(text)}
+	var w word
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			w = append(w, wordPart{text: current.String()})
+			current.Reset()
+		}
+	}
+	for !lx.eof() {
+		switch r := lx.peek(0); r {
+		case '\'':
+			flush()
+			part, err := lx.scanSingleQuoted()
+			if err != nil {
+				return append(w, wordPart{text: text, quote: singleQuoted})
+			}
+			w = append(w, part)
+		case '"':
+			flush()
+			parts, err := lx.scanDoubleQuoted()
+			if err != nil {
+				return append(w, wordPart{text: text, quote: singleQuoted})
+			}
+			w = append(w, parts...)
+		case '\\':
+			flush()
+			lx.pos++
+			if !lx.eof() {
+				w = append(w, wordPart{text: string(lx.peek(0)), quote: singleQuoted})
+				lx.pos++
+			}
+		case '$', '`':
+			if err := lx.scanDollar(&current); err != nil {
+				current.WriteString(string(lx.src[lx.pos:]))
+				lx.pos = len(lx.src)
+			}
+		default:
+			current.WriteRune(r)
+			lx.pos++
+		}
+	}
+	flush()
+	return w
+}
@@ -0,0 +1,141 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestExpandWords(t *testing.T) {
+	sh := newShell()
+	sh.initVars([]string{"HOME=/home/user", "X=a  b", "EMPTY="})
+	sh.setStatus(3)
+
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{`echo $HOME "$HOME" '$HOME' \$HOME`, []string{"echo", "/home/user", "/home/user", "$HOME", "$HOME"}},
+		{`echo $X "$X"`, []string{"echo", "a", "b", "a  b"}},
+		{`echo pre$X-post`, []string{"echo", "prea", "b-post"}},
+		{`echo $EMPTY $UNSET x`, []string{"echo", "x"}},
+		{`echo "$EMPTY" ''`, []string{"echo", "", ""}},
+		{`echo ${HOME}dir $HOME_dir`, []string{"echo", "/home/userdir"}},
+		{`echo ${UNSET:-de fault} ${EMPTY:-e} ${EMPTY-e}`, []string{"echo", "de", "fault", "e"}},
+		{`echo "${UNSET:-"q q"}" ${HOME:+set} ${UNSET:+set}`, []string{"echo", "q q", "set"}},
+		{`echo $? "$?" $ a$`, []string{"echo", "3", "3", "$", "a$"}},
+		{`echo $$`, []string{"echo", strconv.Itoa(os.Getpid())}},
+	}
+
+	for _, test := range tests {
+		tree, err := parse(test.input)
+		if err != nil {
+			t.Fatalf("Не ожидалась ошибка для ввода %s: %v", test.input, err)
+		}
+		args := sh.expander().expandArgs(tree.items[0].pipelines[0].commands[0].(*simpleCommand).args)
+		if len(args) == 0 {
+			args = nil
+		}
+		if !reflect.DeepEqual(args, test.expected) {
+			t.Errorf("Для ввода %s ожидалось %q, но получено %q", test.input, test.expected, args)
+		}
+	}
+
+	if got := sh.expander().expandParam("NEW:=value"); got != "value" {
+		t.Errorf("Ожидалось value, но получено %q", got)
+	}
+	if value, _ := sh.getVar("NEW"); value != "value" {
+		t.Errorf("${NEW:=value} должен присвоить переменную, но получено %q", value)
+	}
+}
+
+func TestAssignments(t *testing.T) {
+	sh := newShell()
+	sh.initVars([]string{"PATH=/usr/bin:/bin"})
+	run := func(input string) {
+		t.Helper()
+		tree, err := parse(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sh.runList(tree, sh.stdStreams())
+	}
+
+	run(`A=1 B="two words"`)
+	if value, _ := sh.getVar("B"); value != "two words" {
+		t.Errorf("Ожидалось %q, но получено %q", "two words", value)
+	}
+	if env := sh.environ(nil); !reflect.DeepEqual(env, []string{"PATH=/usr/bin:/bin"}) {
+		t.Errorf("Неэкспортированные переменные не должны попадать в окружение: %q", env)
+	}
+	if env := sh.environ([]string{"C=3"}); !reflect.DeepEqual(env, []string{"C=3", "PATH=/usr/bin:/bin"}) {
+		t.Errorf("Присваивание перед командой должно попадать в окружение: %q", env)
+	}
+
+	run(`export A; unset B`)
+	if env := sh.environ(nil); !reflect.DeepEqual(env, []string{"A=1", "PATH=/usr/bin:/bin"}) {
+		t.Errorf("Ожидалось окружение с A, но получено %q", env)
+	}
+	if _, ok := sh.getVar("B"); ok {
+		t.Error("Переменная B должна быть удалена")
+	}
+
+	assigns, rest := splitAssignments(tokenWords(t, `X=1 'Y=2' cmd Z=3`))
+	if len(assigns) != 1 || len(rest) != 3 {
+		t.Errorf("Ожидалось одно присваивание и три слова, но получено %d и %d", len(assigns), len(rest))
+	}
+}
+
+func tokenWords(t *testing.T, input string) []word {
+	t.Helper()
+	tokens, err := tokenize(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var words []word
+	for _, tok := range tokens {
+		if tok.kind == tokWord {
+			words = append(words, tok.word)
+		}
+	}
+	return words
+}
+
+func TestExpandBracesTildeGlob(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.go", "b.go", ".hidden.go", "c.txt", "*.go"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sh := newShell()
+	sh.initVars([]string{"HOME=/home/user", "D=" + dir, "PATH=" + os.Getenv("PATH")})
+
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{`echo {a,b}x pre{1..3} {c..a}`, []string{"echo", "ax", "bx", "pre1", "pre2", "pre3", "c", "b", "a"}},
+		{`echo a{b,{c,d}}e {x} {} "{a,b}" \{a,b}`, []string{"echo", "abe", "ace", "ade", "{x}", "{}", "{a,b}", "{a,b}"}},
+		{`echo ${UNSET:-a,b} {$D,y}`, []string{"echo", "a,b", dir, "y"}},
+		{`echo ~ ~/bin "~" \~ a~`, []string{"echo", "/home/user", "/home/user/bin", "~", "~", "a~"}},
+		{`echo $D/*.go`, []string{"echo", dir + "/*.go", dir + "/a.go", dir + "/b.go"}},
+		{`echo "$D"/?.txt $D/.*.go $D/*.none`, []string{"echo", dir + "/c.txt", dir + "/.hidden.go", dir + "/*.none"}},
+		{`echo "$D/*.go" $D/\*.go`, []string{"echo", dir + "/*.go", dir + "/*.go"}},
+		{`echo $(echo a  b) "$(echo a; echo b)" x$(printf '')y`, []string{"echo", "a", "b", "a\nb", "xy"}},
+		{"echo `echo back` \"`echo \\`echo nested\\``\"", []string{"echo", "back", "nested"}},
+	}
+
+	for _, test := range tests {
+		tree, err := parse(test.input)
+		if err != nil {
+			t.Fatalf("Не ожидалась ошибка для ввода %s: %v", test.input, err)
+		}
+		args := sh.expander().expandArgs(tree.items[0].pipelines[0].commands[0].(*simpleCommand).args)
+		if !reflect.DeepEqual(args, test.expected) {
+			t.Errorf("Для ввода %s ожидалось %q, но получено %q", test.input, test.expected, args)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package shell
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// BuiltinFunc — встроенная команда, добавленная в Interpreter. ctx отменяется вместе
+// с контекстом Run, stdio — потоки команды после перенаправлений, args[0] — имя команды.
+// Возвращает код завершения.
+type BuiltinFunc func(ctx context.Context, stdio Stdio, args []string) int
+
+// Interpreter выполняет скрипты l2sh внутри другой программы. Переменные, функции,
+// алиасы и текущий каталог сохраняются между вызовами Run. cd меняет каталог
+// интерпретатора, а не процесса, поэтому в одном процессе могут работать несколько
+// интерпретаторов. Управление заданиями в интерпретаторе выключено.
+//
+// Нулевое значение готово к работе. Run нельзя вызывать одновременно из нескольких горутин.
+type Interpreter struct {
+	// Потоки скрипта. nil у Stdin — пустой ввод, у Stdout и Stderr — вывод отбрасывается.
+	// Команды конвейера пишут в Stderr одновременно, поэтому запись в него должна быть
+	// безопасна для нескольких горутин, если скрипт запускает конвейеры или фоновые задания.
+	// Потоки читаются при каждом вызове Run.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	// Dir — начальный каталог; пустая строка — текущий каталог процесса.
+	Dir string
+	// Env — начальное окружение в виде NAME=value; nil — окружение процесса.
+	Env []string
+
+	sh *shell
+}
+
+// shell возвращает состояние интерпретатора, создавая его при первом обращении.
+// Dir и Env применяются только здесь: дальше каталог и переменные меняет сам скрипт.
+func (in *Interpreter) shell() *shell {
+	if in.sh != nil {
+		return in.sh
+	}
+	sh := newShell()
+	sh.builtins = make(map[string]Builtin)
+	if in.Env != nil {
+		sh.initVars(in.Env)
+	}
+	if in.Dir != "" {
+		sh.dir = in.Dir
+		if abs, err := filepath.Abs(in.Dir); err == nil {
+			sh.dir = abs
+		}
+		sh.setVar("PWD", sh.dir)
+	}
+	in.sh = sh
+	return sh
+}
+
+// RegisterBuiltin добавляет встроенную команду name, выполняемую функцией fn.
+func (in *Interpreter) RegisterBuiltin(name string, fn BuiltinFunc) {
+	in.Register(funcBuiltin{name: name, fn: fn})
+}
+
+// Register добавляет встроенную команду только в этот интерпретатор. Она заменяет
+// стандартную встроенную команду с тем же именем, но не функцию шелла.
+func (in *Interpreter) Register(b Builtin) {
+	in.shell().builtins[b.Name()] = b
+}
+
+// Run выполняет script и возвращает код завершения: код exit или последней команды.
+// Синтаксическая ошибка возвращается с кодом 2, и скрипт не выполняется.
+// После отмены ctx новые команды не запускаются, процессы переднего плана
+// завершаются сигналом SIGKILL, а Run возвращает ctx.Err() вместе с кодом
+// прерванной команды.
+func (in *Interpreter) Run(ctx context.Context, script string) (int, error) {
+	sh := in.shell()
+	sh.stdin, sh.stdout, sh.stderr = in.Stdin, in.Stdout, in.Stderr
+	if sh.stdin == nil {
+		sh.stdin = strings.NewReader("")
+	}
+	if sh.stdout == nil {
+		sh.stdout = io.Discard
+	}
+	if sh.stderr == nil {
+		sh.stderr = io.Discard
+	}
+
+	tree, err := sh.parse(script)
+	if err != nil {
+		return 2, scriptError(script, err)
+	}
+
+	sh.mu.Lock()
+	sh.ctx = ctx
+	sh.mu.Unlock()
+	defer func() {
+		sh.mu.Lock()
+		sh.ctx = context.Background()
+		sh.mu.Unlock()
+	}()
+
+	base := sh.stdStreams()
+	status := sh.runList(tree, base)
+	if base.ctl.kind == flowExit {
+		status = base.ctl.status
+	}
+	return status, ctx.Err()
+}
+
+// Var возвращает значение переменной шелла name.
+func (in *Interpreter) Var(name string) (string, bool) {
+	return in.shell().getVar(name)
+}
@@ -0,0 +1,44 @@
+package shell_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"L2/L2.9/shell"
+)
+
+// upper — встроенная команда, написанная вне пакета shell: она использует только
+// экспортированные типы и поэтому компилируется и за пределами модуля.
+type upper struct{}
+
+func (upper) Name() string  { return "upper" }
+func (upper) Usage() string { return "upper" }
+
+func (upper) Run(ctx context.Context, io shell.IO, args []string) int {
+	var in bytes.Buffer
+	in.ReadFrom(io.In)
+	fmt.Fprint(io.Out, strings.ToUpper(in.String()))
+	return 0
+}
+
+func TestInterpreterExternalBuiltins(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	in := &shell.Interpreter{Stdout: &stdout, Stderr: &stderr}
+	in.Register(upper{})
+	in.RegisterBuiltin("greet", func(ctx context.Context, stdio shell.Stdio, args []string) int {
+		fmt.Fprintln(stdio.Out, "hello", strings.Join(args[1:], " "))
+		return 3
+	})
+
+	code, err := in.Run(context.Background(), "greet world | upper; greet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != 3 || stdout.String() != "HELLO WORLD\nhello \n" {
+		t.Errorf("Ожидался код 3 и вывод %q, но получен код %d и вывод %q (stderr: %q)",
+			"HELLO WORLD\nhello \n", code, stdout.String(), stderr.String())
+	}
+}
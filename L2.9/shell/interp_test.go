@@ -0,0 +1,106 @@
+package shell
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInterpreterRun(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	in := &Interpreter{
+		Stdin:  strings.NewReader("from stdin\n"),
+		Stdout: &stdout,
+		Stderr: &stderr,
+		Dir:    dir,
+		Env:    []string{"PATH=/usr/bin:/bin", "GREETING=hi"},
+	}
+	in.RegisterBuiltin("greet", func(ctx context.Context, stdio Stdio, args []string) int {
+		fmt.Fprintf(stdio.Out, "hello, %s\n", strings.Join(args[1:], " "))
+		return len(args) - 1
+	})
+
+	tests := []struct {
+		script   string
+		status   int
+		expected string
+	}{
+		{"echo $GREETING; echo $HOME", 0, "hi\n\n"},
+		{"read line; echo \"$line\"", 0, "from stdin\n"},
+		{"cd sub && pwd && echo data > file && cat file && /bin/pwd", 0, filepath.Join(dir, "sub") + "\ndata\n" + filepath.Join(dir, "sub") + "\n"},
+		{"pwd; ls; [ -f file ] && echo exists", 0, filepath.Join(dir, "sub") + "\nfile\nexists\n"},
+		{"cd ..; echo *", 0, "sub\n"},
+		{"greet world | tr a-z A-Z", 0, "HELLO, WORLD\n"},
+		{"greet a b", 2, "hello, a b\n"},
+		{"f() { return 3; }; f", 3, ""},
+		{"echo before; exit 4; echo after", 4, "before\n"},
+		{"echo $?", 0, "4\n"},
+	}
+	for _, test := range tests {
+		stdout.Reset()
+		status, err := in.Run(context.Background(), test.script)
+		if err != nil {
+			t.Errorf("Для скрипта %q получена ошибка %v", test.script, err)
+		}
+		if status != test.status {
+			t.Errorf("Для скрипта %q ожидался код %d, но получен %d", test.script, test.status, status)
+		}
+		if stdout.String() != test.expected {
+			t.Errorf("Для скрипта %q ожидалось %q, но получено %q", test.script, test.expected, stdout.String())
+		}
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("Ожидался пустой stderr, но получено %q", stderr.String())
+	}
+	if wd, _ := os.Getwd(); wd == dir || wd == filepath.Join(dir, "sub") {
+		t.Error("cd в интерпретаторе не должен менять каталог процесса")
+	}
+	if value, _ := in.Var("PWD"); value != dir {
+		t.Errorf("Ожидалось PWD=%q, но получено %q", dir, value)
+	}
+
+	status, err := in.Run(context.Background(), "echo 'unterminated")
+	if status != 2 || err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("Для синтаксической ошибки ожидался код 2 и ошибка со строкой, но получены %d и %v", status, err)
+	}
+}
+
+func TestInterpreterCancel(t *testing.T) {
+	for _, script := range []string{
+		"sleep 10; echo after",
+		"sleep 10 | cat; echo after",
+		"while true; do :; done; echo after",
+		"for i in 1 2 3; do sleep 10; done; echo after",
+	} {
+		var stdout bytes.Buffer
+		in := &Interpreter{Stdout: &stdout}
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		start := time.Now()
+		status, err := in.Run(ctx, script)
+		cancel()
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Для скрипта %q ожидалась ошибка DeadlineExceeded, но получено %v (код %d)", script, err, status)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("Скрипт %q не прервался после отмены: %v", script, elapsed)
+		}
+		if stdout.Len() != 0 {
+			t.Errorf("Для скрипта %q после отмены ожидался пустой вывод, но получено %q", script, stdout.String())
+		}
+
+		// После отмены интерпретатор продолжает работать с новым контекстом.
+		if status, err := in.Run(context.Background(), "echo ok"); status != 0 || err != nil || stdout.String() != "ok\n" {
+			t.Errorf("После отмены ожидался вывод %q, но получено %q (код %d, ошибка %v)", "ok\n", stdout.String(), status, err)
+		}
+	}
+}
@@ -0,0 +1,413 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// process — стадия задания: внешний процесс (pid > 0) или встроенная команда (pid == 0).
+type process struct {
+	pid     int
+	stopped bool
+	exited  bool
+	status  int
+}
+
+// job — задание: конвейер переднего плана или список, запущенный через &.
+// Все процессы одного конвейера входят в одну группу, чтобы сигналы терминала
+// и kill доставались им вместе.
+type job struct {
+	id   int
+	text string
+
+	mu         sync.Mutex
+	changed    *sync.Cond
+	pgid       int // группа текущего конвейера задания; 0 — ещё не запущен внешний процесс
+	procs      []*process
+	foreground bool
+	started    chan struct{}
+	startOnce  sync.Once
+	done       bool
+	status     int
+}
+
+func newJob(text string, foreground bool) *job {
+	j := &job{text: text, foreground: foreground, started: make(chan struct{})}
+	j.changed = sync.NewCond(&j.mu)
+	return j
+}
+
+func (j *job) addProcess(pid int) *process {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	p := &process{pid: pid}
+	j.procs = append(j.procs, p)
+	return p
+}
+
+// update меняет состояние процесса и будит ожидающих.
+func (j *job) update(fn func()) {
+	j.mu.Lock()
+	fn()
+	j.mu.Unlock()
+	j.changed.Broadcast()
+}
+
+func (j *job) exit(p *process, status int) {
+	j.update(func() { p.exited, p.stopped, p.status = true, false, status })
+}
+
+// finish отмечает, что задание выполнило все команды.
+func (j *job) finish(status int) {
+	j.markStarted()
+	j.update(func() { j.done, j.status = true, status })
+}
+
+// markStarted сообщает, что стадии первого конвейера запущены.
+func (j *job) markStarted() {
+	j.startOnce.Do(func() { close(j.started) })
+}
+
+func (j *job) pgroup() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.pgid
+}
+
+func (j *job) setForeground(fg bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.foreground = fg
+}
+
+// stoppedLocked сообщает, остановлен ли хотя бы один процесс задания.
+func (j *job) stoppedLocked() bool {
+	for _, p := range j.procs {
+		if p.stopped && !p.exited {
+			return true
+		}
+	}
+	return false
+}
+
+// waitProcesses ждёт завершения процессов procs и возвращает код последнего,
+// а с pipefail — последний ненулевой код.
+func (j *job) waitProcesses(procs []*process, pipefail bool) int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, p := range procs {
+		for !p.exited {
+			j.changed.Wait()
+		}
+	}
+	if len(procs) == 0 {
+		return 0
+	}
+	if pipefail {
+		for i := len(procs) - 1; i >= 0; i-- {
+			if procs[i].status != 0 {
+				return procs[i].status
+			}
+		}
+	}
+	return procs[len(procs)-1].status
+}
+
+// killProcesses посылает SIGKILL ещё работающим внешним процессам procs.
+func (j *job) killProcesses(procs []*process) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, p := range procs {
+		if p.pid > 0 && !p.exited {
+			syscall.Kill(p.pid, syscall.SIGKILL)
+		}
+	}
+}
+
+// waitStopOrDone ждёт, пока задание завершится или будет остановлено.
+func (j *job) waitStopOrDone() (stopped bool, status int) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for !j.done && !j.stoppedLocked() {
+		j.changed.Wait()
+	}
+	return !j.done, j.status
+}
+
+// waitDone ждёт завершения задания.
+func (j *job) waitDone() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for !j.done {
+		j.changed.Wait()
+	}
+	return j.status
+}
+
+// state описывает задание для jobs: Running, Stopped, Done или Exit N.
+func (j *job) state() string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	switch {
+	case j.done && j.status == 0:
+		return "Done"
+	case j.done:
+		return fmt.Sprintf("Exit %d", j.status)
+	case j.stoppedLocked():
+		return "Stopped"
+	}
+	return "Running"
+}
+
+func (j *job) isDone() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.done
+}
+
+func (j *job) hasPid(pid int) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, p := range j.procs {
+		if p.pid == pid {
+			return true
+		}
+	}
+	return j.pgid == pid
+}
+
+// watchProcess следит за внешним процессом p задания j до его завершения,
+// отмечая остановки (Ctrl+Z, SIGSTOP) и продолжения (SIGCONT). finish получает
+// состояние завершившегося процесса и может заменить его код завершения.
+func (j *job) watchProcess(p *process, cmd *exec.Cmd, finish func(*os.ProcessState, int) int) {
+	for {
+		stopped, continued, err := waitStopOrExit(p.pid)
+		if err != nil || (!stopped && !continued) {
+			break
+		}
+		j.update(func() { p.stopped = stopped })
+	}
+	status := exitStatus(cmd.Wait())
+	j.exit(p, finish(cmd.ProcessState, status))
+}
+
+// continueJob посылает SIGCONT группе задания.
+func continueJob(j *job) error {
+	pgid := j.pgroup()
+	if pgid == 0 {
+		return nil
+	}
+	return syscall.Kill(-pgid, syscall.SIGCONT)
+}
+
+// addJob помещает задание в таблицу заданий; оно становится текущим (+).
+func (sh *shell) addJob(j *job) {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	for i, other := range sh.jobs {
+		if other == j {
+			sh.jobs = append(sh.jobs[:i], sh.jobs[i+1:]...)
+			break
+		}
+	}
+	if j.id == 0 {
+		for _, other := range sh.jobs {
+			j.id = max(j.id, other.id)
+		}
+		j.id++
+	}
+	sh.jobs = append(sh.jobs, j)
+}
+
+func (sh *shell) removeJob(j *job) {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	for i, other := range sh.jobs {
+		if other == j {
+			sh.jobs = append(sh.jobs[:i], sh.jobs[i+1:]...)
+			return
+		}
+	}
+}
+
+// jobList возвращает задания по возрастанию номера и метки: + у текущего, - у предыдущего.
+func (sh *shell) jobList() ([]*job, map[*job]byte) {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	marks := make(map[*job]byte)
+	if n := len(sh.jobs); n > 0 {
+		marks[sh.jobs[n-1]] = '+'
+		if n > 1 {
+			marks[sh.jobs[n-2]] = '-'
+		}
+	}
+	list := append([]*job(nil), sh.jobs...)
+	sort.Slice(list, func(a, b int) bool { return list[a].id < list[b].id })
+	return list, marks
+}
+
+func formatJob(j *job, mark byte) string {
+	if mark == 0 {
+		mark = ' '
+	}
+	return fmt.Sprintf("[%d]%c  %-24s%s", j.id, mark, j.state(), j.text)
+}
+
+// reportJobs печатает завершившиеся фоновые задания и убирает их из таблицы.
+// Вызывается перед каждым приглашением.
+func (sh *shell) reportJobs(s streams) {
+	list, marks := sh.jobList()
+	for _, j := range list {
+		if j.isDone() {
+			fmt.Fprintln(s.stderr, formatJob(j, marks[j]))
+			sh.removeJob(j)
+		}
+	}
+}
+
+// findJob находит задание по спецификации: %N, %+ или %%, %-, %prefix, N или PID.
+func (sh *shell) findJob(spec string) (*job, error) {
+	list, marks := sh.jobList()
+	byMark := func(mark byte) (*job, error) {
+		for j, m := range marks {
+			if m == mark {
+				return j, nil
+			}
+		}
+		if mark == '+' {
+			return nil, fmt.Errorf("current: no such job")
+		}
+		return nil, fmt.Errorf("%s: no such job", spec)
+	}
+
+	switch {
+	case spec == "" || spec == "%" || spec == "%+" || spec == "%%":
+		return byMark('+')
+	case spec == "%-":
+		return byMark('-')
+	case strings.HasPrefix(spec, "%"):
+		if id, err := strconv.Atoi(spec[1:]); err == nil {
+			for _, j := range list {
+				if j.id == id {
+					return j, nil
+				}
+			}
+			return nil, fmt.Errorf("%s: no such job", spec)
+		}
+		var found *job
+		for _, j := range list {
+			if strings.HasPrefix(j.text, spec[1:]) {
+				if found != nil {
+					return nil, fmt.Errorf("%s: ambiguous job spec", spec)
+				}
+				found = j
+			}
+		}
+		if found == nil {
+			return nil, fmt.Errorf("%s: no such job", spec)
+		}
+		return found, nil
+	}
+
+	n, err := strconv.Atoi(spec)
+	if err != nil {
+		return nil, fmt.Errorf("%s: no such job", spec)
+	}
+	for _, j := range list {
+		if j.id == n || j.hasPid(n) {
+			return j, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: no such job", spec)
+}
+
+func builtinJobs(sh *shell, args []string, s streams) int {
+	list, marks := sh.jobList()
+	for _, j := range list {
+		fmt.Fprintln(s.stdout, formatJob(j, marks[j]))
+		if j.isDone() {
+			sh.removeJob(j)
+		}
+	}
+	return 0
+}
+
+// builtinFg возвращает задание на передний план: передаёт ему терминал,
+// продолжает его и ждёт, как обычную команду.
+func builtinFg(sh *shell, args []string, s streams) int {
+	j, err := sh.findJob(strings.Join(args[1:], " "))
+	if err != nil {
+		fmt.Fprintln(s.stderr, "fg:", err)
+		return 1
+	}
+	fmt.Fprintln(s.stdout, j.text)
+	j.setForeground(true)
+	if sh.interactive {
+		if pgid := j.pgroup(); pgid != 0 {
+			tcsetpgrp(0, pgid)
+		}
+	}
+	if err := continueJob(j); err != nil {
+		fmt.Fprintln(s.stderr, "fg:", err)
+	}
+	return sh.waitForeground(j)
+}
+
+// builtinBg продолжает остановленные задания в фоне.
+func builtinBg(sh *shell, args []string, s streams) int {
+	specs := args[1:]
+	if len(specs) == 0 {
+		specs = []string{""}
+	}
+	status := 0
+	for _, spec := range specs {
+		j, err := sh.findJob(spec)
+		if err != nil {
+			fmt.Fprintln(s.stderr, "bg:", err)
+			status = 1
+			continue
+		}
+		j.setForeground(false)
+		if err := continueJob(j); err != nil {
+			fmt.Fprintln(s.stderr, "bg:", err)
+			status = 1
+			continue
+		}
+		fmt.Fprintf(s.stdout, "[%d] %s &\n", j.id, j.text)
+	}
+	return status
+}
+
+// builtinWait ждёт завершения указанных заданий и возвращает код последнего.
+// Без аргументов ждёт все фоновые задания и, как в POSIX, возвращает 0.
+// Дождавшиеся задания удаляются из таблицы заданий.
+func builtinWait(sh *shell, args []string, s streams) int {
+	var targets []*job
+	if len(args) < 2 {
+		targets, _ = sh.jobList()
+	}
+	status := 0
+	for _, spec := range args[1:] {
+		j, err := sh.findJob(spec)
+		if err != nil {
+			fmt.Fprintln(s.stderr, "wait:", err)
+			status = 127
+			continue
+		}
+		targets = append(targets, j)
+	}
+	for _, j := range targets {
+		code := j.waitDone()
+		sh.removeJob(j)
+		if len(args) > 1 {
+			status = code
+		}
+	}
+	return status
+}
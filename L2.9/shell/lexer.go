@@ -0,0 +1,506 @@
+package shell
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokEOF      tokenKind = iota
+	tokWord               // слово, возможно состоящее из частей в разных кавычках
+	tokPipe               // |
+	tokAndIf              // &&
+	tokOrIf               // ||
+	tokSemi               // ;
+	tokAmp                // &
+	tokNewline            // перевод строки
+	tokRedirect           // <, >, >>, <&, >&, << с необязательным номером дескриптора
+	tokLParen             // (
+	tokRParen             // )
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokEOF:
+		return "end of input"
+	case tokWord:
+		return "word"
+	case tokPipe:
+		return "|"
+	case tokAndIf:
+		return "&&"
+	case tokOrIf:
+		return "||"
+	case tokSemi:
+		return ";"
+	case tokAmp:
+		return "&"
+	case tokNewline:
+		return "newline"
+	case tokRedirect:
+		return "redirection"
+	case tokLParen:
+		return "("
+	case tokRParen:
+		return ")"
+	}
+	return "unknown"
+}
+
+// quoteKind описывает, как часть слова была записана во входной строке.
+// От этого зависит, какие подстановки к ней применяются.
+type quoteKind int
+
+const (
+	unquoted     quoteKind = iota
+	singleQuoted           // '...' или символ, экранированный обратной косой чертой
+	doubleQuoted           // "..."
+)
+
+// wordPart — непрерывный фрагмент слова с одним видом кавычек.
+type wordPart struct {
+	text  string
+	quote quoteKind
+}
+
+// word — слово командной строки. "a"'b'c — одно слово из трёх частей.
+type word []wordPart
+
+// literal возвращает текст слова после удаления кавычек.
+func (w word) literal() string {
+	var sb strings.Builder
+	for _, part := range w {
+		sb.WriteString(part.text)
+	}
+	return sb.String()
+}
+
+// isPlain сообщает, записано ли слово целиком без кавычек и экранирования.
+// Только такие слова могут быть ключевыми словами и операторами.
+func (w word) isPlain() bool {
+	for _, part := range w {
+		if part.quote != unquoted {
+			return false
+		}
+	}
+	return true
+}
+
+type token struct {
+	kind  tokenKind
+	word  word
+	redir *redirect
+	pos   int // начало токена во входной строке (в рунах)
+	end   int // позиция сразу за токеном
+	// aliases — алиасы, из раскрытия которых получен токен; повторно они не раскрываются.
+	aliases []string
+}
+
+// syntaxError — ошибка разбора командной строки. incomplete означает, что ввод
+// оборвался (незакрытая кавычка, | в конце строки, here-doc без разделителя)
+// и интерактивный шелл может дочитать следующую строку.
+type syntaxError struct {
+	pos        int
+	msg        string
+	incomplete bool
+}
+
+func (e *syntaxError) Error() string {
+	return fmt.Sprintf("syntax error at position %d: %s", e.pos+1, e.msg)
+}
+
+// lexer разбивает командную строку на слова и операторы по правилам POSIX shell:
+// одинарные кавычки сохраняют всё буквально, в двойных кавычках обратная косая черта
+// экранирует только $ ` " \ и перевод строки, вне кавычек — любой символ.
+type lexer struct {
+	src []rune
+	pos int
+	// awaitingDelim — here-doc, для которого следующее слово будет разделителем.
+	awaitingDelim *redirect
+	// pendingHeredocs — here-doc, тела которых начинаются со следующей строки.
+	pendingHeredocs []*redirect
+}
+
+func tokenize(input string) ([]token, error) {
+	lx := &lexer{src: []rune(input)}
+	var tokens []token
+	for {
+		tok, err := lx.next()
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, tok)
+		if tok.kind == tokEOF {
+			return tokens, nil
+		}
+	}
+}
+
+func (lx *lexer) peek(offset int) rune {
+	if lx.pos+offset < len(lx.src) {
+		return lx.src[lx.pos+offset]
+	}
+	return 0
+}
+
+func (lx *lexer) eof() bool {
+	return lx.pos >= len(lx.src)
+}
+
+func (lx *lexer) next() (token, error) {
+	for !lx.eof() {
+		switch r := lx.peek(0); {
+		case r == ' ' || r == '\t' || r == '\r':
+			lx.pos++
+		case r == '\\' && lx.peek(1) == '\n':
+			lx.pos += 2
+		case r == '#':
+			for !lx.eof() && lx.peek(0) != '\n' {
+				lx.pos++
+			}
+		default:
+			tok, err := lx.scan()
+			tok.end = lx.pos
+			return tok, err
+		}
+	}
+	if lx.awaitingDelim != nil || len(lx.pendingHeredocs) > 0 {
+		return token{}, &syntaxError{pos: lx.pos, msg: "here-document is not terminated", incomplete: true}
+	}
+	return token{kind: tokEOF, pos: lx.pos, end: lx.pos}, nil
+}
+
+func (lx *lexer) scan() (token, error) {
+	start := lx.pos
+	op := func(kind tokenKind, width int) (token, error) {
+		lx.pos += width
+		return token{kind: kind, pos: start}, nil
+	}
+
+	if tok, ok, err := lx.scanRedirect(); ok || err != nil {
+		return tok, err
+	}
+
+	switch r := lx.peek(0); r {
+	case '\n':
+		if lx.awaitingDelim != nil {
+			return token{}, &syntaxError{pos: start, msg: "missing here-document delimiter"}
+		}
+		lx.pos++
+		if err := lx.readHeredocBodies(); err != nil {
+			return token{}, err
+		}
+		return token{kind: tokNewline, pos: start}, nil
+	case ';':
+		return op(tokSemi, 1)
+	case '|':
+		if lx.peek(1) == '|' {
+			return op(tokOrIf, 2)
+		}
+		return op(tokPipe, 1)
+	case '&':
+		if lx.peek(1) == '&' {
+			return op(tokAndIf, 2)
+		}
+		return op(tokAmp, 1)
+	case '(':
+		return op(tokLParen, 1)
+	case ')':
+		return op(tokRParen, 1)
+	}
+
+	w, err := lx.scanWord()
+	if err != nil {
+		return token{}, err
+	}
+	if hd := lx.awaitingDelim; hd != nil {
+		lx.awaitingDelim = nil
+		hd.heredoc.delimiter = w.literal()
+		hd.heredoc.expand = w.isPlain()
+		lx.pendingHeredocs = append(lx.pendingHeredocs, hd)
+	}
+	return token{kind: tokWord, word: w, pos: start}, nil
+}
+
+// scanRedirect распознаёт операторы перенаправления, в том числе с номером
+// дескриптора перед ними (2>, 2>>, 2>&1). Номер учитывается, только если он
+// стоит вплотную к оператору: в "echo 2 > f" двойка — обычный аргумент.
+func (lx *lexer) scanRedirect() (token, bool, error) {
+	start := lx.pos
+	i := lx.pos
+	for i < len(lx.src) && lx.src[i] >= '0' && lx.src[i] <= '9' {
+		i++
+	}
+	if i >= len(lx.src) || (lx.src[i] != '<' && lx.src[i] != '>') {
+		return token{}, false, nil
+	}
+
+	r := &redirect{fd: -1}
+	if i > lx.pos {
+		fd, err := strconv.Atoi(string(lx.src[lx.pos:i]))
+		if err != nil {
+			return token{}, false, &syntaxError{pos: start, msg: "invalid file descriptor"}
+		}
+		r.fd = fd
+	}
+	lx.pos = i
+
+	next := lx.peek(1)
+	switch lx.peek(0) {
+	case '<':
+		switch {
+		case next == '<' && lx.peek(2) == '-':
+			r.op, r.heredoc = redirHeredoc, &heredoc{stripTabs: true}
+			lx.pos += 3
+		case next == '<':
+			r.op, r.heredoc = redirHeredoc, &heredoc{}
+			lx.pos += 2
+		case next == '&':
+			r.op = redirDupIn
+			lx.pos += 2
+		default:
+			r.op = redirIn
+			lx.pos++
+		}
+		if r.fd < 0 {
+			r.fd = 0
+		}
+	case '>':
+		switch next {
+		case '>':
+			r.op = redirAppend
+			lx.pos += 2
+		case '&':
+			r.op = redirDupOut
+			lx.pos += 2
+		case '|':
+			r.op = redirOut
+			lx.pos += 2
+		default:
+			r.op = redirOut
+			lx.pos++
+		}
+		if r.fd < 0 {
+			r.fd = 1
+		}
+	}
+	if r.heredoc != nil {
+		lx.awaitingDelim = r
+	}
+	return token{kind: tokRedirect, redir: r, pos: start}, true, nil
+}
+
+// readHeredocBodies читает тела отложенных here-doc: строки после перевода строки
+// до строки, совпадающей с разделителем. Для <<- ведущие табуляции удаляются.
+func (lx *lexer) readHeredocBodies() error {
+	for len(lx.pendingHeredocs) > 0 {
+		hd := lx.pendingHeredocs[0].heredoc
+		var body strings.Builder
+		for {
+			if lx.eof() {
+				return &syntaxError{pos: lx.pos, msg: fmt.Sprintf("here-document delimited by %q is not terminated", hd.delimiter), incomplete: true}
+			}
+			end := lx.pos
+			for end < len(lx.src) && lx.src[end] != '\n' {
+				end++
+			}
+			line := string(lx.src[lx.pos:end])
+			lx.pos = min(end+1, len(lx.src))
+			if hd.stripTabs {
+				line = strings.TrimLeft(line, "\t")
+			}
+			if line == hd.delimiter {
+				break
+			}
+			body.WriteString(line)
+			body.WriteByte('\n')
+		}
+		hd.body = body.String()
+		lx.pendingHeredocs = lx.pendingHeredocs[1:]
+	}
+	return nil
+}
+
+// isMeta сообщает, завершает ли символ слово вне кавычек.
+func isMeta(r rune) bool {
+	switch r {
+	case ' ', '\t', '\r', '\n', ';', '|', '&', '<', '>', '(', ')':
+		return true
+	}
+	return false
+}
+
+func (lx *lexer) scanWord() (word, error) {
+	var w word
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			w = append(w, wordPart{text: current.String()})
+			current.Reset()
+		}
+	}
+
+	for !lx.eof() && !isMeta(lx.peek(0)) {
+		switch r := lx.peek(0); r {
+		case '\'':
+			flush()
+			part, err := lx.scanSingleQuoted()
+			if err != nil {
+				return nil, err
+			}
+			w = append(w, part)
+		case '"':
+			flush()
+			parts, err := lx.scanDoubleQuoted()
+			if err != nil {
+				return nil, err
+			}
+			w = append(w, parts...)
+		case '\\':
+			flush()
+			lx.pos++
+			if lx.eof() {
+				return nil, &syntaxError{pos: lx.pos, msg: "unexpected end of input after '\\'", incomplete: true}
+			}
+			w = append(w, wordPart{text: string(lx.peek(0)), quote: singleQuoted})
+			lx.pos++
+		case '$', '`':
+			if err := lx.scanDollar(&current); err != nil {
+				return nil, err
+			}
+		default:
+			current.WriteRune(r)
+			lx.pos++
+		}
+	}
+	flush()
+	return w, nil
+}
+
+// scanDollar копирует в sb подстановку, начинающуюся с $ или `. Конструкции ${...},
+// $(...) и `...` копируются целиком, даже если внутри есть пробелы, кавычки или |:
+// их разбирает уже подстановка при выполнении.
+func (lx *lexer) scanDollar(sb *strings.Builder) error {
+	start := lx.pos
+	if lx.peek(0) == '`' {
+		sb.WriteRune('`')
+		lx.pos++
+		for !lx.eof() {
+			r := lx.peek(0)
+			sb.WriteRune(r)
+			lx.pos++
+			switch {
+			case r == '`':
+				return nil
+			case r == '\\' && !lx.eof():
+				sb.WriteRune(lx.peek(0))
+				lx.pos++
+			}
+		}
+		return &syntaxError{pos: start, msg: "unterminated `", incomplete: true}
+	}
+
+	var open, close rune
+	switch lx.peek(1) {
+	case '{':
+		open, close = '{', '}'
+	case '(':
+		open, close = '(', ')'
+	default:
+		sb.WriteRune('$')
+		lx.pos++
+		return nil
+	}
+
+	depth := 0
+	var quote rune
+	for !lx.eof() {
+		r := lx.peek(0)
+		sb.WriteRune(r)
+		lx.pos++
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else if r == '\\' && quote == '"' && !lx.eof() {
+				sb.WriteRune(lx.peek(0))
+				lx.pos++
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '\\' && !lx.eof():
+			sb.WriteRune(lx.peek(0))
+			lx.pos++
+		case r == open:
+			depth++
+		case r == close:
+			depth--
+			if depth == 0 {
+				return nil
+			}
+		}
+	}
+	return &syntaxError{pos: start, msg: fmt.Sprintf("unterminated $%c", open), incomplete: true}
+}
+
+func (lx *lexer) scanSingleQuoted() (wordPart, error) {
+	start := lx.pos
+	lx.pos++
+	var sb strings.Builder
+	for {
+		if lx.eof() {
+			return wordPart{}, &syntaxError{pos: start, msg: "unterminated single quote", incomplete: true}
+		}
+		r := lx.peek(0)
+		lx.pos++
+		if r == '\'' {
+			// '' — пустая строка, но всё же отдельное слово.
+			return wordPart{text: sb.String(), quote: singleQuoted}, nil
+		}
+		sb.WriteRune(r)
+	}
+}
+
+func (lx *lexer) scanDoubleQuoted() ([]wordPart, error) {
+	start := lx.pos
+	lx.pos++
+	parts := []wordPart{}
+	var sb strings.Builder
+	flush := func(force bool) {
+		if sb.Len() > 0 || force {
+			parts = append(parts, wordPart{text: sb.String(), quote: doubleQuoted})
+			sb.Reset()
+		}
+	}
+
+	for {
+		if lx.eof() {
+			return nil, &syntaxError{pos: start, msg: "unterminated double quote", incomplete: true}
+		}
+		r := lx.peek(0)
+		lx.pos++
+		switch {
+		case r == '"':
+			flush(len(parts) == 0)
+			return parts, nil
+		case r == '\\' && strings.ContainsRune("$`\"\\\n", lx.peek(0)):
+			escaped := lx.peek(0)
+			lx.pos++
+			if escaped == '\n' {
+				continue
+			}
+			flush(false)
+			parts = append(parts, wordPart{text: string(escaped), quote: singleQuoted})
+		case r == '$' || r == '`':
+			lx.pos--
+			if err := lx.scanDollar(&sb); err != nil {
+				return nil, err
+			}
+		default:
+			sb.WriteRune(r)
+		}
+	}
+}
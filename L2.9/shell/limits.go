@@ -0,0 +1,247 @@
+package shell
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"L2/internal/cli"
+)
+
+// rlimitResource — ограничение ресурса, которое задаёт ulimit.
+type rlimitResource struct {
+	flag     byte
+	resource int
+	name     string
+	unit     string // единица в выводе ulimit -a
+	factor   uint64 // во столько раз значение rlimit больше значения ulimit
+}
+
+var rlimitResources = []rlimitResource{
+	{'t', syscall.RLIMIT_CPU, "cpu time", "seconds", 1},
+	{'d', syscall.RLIMIT_DATA, "data seg size", "kbytes", 1024},
+	{'n', syscall.RLIMIT_NOFILE, "open files", "", 1},
+	{'v', syscall.RLIMIT_AS, "virtual memory", "kbytes", 1024},
+}
+
+func findRlimit(flag byte) (rlimitResource, bool) {
+	for _, r := range rlimitResources {
+		if r.flag == flag {
+			return r, true
+		}
+	}
+	return rlimitResource{}, false
+}
+
+// rlimitInfinity — значение rlimit без ограничения (RLIM_INFINITY).
+const rlimitInfinity = math.MaxUint64
+
+// resourceLimit — мягкое и жёсткое ограничение ресурса. Тип полей syscall.Rlimit
+// зависит от системы, поэтому ulimit работает с этим типом, а в syscall.Rlimit
+// его переводят getrlimit и setrlimit (rlimit_*.go).
+type resourceLimit struct {
+	cur, max uint64
+}
+
+// limitExecOption — скрытый параметр l2sh, с которым шелл запускает сам себя, чтобы
+// выполнить программу с ограничениями ulimit: l2sh --rlimit-exec ПУТЬ ARGV0 [АРГУМЕНТЫ...].
+// Ограничения передаются в переменной окружения rlimitsEnv.
+const (
+	limitExecOption = "--rlimit-exec"
+	rlimitsEnv      = "L2SH_RLIMITS"
+)
+
+// startProcess запускает cmd с ограничениями, заданными ulimit. Go не даёт выполнить
+// код между fork и exec, поэтому программа с ограничениями запускается через
+// промежуточный процесс — исполняемый файл l2sh с параметром limitExecOption:
+// он вызывает setrlimit и заменяет себя программой через execve (см. execWithLimits).
+// Без ограничений программа запускается обычным cmd.Start.
+func (sh *shell) startProcess(cmd *exec.Cmd) error {
+	sh.mu.Lock()
+	limits := make([]string, 0, len(sh.limits))
+	for resource, lim := range sh.limits {
+		limits = append(limits, fmt.Sprintf("%d:%d:%d", resource, lim.cur, lim.max))
+	}
+	sh.mu.Unlock()
+	if len(limits) == 0 || cmd.Err != nil {
+		return cmd.Start()
+	}
+	if sh.limitExec == "" {
+		return errors.New("ulimit: resource limits for programs are applied only by the l2sh binary")
+	}
+	sort.Strings(limits)
+
+	env := cmd.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	cmd.Env = append(slices.Clip(env), rlimitsEnv+"="+strings.Join(limits, ";"))
+	cmd.Args = append([]string{sh.limitExec, limitExecOption, cmd.Path}, cmd.Args...)
+	cmd.Path = sh.limitExec
+	return cmd.Start()
+}
+
+// execWithLimits выполняется в промежуточном процессе (l2sh --rlimit-exec): устанавливает
+// ограничения из rlimitsEnv ("ресурс:мягкое:жёсткое;...") и заменяет себя программой
+// args[0] с argv args[1:] и окружением процесса без rlimitsEnv. Возвращает управление
+// только при ошибке.
+func execWithLimits(args []string, stdio cli.Stdio) int {
+	if len(args) < 2 {
+		fmt.Fprintf(stdio.Err, "l2sh: %s: missing program\n", limitExecOption)
+		return 2
+	}
+	for _, item := range strings.Split(os.Getenv(rlimitsEnv), ";") {
+		var resource int
+		var lim resourceLimit
+		if _, err := fmt.Sscanf(item, "%d:%d:%d", &resource, &lim.cur, &lim.max); err != nil {
+			fmt.Fprintf(stdio.Err, "l2sh: ulimit: %q: invalid limit\n", item)
+			return 126
+		}
+		if err := setrlimit(resource, lim); err != nil {
+			fmt.Fprintf(stdio.Err, "l2sh: ulimit: %v\n", err)
+			return 126
+		}
+	}
+
+	env := slices.DeleteFunc(os.Environ(), func(kv string) bool {
+		return strings.HasPrefix(kv, rlimitsEnv+"=")
+	})
+	err := syscall.Exec(args[0], args[1:], env)
+	fmt.Fprintf(stdio.Err, "l2sh: %s: %v\n", args[1], err)
+	return 126
+}
+
+// rlimit возвращает ограничение, которое получат запускаемые программы.
+func (sh *shell) rlimit(resource int) (resourceLimit, error) {
+	sh.mu.Lock()
+	lim, ok := sh.limits[resource]
+	sh.mu.Unlock()
+	if ok {
+		return lim, nil
+	}
+	return getrlimit(resource)
+}
+
+// builtinUlimit показывает и меняет ограничения ресурсов для запускаемых программ:
+//
+//	ulimit [-S|-H] -t|-d|-n|-v [LIMIT|unlimited]
+//	ulimit [-S|-H] -a
+//
+// -t — процессорное время в секундах, -n — число открытых файлов, -d и -v — размер
+// данных и виртуальной памяти в килобайтах. -S меняет только мягкое ограничение,
+// -H — только жёсткое, без них меняются оба. Сам шелл ограничения не получает.
+func builtinUlimit(sh *shell, args []string, s streams) int {
+	soft, hard, all := false, false, false
+	var res *rlimitResource
+	var value string
+	for _, arg := range args[1:] {
+		if len(arg) < 2 || arg[0] != '-' {
+			if value != "" {
+				fmt.Fprintln(s.stderr, "ulimit: too many arguments")
+				return 2
+			}
+			value = arg
+			continue
+		}
+		for i := 1; i < len(arg); i++ {
+			switch arg[i] {
+			case 'S':
+				soft = true
+			case 'H':
+				hard = true
+			case 'a':
+				all = true
+			default:
+				r, ok := findRlimit(arg[i])
+				if !ok {
+					fmt.Fprintf(s.stderr, "ulimit: -%c: invalid option\n", arg[i])
+					fmt.Fprintln(s.stderr, "usage: ulimit [-SH] [-a] [-t|-d|-n|-v] [limit]")
+					return 2
+				}
+				res = &r
+			}
+		}
+	}
+
+	show := func(lim resourceLimit, r rlimitResource) string {
+		v := lim.cur
+		if hard && !soft {
+			v = lim.max
+		}
+		if v == rlimitInfinity {
+			return "unlimited"
+		}
+		return strconv.FormatUint(v/r.factor, 10)
+	}
+	if all || res == nil && value == "" {
+		for _, r := range rlimitResources {
+			lim, err := sh.rlimit(r.resource)
+			if err != nil {
+				fmt.Fprintln(s.stderr, "ulimit:", err)
+				return 1
+			}
+			unit := "(-" + string(r.flag) + ")"
+			if r.unit != "" {
+				unit = "(" + r.unit + ", -" + string(r.flag) + ")"
+			}
+			fmt.Fprintf(s.stdout, "%-16s%20s %s\n", r.name, unit, show(lim, r))
+		}
+		return 0
+	}
+	if res == nil {
+		fmt.Fprintln(s.stderr, "ulimit: resource option required (-t, -d, -n or -v)")
+		return 2
+	}
+
+	lim, err := sh.rlimit(res.resource)
+	if err != nil {
+		fmt.Fprintln(s.stderr, "ulimit:", err)
+		return 1
+	}
+	if value == "" {
+		fmt.Fprintln(s.stdout, show(lim, *res))
+		return 0
+	}
+
+	v := uint64(rlimitInfinity)
+	if value != "unlimited" {
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil || n > rlimitInfinity/res.factor {
+			fmt.Fprintf(s.stderr, "ulimit: %s: invalid number\n", value)
+			return 1
+		}
+		v = n * res.factor
+	}
+	next := lim
+	if !hard || soft {
+		next.cur = v
+	}
+	if !soft || hard {
+		next.max = v
+		// Жёсткое ограничение ниже текущего мягкого опускает и мягкое.
+		next.cur = min(next.cur, v)
+	}
+	switch {
+	case next.cur > next.max:
+		fmt.Fprintf(s.stderr, "ulimit: %s: soft limit exceeds hard limit\n", res.name)
+		return 1
+	case next.max > lim.max && os.Geteuid() != 0:
+		fmt.Fprintf(s.stderr, "ulimit: %s: cannot raise hard limit: %v\n", res.name, syscall.EPERM)
+		return 1
+	}
+
+	sh.mu.Lock()
+	if sh.limits == nil {
+		sh.limits = make(map[int]resourceLimit)
+	}
+	sh.limits[res.resource] = next
+	sh.mu.Unlock()
+	return 0
+}
@@ -0,0 +1,83 @@
+package shell
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"L2/internal/cli"
+)
+
+// TestMain выполняет промежуточный процесс ulimit: в тестах шелл запускает
+// программы с ограничениями через тестовый бинарь, а не через l2sh.
+func TestMain(m *testing.M) {
+	if len(os.Args) > 1 && os.Args[1] == limitExecOption {
+		os.Exit(execWithLimits(os.Args[2:], cli.OSStdio()))
+	}
+	os.Exit(m.Run())
+}
+
+// limitedShell возвращает шелл, который запускает программы с ограничениями ulimit.
+func limitedShell(t *testing.T) *shell {
+	t.Helper()
+	sh := newShell()
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sh.limitExec = exe
+	return sh
+}
+
+func TestUlimit(t *testing.T) {
+	tests := []struct {
+		script   string
+		expected string
+		status   int
+	}{
+		{"ulimit -n 64; ulimit -n; sh -c 'ulimit -n; ulimit -Hn'", "64\n64\n64\n", 0},
+		{"ulimit -Sn 32; ulimit -Sn; ulimit -Hn 48; ulimit -Hn; sh -c 'ulimit -Sn; ulimit -Hn'", "32\n48\n32\n48\n", 0},
+		{"ulimit -v 500000; sh -c 'ulimit -v'", "500000\n", 0},
+		{"ulimit -n 20; env X=1 sh -c 'ulimit -n'; echo $X", "20\n\n", 0},
+		{"ulimit -t 2; ulimit -t unlimited; sh -c 'ulimit -t'", "unlimited\n", 0},
+		{"ulimit -n 16; sh -c 'exec 3</dev/null 4</dev/null 5</dev/null; echo opened'", "opened\n", 0},
+		{"ulimit -n 3; cat /dev/null 2>/dev/null || echo failed; echo still works", "failed\nstill works\n", 0},
+		{"ulimit -Hn 40; ulimit -Sn 50", "", 1},
+		{"ulimit -n many", "", 1},
+		{"ulimit -x", "", 2},
+		{"ulimit 10", "", 2},
+	}
+
+	for _, test := range tests {
+		status, out, _ := runScriptText(t, limitedShell(t), test.script)
+		if status != test.status {
+			t.Errorf("Для скрипта %q ожидался код %d, но получен %d", test.script, test.status, status)
+		}
+		if out != test.expected {
+			t.Errorf("Для скрипта %q ожидалось %q, но получено %q", test.script, test.expected, out)
+		}
+	}
+
+	// Без исполняемого файла l2sh (встроенный шелл) ограничения программам не задаются.
+	status, _, s := runScriptText(t, newShell(), "ulimit -n 20; sh -c 'echo started'")
+	if stderr := s.stderr.(*bytes.Buffer).String(); status == 0 || !strings.Contains(stderr, "only by the l2sh binary") {
+		t.Errorf("Ожидалась ошибка запуска без l2sh, но получен код %d: %q", status, stderr)
+	}
+
+	status, out, _ := runScriptText(t, limitedShell(t), "ulimit -a")
+	if status != 0 || !strings.Contains(out, "open files") || !strings.Contains(out, "(seconds, -t)") {
+		t.Errorf("ulimit -a вывел неожиданный результат: %q", out)
+	}
+
+	// Ограничение процессорного времени завершает бесконечный цикл сигналом SIGXCPU или SIGKILL.
+	start := time.Now()
+	status, _, _ = runScriptText(t, limitedShell(t), "ulimit -t 1; sh -c 'while :; do :; done'")
+	if status != 128+24 && status != 128+9 {
+		t.Errorf("Ожидалось завершение по ограничению CPU, но получен код %d", status)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Ограничение CPU не сработало: цикл выполнялся %v", elapsed)
+	}
+}
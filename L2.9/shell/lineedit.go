@@ -0,0 +1,469 @@
+package shell
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// errInterrupted — ввод строки прерван Ctrl+C.
+var errInterrupted = errors.New("interrupted")
+
+// historySize — сколько строк истории хранится в памяти и в файле.
+const historySize = 1000
+
+// Клавиши, которые приходят escape-последовательностями. Отрицательные значения
+// не пересекаются с символами.
+const (
+	keyUp rune = -(iota + 1)
+	keyDown
+	keyLeft
+	keyRight
+	keyHome
+	keyEnd
+	keyDelete
+	keyUnknown
+)
+
+func ctrl(c byte) rune {
+	return rune(c & 0x1f)
+}
+
+// lineEditor — редактор строки для интерактивного сеанса: перемещение курсора,
+// история (стрелки и Ctrl+R) и дополнение по Tab. Терминал должен быть
+// в режиме посимвольного ввода (makeRaw); редактор только читает клавиши из in
+// и рисует строку в out escape-последовательностями VT100.
+type lineEditor struct {
+	in      io.Reader
+	out     io.Writer
+	pending []byte
+
+	history     []string
+	historyFile string
+
+	// complete возвращает начало дополняемого слова в line и варианты замены line[start:pos].
+	complete func(line []rune, pos int) (start int, candidates []string)
+	// width возвращает ширину терминала для вывода вариантов дополнения.
+	width func() int
+
+	prompt string
+	line   []rune
+	pos    int
+}
+
+func newLineEditor(in io.Reader, out io.Writer) *lineEditor {
+	return &lineEditor{in: in, out: out, width: func() int { return 80 }}
+}
+
+// readLine читает строку после приглашения prompt. Ctrl+D в пустой строке
+// возвращает io.EOF, Ctrl+C — errInterrupted.
+func (ed *lineEditor) readLine(prompt string) (string, error) {
+	ed.prompt, ed.line, ed.pos = prompt, nil, 0
+	historyIndex := len(ed.history)
+	var edited []rune // строка, которую редактировали до перехода по истории
+
+	io.WriteString(ed.out, prompt)
+	for {
+		key, err := ed.readKey()
+		if err != nil {
+			if len(ed.line) > 0 && err == io.EOF {
+				io.WriteString(ed.out, "\r\n")
+				return ed.accept(), nil
+			}
+			return "", err
+		}
+		if key == ctrl('R') {
+			if key, err = ed.reverseSearch(); err != nil {
+				return "", err
+			}
+		}
+
+		switch key {
+		case '\r', '\n':
+			io.WriteString(ed.out, "\r\n")
+			return ed.accept(), nil
+		case ctrl('C'):
+			io.WriteString(ed.out, "^C\r\n")
+			return "", errInterrupted
+		case ctrl('D'):
+			if len(ed.line) == 0 {
+				io.WriteString(ed.out, "\r\n")
+				return "", io.EOF
+			}
+			ed.deleteAt(ed.pos)
+		case 127, ctrl('H'):
+			if ed.pos > 0 {
+				ed.pos--
+				ed.deleteAt(ed.pos)
+			}
+		case keyDelete:
+			ed.deleteAt(ed.pos)
+		case keyLeft, ctrl('B'):
+			ed.pos = max(ed.pos-1, 0)
+		case keyRight, ctrl('F'):
+			ed.pos = min(ed.pos+1, len(ed.line))
+		case keyHome, ctrl('A'):
+			ed.pos = 0
+		case keyEnd, ctrl('E'):
+			ed.pos = len(ed.line)
+		case ctrl('U'):
+			ed.line, ed.pos = append([]rune(nil), ed.line[ed.pos:]...), 0
+		case ctrl('K'):
+			ed.line = ed.line[:ed.pos]
+		case ctrl('W'):
+			start := ed.pos
+			for start > 0 && ed.line[start-1] == ' ' {
+				start--
+			}
+			for start > 0 && ed.line[start-1] != ' ' {
+				start--
+			}
+			ed.line = append(ed.line[:start], ed.line[ed.pos:]...)
+			ed.pos = start
+		case ctrl('L'):
+			io.WriteString(ed.out, "\x1b[H\x1b[2J"+ed.prompt)
+		case keyUp, ctrl('P'), keyDown, ctrl('N'):
+			next := historyIndex - 1
+			if key == keyDown || key == ctrl('N') {
+				next = historyIndex + 1
+			}
+			if next < 0 || next > len(ed.history) {
+				break
+			}
+			if historyIndex == len(ed.history) {
+				edited = ed.line
+			}
+			historyIndex = next
+			if next == len(ed.history) {
+				ed.line = edited
+			} else {
+				ed.line = []rune(ed.history[next])
+			}
+			ed.pos = len(ed.line)
+		case '\t':
+			ed.completeWord()
+		default:
+			if key >= ' ' {
+				ed.insert(key)
+			}
+		}
+		ed.refresh()
+	}
+}
+
+// accept завершает ввод строки и добавляет её в историю.
+func (ed *lineEditor) accept() string {
+	line := string(ed.line)
+	ed.addHistory(line)
+	return line
+}
+
+func (ed *lineEditor) insert(runes ...rune) {
+	line := make([]rune, 0, len(ed.line)+len(runes))
+	line = append(append(append(line, ed.line[:ed.pos]...), runes...), ed.line[ed.pos:]...)
+	ed.line = line
+	ed.pos += len(runes)
+}
+
+func (ed *lineEditor) deleteAt(i int) {
+	if i < len(ed.line) {
+		ed.line = append(ed.line[:i], ed.line[i+1:]...)
+	}
+}
+
+// refresh перерисовывает строку и ставит курсор на место.
+func (ed *lineEditor) refresh() {
+	ed.render(ed.prompt, ed.line, ed.pos)
+}
+
+// render выводит строку line после последней строки приглашения prompt
+// и ставит курсор перед line[pos].
+func (ed *lineEditor) render(prompt string, line []rune, pos int) {
+	if i := strings.LastIndexByte(prompt, '\n'); i >= 0 {
+		prompt = prompt[i+1:]
+	}
+	var sb strings.Builder
+	sb.WriteString("\r")
+	sb.WriteString(prompt)
+	sb.WriteString(string(line))
+	sb.WriteString("\x1b[K")
+	if back := len(line) - pos; back > 0 {
+		fmt.Fprintf(&sb, "\x1b[%dD", back)
+	}
+	io.WriteString(ed.out, sb.String())
+}
+
+func (ed *lineEditor) readByte() (byte, error) {
+	if len(ed.pending) == 0 {
+		buf := make([]byte, 64)
+		n, err := ed.in.Read(buf)
+		if n == 0 {
+			if err == nil {
+				err = io.EOF
+			}
+			return 0, err
+		}
+		ed.pending = buf[:n]
+	}
+	b := ed.pending[0]
+	ed.pending = ed.pending[1:]
+	return b, nil
+}
+
+// readKey читает одну клавишу: символ UTF-8, управляющий символ или
+// escape-последовательность стрелок, Home, End и Delete.
+func (ed *lineEditor) readKey() (rune, error) {
+	b, err := ed.readByte()
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case b == 0x1b:
+		return ed.readEscape()
+	case b < utf8.RuneSelf:
+		return rune(b), nil
+	}
+
+	buf := []byte{b}
+	for !utf8.FullRune(buf) && len(buf) < utf8.UTFMax {
+		next, err := ed.readByte()
+		if err != nil {
+			return 0, err
+		}
+		buf = append(buf, next)
+	}
+	r, _ := utf8.DecodeRune(buf)
+	return r, nil
+}
+
+// readEscape разбирает последовательность после ESC: ESC [ A, ESC O H, ESC [ 3 ~ и т.п.
+func (ed *lineEditor) readEscape() (rune, error) {
+	b, err := ed.readByte()
+	if err != nil {
+		return 0, err
+	}
+	if b != '[' && b != 'O' {
+		return keyUnknown, nil
+	}
+	var params []byte
+	for {
+		c, err := ed.readByte()
+		if err != nil {
+			return 0, err
+		}
+		if c >= '0' && c <= '9' || c == ';' {
+			params = append(params, c)
+			continue
+		}
+		switch c {
+		case 'A':
+			return keyUp, nil
+		case 'B':
+			return keyDown, nil
+		case 'C':
+			return keyRight, nil
+		case 'D':
+			return keyLeft, nil
+		case 'H':
+			return keyHome, nil
+		case 'F':
+			return keyEnd, nil
+		case '~':
+			switch string(params) {
+			case "1", "7":
+				return keyHome, nil
+			case "4", "8":
+				return keyEnd, nil
+			case "3":
+				return keyDelete, nil
+			}
+		}
+		return keyUnknown, nil
+	}
+}
+
+// reverseSearch ищет строку в истории по подстроке (Ctrl+R). Найденная строка
+// становится редактируемой. Возвращается клавиша, завершившая поиск: её
+// обрабатывает обычный редактор, так что Enter сразу выполняет найденную строку.
+// Ctrl+G и Ctrl+C отменяют поиск и возвращают исходную строку.
+func (ed *lineEditor) reverseSearch() (rune, error) {
+	original, originalPos := ed.line, ed.pos
+	var query []rune
+	match := len(ed.history)
+	failed := false
+
+	search := func(from int) {
+		for i := min(from, len(ed.history)-1); i >= 0; i-- {
+			if idx := strings.Index(ed.history[i], string(query)); idx >= 0 {
+				match, failed = i, false
+				ed.line = []rune(ed.history[i])
+				ed.pos = utf8.RuneCountInString(ed.history[i][:idx])
+				return
+			}
+		}
+		failed = true
+	}
+
+	for {
+		prompt := "(reverse-i-search)`"
+		if failed {
+			prompt = "(failed reverse-i-search)`"
+		}
+		ed.render(prompt+string(query)+"': ", ed.line, ed.pos)
+
+		key, err := ed.readKey()
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case key == ctrl('R'):
+			if len(query) > 0 {
+				search(match - 1)
+			}
+		case key == 127 || key == ctrl('H'):
+			if len(query) > 0 {
+				query = query[:len(query)-1]
+				search(len(ed.history) - 1)
+			}
+		case key == ctrl('G') || key == ctrl('C'):
+			ed.line, ed.pos = original, originalPos
+			return keyUnknown, nil
+		case key >= ' ':
+			query = append(query, key)
+			search(match)
+		default:
+			return key, nil
+		}
+	}
+}
+
+// completeWord дополняет слово перед курсором: единственный вариант или общее
+// начало вариантов подставляется, иначе варианты печатаются под строкой.
+func (ed *lineEditor) completeWord() {
+	if ed.complete == nil {
+		return
+	}
+	start, candidates := ed.complete(ed.line, ed.pos)
+	if len(candidates) == 0 {
+		io.WriteString(ed.out, "\a")
+		return
+	}
+
+	prefix := string(ed.line[start:ed.pos])
+	common := commonPrefix(candidates)
+	if len(common) > len(prefix) || len(candidates) == 1 {
+		ed.line = append(ed.line[:start:start], ed.line[ed.pos:]...)
+		ed.pos = start
+		ed.insert([]rune(common)...)
+		return
+	}
+
+	names := make([]string, len(candidates))
+	for i, c := range candidates {
+		name := strings.TrimSuffix(c, " ")
+		if i := strings.LastIndexByte(strings.TrimSuffix(name, "/"), '/'); i >= 0 {
+			name = name[i+1:]
+		}
+		names[i] = name
+	}
+	io.WriteString(ed.out, "\r\n"+strings.ReplaceAll(columns(names, ed.width()), "\n", "\r\n"))
+	io.WriteString(ed.out, ed.prompt)
+}
+
+// commonPrefix возвращает общее начало строк.
+func commonPrefix(items []string) string {
+	prefix := items[0]
+	for _, item := range items[1:] {
+		for !strings.HasPrefix(item, prefix) {
+			_, size := utf8.DecodeLastRuneInString(prefix)
+			prefix = prefix[:len(prefix)-size]
+		}
+	}
+	return prefix
+}
+
+// columns раскладывает имена по столбцам, как ls, для терминала шириной width.
+func columns(names []string, width int) string {
+	sort.Strings(names)
+	colWidth := 0
+	for _, name := range names {
+		colWidth = max(colWidth, utf8.RuneCountInString(name)+2)
+	}
+	cols := max(width/colWidth, 1)
+	rows := (len(names) + cols - 1) / cols
+
+	var sb strings.Builder
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			i := col*rows + row
+			if i >= len(names) {
+				break
+			}
+			sb.WriteString(names[i])
+			if col < cols-1 && i+rows < len(names) {
+				sb.WriteString(strings.Repeat(" ", colWidth-utf8.RuneCountInString(names[i])))
+			}
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// loadHistory читает историю из файла path; туда же будут дописываться новые строки.
+// Если файл разросся больше historySize строк, он переписывается последними строками.
+func (ed *lineEditor) loadHistory(path string) error {
+	ed.historyFile = path
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if len(lines) > historySize {
+		lines = lines[len(lines)-historySize:]
+		if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+			return err
+		}
+	}
+	ed.history = lines
+	return nil
+}
+
+// addHistory добавляет строку в историю и дописывает её в файл истории.
+// Пустые строки и повтор предыдущей строки не запоминаются.
+func (ed *lineEditor) addHistory(line string) {
+	if strings.TrimSpace(line) == "" || len(ed.history) > 0 && ed.history[len(ed.history)-1] == line {
+		return
+	}
+	ed.history = append(ed.history, line)
+	if len(ed.history) > historySize {
+		ed.history = ed.history[len(ed.history)-historySize:]
+	}
+	if ed.historyFile == "" {
+		return
+	}
+	f, err := os.OpenFile(ed.historyFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return
+	}
+	fmt.Fprintln(f, line)
+	f.Close()
+}
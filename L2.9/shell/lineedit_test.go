@@ -0,0 +1,123 @@
+package shell
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLineEditor(t *testing.T) {
+	history := []string{"echo one", "ls -l", "echo two"}
+	tests := []struct {
+		name     string
+		keys     string
+		expected string
+		err      error
+	}{
+		{"ввод", "hello\r", "hello", nil},
+		{"стрелки", "abc\x1b[D\x1b[DX\r", "aXbc", nil},
+		{"Home и End", "bc\x1b[Ha\x1b[Fd\r", "abcd", nil},
+		{"Backspace и Delete", "abcd\x7f\x1b[D\x1b[D\x1b[3~\r", "ac", nil},
+		{"Ctrl+A, Ctrl+K", "abc\x01\x06\x0b\r", "a", nil},
+		{"Ctrl+U", "abc\x1b[D\x15\r", "c", nil},
+		{"Ctrl+W", "echo foo bar\x17\r", "echo foo ", nil},
+		{"UTF-8", "пр\x1b[Dи\r", "пир", nil},
+		{"история вверх", "\x1b[A\x1b[A\r", "ls -l", nil},
+		{"история вниз", "new\x1b[A\x1b[B\r", "new", nil},
+		{"Ctrl+R", "\x12echo\r", "echo two", nil},
+		{"Ctrl+R дважды", "\x12echo\x12\r", "echo one", nil},
+		{"Ctrl+R и правка", "\x12ls\x05 -a\r", "ls -l -a", nil},
+		{"Ctrl+R без совпадений", "\x12zzz\x07x\r", "x", nil},
+		{"Ctrl+C", "abc\x03", "", errInterrupted},
+		{"Ctrl+D", "\x04", "", io.EOF},
+		{"конец ввода", "abc", "abc", nil},
+	}
+
+	for _, test := range tests {
+		var out bytes.Buffer
+		ed := newLineEditor(strings.NewReader(test.keys), &out)
+		ed.history = append([]string(nil), history...)
+		line, err := ed.readLine("> ")
+		if !errors.Is(err, test.err) {
+			t.Errorf("%s: ожидалась ошибка %v, но получено %v", test.name, test.err, err)
+		}
+		if line != test.expected {
+			t.Errorf("%s: ожидалось %q, но получено %q", test.name, test.expected, line)
+		}
+	}
+}
+
+func TestLineEditorHistoryFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	os.WriteFile(path, []byte("old\n"), 0o600)
+
+	ed := newLineEditor(strings.NewReader("first\rfirst\r \rsecond\r"), io.Discard)
+	if err := ed.loadHistory(path); err != nil {
+		t.Fatal(err)
+	}
+	for range 4 {
+		ed.readLine("> ")
+	}
+
+	data, _ := os.ReadFile(path)
+	if expected := "old\nfirst\nsecond\n"; string(data) != expected {
+		t.Errorf("Ожидалось %q, но получено %q", expected, data)
+	}
+
+	ed = newLineEditor(strings.NewReader("\x1b[A\x1b[A\r"), io.Discard)
+	ed.loadHistory(path)
+	if line, _ := ed.readLine("> "); line != "first" {
+		t.Errorf("Ожидалась строка из файла истории %q, но получено %q", "first", line)
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"alpha.txt", "alpine.txt", "beta file", ".hidden"} {
+		os.WriteFile(filepath.Join(dir, name), nil, 0o644)
+	}
+	os.Mkdir(filepath.Join(dir, "sub"), 0o755)
+	tool := filepath.Join(dir, "l2tool")
+	os.WriteFile(tool, nil, 0o755)
+
+	sh := newShell()
+	sh.setVar("PATH", dir)
+	tests := []struct {
+		line     string
+		start    int
+		expected []string
+	}{
+		{"ech", 0, []string{"echo "}},
+		{"l2t", 0, []string{"l2tool "}},
+		{"ls | l2t", 5, []string{"l2tool "}},
+		{"if l2t", 3, []string{"l2tool "}},
+		{"cat " + dir + "/al", 4, []string{dir + "/alpha.txt ", dir + "/alpine.txt "}},
+		{"cat " + dir + "/be", 4, []string{dir + `/beta\ file `}},
+		{"cat " + dir + "/s", 4, []string{dir + "/sub/"}},
+		{"cat " + dir + "/.h", 4, []string{dir + "/.hidden "}},
+		{"echo l2t", 5, nil},
+	}
+	for _, test := range tests {
+		line := []rune(test.line)
+		start, candidates := sh.completeLine(line, len(line))
+		if start != test.start || !reflect.DeepEqual(candidates, test.expected) {
+			t.Errorf("Для %q ожидалось %d %q, но получено %d %q", test.line, test.start, test.expected, start, candidates)
+		}
+	}
+
+	var out bytes.Buffer
+	ed := newLineEditor(strings.NewReader("cat "+dir+"/alp\t\tx\r"), &out)
+	ed.complete = sh.completeLine
+	line, _ := ed.readLine("> ")
+	if expected := "cat " + dir + "/alpx"; line != expected {
+		t.Errorf("Ожидалось %q, но получено %q", expected, line)
+	}
+	if !strings.Contains(out.String(), "\r\nalpha.txt   alpine.txt\r\n") {
+		t.Errorf("Ожидался список вариантов, но получено %q", out.String())
+	}
+}
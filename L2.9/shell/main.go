@@ -0,0 +1,199 @@
+package shell
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"L2/internal/cli"
+)
+
+// Main запускает шелл как программу с аргументами args и возвращает код завершения:
+//
+//	l2sh                        — интерактивный сеанс; в терминале сначала выполняется ~/.l2shrc
+//	l2sh script.sh [args...]    — выполнить скрипт; args — позиционные параметры $1, $2, ...
+//	l2sh -c 'команды' [name [args...]] — выполнить строку; name становится $0
+//
+// Перед ними можно указать --restricted [--config FILE]: ограниченный режим
+// с настройками из FILE (по умолчанию /etc/l2sh/restricted.json, см. restrictConfig).
+// Управление заданиями и редактор строки включаются, только если stdio.In — терминал процесса.
+func Main(args []string, stdio cli.Stdio) int {
+	sh := newShell()
+	sh.stdin, sh.stdout, sh.stderr = stdio.In, stdio.Out, stdio.Err
+
+	restricted, config := false, defaultRestrictConfig
+options:
+	for len(args) > 0 && strings.HasPrefix(args[0], "--") {
+		opt, value, hasValue := strings.Cut(args[0], "=")
+		args = args[1:]
+		switch {
+		case opt == "--":
+			break options
+		case opt == limitExecOption && !hasValue:
+			return execWithLimits(args, stdio)
+		case opt == "--restricted" && !hasValue:
+			restricted = true
+		case opt == "--config" && hasValue:
+			config = value
+		case opt == "--config" && len(args) > 0:
+			config, args = args[0], args[1:]
+		default:
+			fmt.Fprintf(sh.stderr, "l2sh: %s: invalid option\n", opt)
+			fmt.Fprintln(sh.stderr, "usage: l2sh [--restricted [--config FILE]] [-c command | script] [args...]")
+			return 2
+		}
+	}
+	if restricted {
+		r, err := loadRestriction(config)
+		if err != nil {
+			fmt.Fprintln(sh.stderr, "l2sh: restricted:", err)
+			return 2
+		}
+		sh.restrict(r)
+	}
+
+	sh.limitExec, _ = os.Executable()
+
+	if len(args) > 0 {
+		return sh.runArgs(args)
+	}
+
+	sh.enableJobControl()
+	if status, exit := sh.loadRC(); exit {
+		return status
+	}
+	readLine := sh.lineReader()
+
+	for {
+		sh.reportJobs(sh.stdStreams())
+		input, err := readLine(sh.prompt("PS1", defaultPS1))
+		if errors.Is(err, errInterrupted) {
+			continue
+		}
+		if err != nil && input == "" {
+			break
+		}
+		if strings.TrimSpace(input) == "\\quit" {
+			break
+		}
+		// Незакрытые кавычки, | в конце строки и тела here-doc дочитываются со следующих строк.
+		for err == nil && needsMoreInput(input) {
+			var more string
+			more, err = readLine(sh.prompt("PS2", defaultPS2))
+			input += more
+		}
+		if errors.Is(err, errInterrupted) {
+			continue
+		}
+		if status, exit := sh.executeCommand(strings.TrimSpace(input)); exit {
+			return status
+		}
+	}
+	return cli.ExitOK
+}
+
+// lineReader возвращает функцию чтения строки ввода вместе с переводом строки.
+// В терминале строку читает редактор с историей в ~/.l2sh_history и дополнением
+// по Tab, иначе строки читаются из stdin как есть.
+func (sh *shell) lineReader() func(prompt string) (string, error) {
+	if !sh.interactive {
+		reader := bufio.NewReader(sh.stdin)
+		return func(prompt string) (string, error) {
+			fmt.Fprint(sh.stdout, prompt)
+			return reader.ReadString('\n')
+		}
+	}
+
+	ed := newLineEditor(sh.stdin, sh.stdout)
+	ed.complete = sh.completeLine
+	ed.width = func() int { return terminalWidth(1) }
+	if home, ok := sh.getVar("HOME"); ok {
+		if err := ed.loadHistory(filepath.Join(home, ".l2sh_history")); err != nil {
+			fmt.Fprintln(sh.stderr, "history:", err)
+		}
+	}
+	return func(prompt string) (string, error) {
+		restore, err := makeRaw(0)
+		if err != nil {
+			return "", err
+		}
+		defer restore()
+		line, err := ed.readLine(prompt)
+		if err != nil {
+			return "", err
+		}
+		return line + "\n", nil
+	}
+}
+
+// loadRC выполняет ~/.l2shrc в интерактивном сеансе: там удобно задать алиасы,
+// функции и PS1. exit в файле завершает шелл.
+func (sh *shell) loadRC() (status int, exit bool) {
+	home, ok := sh.getVar("HOME")
+	if !sh.interactive || !ok {
+		return 0, false
+	}
+	path := filepath.Join(home, ".l2shrc")
+	base := sh.stdStreams()
+	status, err := sh.source(path, base)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		fmt.Fprintf(sh.stderr, "l2sh: %s: %v\n", path, err)
+	}
+	return status, base.ctl.kind == flowExit
+}
+
+// runArgs выполняет скрипт из файла или из аргумента -c и возвращает код завершения.
+func (sh *shell) runArgs(args []string) int {
+	sh.forwardSignals()
+	if args[0] == "-c" {
+		if len(args) < 2 {
+			fmt.Fprintln(sh.stderr, "l2sh: -c: option requires an argument")
+			return 2
+		}
+		if len(args) > 2 {
+			sh.name, sh.params = args[2], args[3:]
+		}
+		return sh.runScript(args[1], sh.name)
+	}
+
+	data, err := os.ReadFile(sh.path(args[0]))
+	if err != nil {
+		fmt.Fprintln(sh.stderr, "l2sh:", err)
+		return 127
+	}
+	sh.name, sh.params = args[0], args[1:]
+	return sh.runScript(string(data), args[0])
+}
+
+// needsMoreInput сообщает, что ввод оборван и его можно продолжить следующей строкой.
+func needsMoreInput(input string) bool {
+	_, err := parse(input)
+	var syntaxErr *syntaxError
+	return errors.As(err, &syntaxErr) && syntaxErr.incomplete
+}
+
+// executeCommand выполняет строку интерактивного сеанса. exit сообщает,
+// что была выполнена команда exit и шелл должен завершиться с кодом status.
+func (sh *shell) executeCommand(input string) (status int, exit bool) {
+	// Проверка на пустой ввод
+	if input == "" {
+		fmt.Fprintln(sh.stdout, "Error: empty command")
+		return 0, false
+	}
+
+	tree, err := sh.parse(input)
+	if err != nil {
+		fmt.Fprintln(sh.stdout, "Error:", err)
+		return 2, false
+	}
+	base := sh.stdStreams()
+	status = sh.runList(tree, base)
+	if base.ctl.kind == flowExit {
+		return base.ctl.status, true
+	}
+	return status, false
+}
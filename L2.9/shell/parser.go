@@ -0,0 +1,568 @@
+package shell
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Грамматика (упрощённый POSIX shell):
+//
+//	list     := andOr { (';' | '&' | '\n') andOr } [';' | '&' | '\n']
+//	andOr    := pipeline { ('&&' | '||') linebreak pipeline }
+//	pipeline := ['!'] command { '|' linebreak command }
+//	command  := { WORD | redirect } | compound { redirect } | funcdef
+//	compound := 'if' list 'then' list { 'elif' list 'then' list } ['else' list] 'fi'
+//	          | ('while' | 'until') list 'do' list 'done'
+//	          | 'for' NAME ['in' { WORD }] (';' | '\n') linebreak 'do' list 'done'
+//	          | '{' list '}'
+//	funcdef  := NAME '(' ')' linebreak compound | 'function' NAME ['(' ')'] linebreak compound
+//	redirect := [N] ('<' | '>' | '>>' | '<&' | '>&' | '<<' | '<<-') WORD
+//
+// Ключевые слова распознаются только в начале команды и только без кавычек.
+// Там же раскрываются алиасы: слово заменяется токенами значения алиаса.
+
+// command — элемент конвейера.
+type command interface{ commandNode() }
+
+// simpleCommand — команда с аргументами: args[0] — имя команды.
+// Перенаправления применяются слева направо, поэтому "2>&1 >f" и ">f 2>&1" различаются.
+type simpleCommand struct {
+	args   []word
+	redirs []*redirect
+}
+
+type redirOp int
+
+const (
+	redirIn      redirOp = iota // <
+	redirOut                    // > и >|
+	redirAppend                 // >>
+	redirDupIn                  // <&
+	redirDupOut                 // >&
+	redirHeredoc                // << и <<-
+)
+
+// redirect — перенаправление дескриптора fd. target — имя файла, номер дескриптора
+// для <& и >& или разделитель here-doc.
+type redirect struct {
+	fd      int
+	op      redirOp
+	target  word
+	heredoc *heredoc
+}
+
+// heredoc — тело here-doc. Если разделитель записан в кавычках, подстановки
+// в теле не выполняются (expand == false).
+type heredoc struct {
+	delimiter string
+	body      string
+	expand    bool
+	stripTabs bool
+}
+
+// compound — перенаправления составной команды: while ...; done <file.
+type compound struct {
+	redirs []*redirect
+}
+
+func (c *compound) redirections() []*redirect { return c.redirs }
+func (c *compound) addRedirect(r *redirect)   { c.redirs = append(c.redirs, r) }
+
+// ifClause — if conds[0]; then bodies[0]; elif conds[1]; then bodies[1]; else elseBody; fi.
+type ifClause struct {
+	compound
+	conds    []*list
+	bodies   []*list
+	elseBody *list
+}
+
+// loopClause — while cond; do body; done. until повторяет тело, пока cond неуспешна.
+type loopClause struct {
+	compound
+	cond  *list
+	body  *list
+	until bool
+}
+
+// forClause — for name in words; do body; done. Без in перебираются позиционные параметры.
+type forClause struct {
+	compound
+	name  string
+	words []word
+	hasIn bool
+	body  *list
+}
+
+// braceGroup — { list; }: команды выполняются в текущем шелле как одна команда.
+type braceGroup struct {
+	compound
+	body *list
+}
+
+// funcDef — определение функции name() body. Выполнение определения только
+// запоминает функцию.
+type funcDef struct {
+	name string
+	body command
+}
+
+// pipeline — команды, соединённые через |. text — исходный текст для вывода jobs.
+// negate (! cmd) инвертирует код завершения конвейера.
+type pipeline struct {
+	commands []command
+	negate   bool
+	text     string
+}
+
+// andOr — конвейеры, соединённые через && и ||. ops[i] связывает pipelines[i] и pipelines[i+1].
+// background означает, что список завершён & и выполняется как фоновое задание.
+type andOr struct {
+	pipelines  []*pipeline
+	ops        []tokenKind
+	background bool
+	text       string
+}
+
+// list — последовательность andOr, разделённых ; или переводом строки.
+type list struct {
+	items []*andOr
+}
+
+func (*simpleCommand) commandNode() {}
+func (*ifClause) commandNode()      {}
+func (*loopClause) commandNode()    {}
+func (*forClause) commandNode()     {}
+func (*braceGroup) commandNode()    {}
+func (*funcDef) commandNode()       {}
+
+// closingWords завершают список внутри составной команды.
+var closingWords = map[string]bool{
+	"then": true, "elif": true, "else": true, "fi": true, "do": true, "done": true, "}": true,
+}
+
+type parser struct {
+	src     []rune
+	tokens  []token
+	pos     int
+	lastEnd int // конец последнего прочитанного токена
+	// alias возвращает значение алиаса; nil — алиасы не раскрываются.
+	alias func(name string) (string, bool)
+	// aliasNext — позиция слова, которое тоже проверяется на алиас, потому что
+	// значение предыдущего алиаса закончилось пробелом; -1 — такого слова нет.
+	aliasNext int
+}
+
+// parse разбирает командную строку в AST.
+func parse(input string) (*list, error) {
+	return parseWithAliases(input, nil)
+}
+
+// parseWithAliases разбирает командную строку, раскрывая алиасы в начале простых команд.
+func parseWithAliases(input string, alias func(name string) (string, bool)) (*list, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{src: []rune(input), tokens: tokens, alias: alias, aliasNext: -1}
+	l, err := p.parseList()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, p.unexpectedWord(tok)
+	}
+	return l, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) advance() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+		p.lastEnd = tok.end
+	}
+	return tok
+}
+
+// textFrom возвращает исходный текст от позиции start до конца последнего прочитанного токена.
+func (p *parser) textFrom(start int) string {
+	return string(p.src[start:p.lastEnd])
+}
+
+func (p *parser) unexpected(tok token) error {
+	if tok.kind == tokEOF {
+		return &syntaxError{pos: tok.pos, msg: "unexpected end of input", incomplete: true}
+	}
+	return &syntaxError{pos: tok.pos, msg: fmt.Sprintf("unexpected token `%s'", tok.kind)}
+}
+
+func (p *parser) skipNewlines() {
+	for p.peek().kind == tokNewline {
+		p.advance()
+	}
+}
+
+// keywords — зарезервированные слова шелла.
+var keywords = map[string]bool{
+	"if": true, "then": true, "elif": true, "else": true, "fi": true, "while": true, "until": true,
+	"for": true, "in": true, "do": true, "done": true, "{": true, "}": true, "!": true, "function": true,
+}
+
+// keyword возвращает ключевое слово в текущей позиции или "", если там обычное слово.
+func (p *parser) keyword() string {
+	tok := p.peek()
+	if tok.kind != tokWord || len(tok.word) != 1 || tok.word[0].quote != unquoted {
+		return ""
+	}
+	if text := tok.word[0].text; keywords[text] {
+		return text
+	}
+	return ""
+}
+
+// expectKeyword пропускает ключевое слово want или сообщает о синтаксической ошибке.
+func (p *parser) expectKeyword(want string) error {
+	if p.keyword() != want {
+		return p.unexpected(p.peek())
+	}
+	p.advance()
+	return nil
+}
+
+func (p *parser) unexpectedWord(tok token) error {
+	if tok.kind == tokWord {
+		return &syntaxError{pos: tok.pos, msg: fmt.Sprintf("unexpected word `%s'", tok.word.literal())}
+	}
+	return p.unexpected(tok)
+}
+
+func (p *parser) parseList() (*list, error) {
+	l := &list{}
+	for {
+		p.skipNewlines()
+		if kind := p.peek().kind; kind != tokWord && kind != tokRedirect || closingWords[p.keyword()] {
+			return l, nil
+		}
+		item, err := p.parseAndOr()
+		if err != nil {
+			return nil, err
+		}
+		l.items = append(l.items, item)
+
+		switch p.peek().kind {
+		case tokAmp:
+			item.background = true
+			p.advance()
+		case tokSemi, tokNewline:
+			p.advance()
+		default:
+			return l, nil
+		}
+	}
+}
+
+func (p *parser) parseAndOr() (*andOr, error) {
+	start := p.peek().pos
+	first, err := p.parsePipeline()
+	if err != nil {
+		return nil, err
+	}
+	ao := &andOr{pipelines: []*pipeline{first}}
+	for p.peek().kind == tokAndIf || p.peek().kind == tokOrIf {
+		op := p.advance().kind
+		p.skipNewlines()
+		next, err := p.parsePipeline()
+		if err != nil {
+			return nil, err
+		}
+		ao.ops = append(ao.ops, op)
+		ao.pipelines = append(ao.pipelines, next)
+	}
+	ao.text = p.textFrom(start)
+	return ao, nil
+}
+
+func (p *parser) parsePipeline() (*pipeline, error) {
+	start := p.peek().pos
+	negate := p.keyword() == "!"
+	if negate {
+		p.advance()
+	}
+	first, err := p.parseCommand()
+	if err != nil {
+		return nil, err
+	}
+	pl := &pipeline{commands: []command{first}, negate: negate}
+	for p.peek().kind == tokPipe {
+		p.advance()
+		p.skipNewlines()
+		next, err := p.parseCommand()
+		if err != nil {
+			return nil, err
+		}
+		pl.commands = append(pl.commands, next)
+	}
+	pl.text = p.textFrom(start)
+	return pl, nil
+}
+
+func (p *parser) parseCommand() (command, error) {
+	if err := p.expandAlias(); err != nil {
+		return nil, err
+	}
+	if kind := p.peek().kind; kind != tokWord && kind != tokRedirect {
+		return nil, p.unexpected(p.peek())
+	}
+	switch kw := p.keyword(); {
+	case kw == "function":
+		p.advance()
+		return p.parseFuncDef()
+	case kw != "" && kw != "!" && kw != "in":
+		return p.parseCompound()
+	case p.peek().kind == tokWord && p.tokens[p.pos+1].kind == tokLParen:
+		return p.parseFuncDef()
+	}
+
+	cmd := &simpleCommand{}
+	for {
+		if p.pos == p.aliasNext {
+			if err := p.expandAlias(); err != nil {
+				return nil, err
+			}
+		}
+		switch tok := p.peek(); tok.kind {
+		case tokWord:
+			cmd.args = append(cmd.args, p.advance().word)
+		case tokRedirect:
+			redir, err := p.parseRedirect()
+			if err != nil {
+				return nil, err
+			}
+			cmd.redirs = append(cmd.redirs, redir)
+		default:
+			return cmd, nil
+		}
+	}
+}
+
+// expandAlias заменяет слово в текущей позиции значением алиаса, пока оно
+// является алиасом. Слово раскрывается, только если оно без кавычек, не ключевое
+// и не раскрывается уже (alias ls='ls -F' не зацикливается). Токены значения
+// получают позицию исходного слова, чтобы текст команды для jobs остался прежним.
+func (p *parser) expandAlias() error {
+	for p.alias != nil {
+		tok := p.peek()
+		if tok.kind != tokWord || len(tok.word) != 1 || tok.word[0].quote != unquoted ||
+			p.keyword() != "" || p.tokens[p.pos+1].kind == tokLParen {
+			return nil
+		}
+		name := tok.word[0].text
+		value, ok := p.alias(name)
+		if !ok || slices.Contains(tok.aliases, name) {
+			return nil
+		}
+
+		expansion, err := tokenize(value)
+		if err != nil {
+			msg := err.Error()
+			if syntaxErr, ok := err.(*syntaxError); ok {
+				msg = syntaxErr.msg
+			}
+			return &syntaxError{pos: tok.pos, msg: fmt.Sprintf("alias %s: %s", name, msg)}
+		}
+		expansion = expansion[:len(expansion)-1] // без tokEOF
+		for i := range expansion {
+			expansion[i].pos, expansion[i].end = tok.pos, tok.end
+			expansion[i].aliases = append(slices.Clip(tok.aliases), name)
+		}
+		p.tokens = slices.Concat(p.tokens[:p.pos], expansion, p.tokens[p.pos+1:])
+		if strings.HasSuffix(value, " ") || strings.HasSuffix(value, "\t") {
+			p.aliasNext = p.pos + len(expansion)
+		}
+	}
+	return nil
+}
+
+func (p *parser) parseRedirect() (*redirect, error) {
+	tok := p.advance()
+	target := p.peek()
+	if target.kind != tokWord {
+		return nil, p.unexpected(target)
+	}
+	p.advance()
+	tok.redir.target = target.word
+	return tok.redir, nil
+}
+
+// parseCompound разбирает составную команду и перенаправления после неё.
+func (p *parser) parseCompound() (command, error) {
+	var (
+		cmd command
+		err error
+	)
+	switch p.keyword() {
+	case "if":
+		cmd, err = p.parseIf()
+	case "while", "until":
+		cmd, err = p.parseLoop()
+	case "for":
+		cmd, err = p.parseFor()
+	case "{":
+		cmd, err = p.parseBraceGroup()
+	default:
+		return nil, p.unexpectedWord(p.peek())
+	}
+	if err != nil {
+		return nil, err
+	}
+	c := cmd.(interface{ addRedirect(*redirect) })
+	for p.peek().kind == tokRedirect {
+		redir, err := p.parseRedirect()
+		if err != nil {
+			return nil, err
+		}
+		c.addRedirect(redir)
+	}
+	return cmd, nil
+}
+
+// parseBody разбирает непустой список команд внутри составной команды.
+func (p *parser) parseBody() (*list, error) {
+	body, err := p.parseList()
+	if err != nil {
+		return nil, err
+	}
+	if len(body.items) == 0 {
+		return nil, p.unexpectedWord(p.peek())
+	}
+	return body, nil
+}
+
+// parseBodyUntil разбирает список, завершённый ключевым словом end, и пропускает end.
+func (p *parser) parseBodyUntil(end string) (*list, error) {
+	body, err := p.parseBody()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword(end); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+func (p *parser) parseIf() (*ifClause, error) {
+	clause := &ifClause{}
+	p.advance()
+	for {
+		cond, err := p.parseBodyUntil("then")
+		if err != nil {
+			return nil, err
+		}
+		body, err := p.parseBody()
+		if err != nil {
+			return nil, err
+		}
+		clause.conds = append(clause.conds, cond)
+		clause.bodies = append(clause.bodies, body)
+		if p.keyword() != "elif" {
+			break
+		}
+		p.advance()
+	}
+	if p.keyword() == "else" {
+		p.advance()
+		body, err := p.parseBody()
+		if err != nil {
+			return nil, err
+		}
+		clause.elseBody = body
+	}
+	return clause, p.expectKeyword("fi")
+}
+
+func (p *parser) parseLoop() (*loopClause, error) {
+	loop := &loopClause{until: p.keyword() == "until"}
+	p.advance()
+	var err error
+	if loop.cond, err = p.parseBodyUntil("do"); err != nil {
+		return nil, err
+	}
+	if loop.body, err = p.parseBodyUntil("done"); err != nil {
+		return nil, err
+	}
+	return loop, nil
+}
+
+func (p *parser) parseFor() (*forClause, error) {
+	p.advance()
+	name := p.peek()
+	if name.kind != tokWord || !name.word.isPlain() || !isName(name.word.literal()) {
+		return nil, p.unexpectedWord(name)
+	}
+	p.advance()
+	loop := &forClause{name: name.word.literal()}
+
+	p.skipNewlines()
+	if p.keyword() == "in" {
+		p.advance()
+		loop.hasIn = true
+		for p.peek().kind == tokWord {
+			loop.words = append(loop.words, p.advance().word)
+		}
+		if kind := p.peek().kind; kind != tokSemi && kind != tokNewline {
+			return nil, p.unexpected(p.peek())
+		}
+		p.advance()
+	} else if p.peek().kind == tokSemi {
+		p.advance()
+	}
+	p.skipNewlines()
+
+	if err := p.expectKeyword("do"); err != nil {
+		return nil, err
+	}
+	body, err := p.parseBodyUntil("done")
+	if err != nil {
+		return nil, err
+	}
+	loop.body = body
+	return loop, nil
+}
+
+func (p *parser) parseBraceGroup() (*braceGroup, error) {
+	p.advance()
+	body, err := p.parseBodyUntil("}")
+	if err != nil {
+		return nil, err
+	}
+	return &braceGroup{body: body}, nil
+}
+
+// parseFuncDef разбирает name() body или, после function, name [()] body.
+func (p *parser) parseFuncDef() (*funcDef, error) {
+	name := p.peek()
+	if name.kind != tokWord || !name.word.isPlain() || !isName(name.word.literal()) {
+		return nil, p.unexpectedWord(name)
+	}
+	p.advance()
+	if p.peek().kind == tokLParen {
+		p.advance()
+		if tok := p.peek(); tok.kind != tokRParen {
+			return nil, p.unexpected(tok)
+		}
+		p.advance()
+	}
+	p.skipNewlines()
+	if kw := p.keyword(); kw == "" || closingWords[kw] || kw == "!" || kw == "in" || kw == "function" {
+		return nil, p.unexpectedWord(p.peek())
+	}
+	body, err := p.parseCompound()
+	if err != nil {
+		return nil, err
+	}
+	return &funcDef{name: name.word.literal(), body: body}, nil
+}
@@ -0,0 +1,337 @@
+package shell
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+		hasError bool
+	}{
+		{`echo hello world`, []string{"echo", "hello", "world"}, false},
+		{`echo "a|b" 'c;d'`, []string{"echo", "a|b", "c;d"}, false},
+		{`echo "it's" 'say "hi"'`, []string{"echo", "it's", `say "hi"`}, false},
+		{`echo a\ b \"c\"`, []string{"echo", "a b", `"c"`}, false},
+		{`echo "a\"b\\c\d"`, []string{"echo", `a"b\c\d`}, false},
+		{`echo "" ''`, []string{"echo", "", ""}, false},
+		{`echo pre"mid"'post'`, []string{"echo", "premidpost"}, false},
+		{`echo a # comment`, []string{"echo", "a"}, false},
+		{`echo "unterminated`, nil, true},
+		{`echo 'unterminated`, nil, true},
+		{`echo a\`, nil, true},
+	}
+
+	for _, test := range tests {
+		tokens, err := tokenize(test.input)
+		if test.hasError {
+			if err == nil {
+				t.Errorf("Ожидалась ошибка для ввода %s, но её не произошло", test.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Не ожидалась ошибка для ввода %s, но произошла ошибка: %v", test.input, err)
+			continue
+		}
+		var words []string
+		for _, tok := range tokens {
+			if tok.kind == tokWord {
+				words = append(words, tok.word.literal())
+			}
+		}
+		if !reflect.DeepEqual(words, test.expected) {
+			t.Errorf("Для ввода %s ожидалось %q, но получено %q", test.input, test.expected, words)
+		}
+	}
+}
+
+func TestTokenizeQuoteKinds(t *testing.T) {
+	tokens, err := tokenize(`a"b"'c'\d`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := word{{"a", unquoted}, {"b", doubleQuoted}, {"c", singleQuoted}, {"d", singleQuoted}}
+	if !reflect.DeepEqual(tokens[0].word, expected) {
+		t.Errorf("Ожидалось %v, но получено %v", expected, tokens[0].word)
+	}
+}
+
+// literals возвращает слова без кавычек и без подстановок.
+func literals(words []word) []string {
+	var result []string
+	for _, w := range words {
+		result = append(result, w.literal())
+	}
+	return result
+}
+
+// shape описывает дерево разбора строкой, чтобы тесты были компактными.
+func shape(l *list) [][][]string {
+	var result [][][]string
+	for _, item := range l.items {
+		var ao [][]string
+		for i, pl := range item.pipelines {
+			if i > 0 {
+				ao = append(ao, []string{item.ops[i-1].String()})
+			}
+			var cmds []string
+			for j, cmd := range pl.commands {
+				if j > 0 {
+					cmds = append(cmds, "|")
+				}
+				cmds = append(cmds, literals(cmd.(*simpleCommand).args)...)
+			}
+			ao = append(ao, cmds)
+		}
+		result = append(result, ao)
+	}
+	return result
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected [][][]string
+		hasError bool
+	}{
+		{`ls -l`, [][][]string{{{"ls", "-l"}}}, false},
+		{`echo "a|b" | wc -c`, [][][]string{{{"echo", "a|b", "|", "wc", "-c"}}}, false},
+		{`cd /tmp; pwd`, [][][]string{{{"cd", "/tmp"}}, {{"pwd"}}}, false},
+		{`false || echo no && echo yes`, [][][]string{{{"false"}, {"||"}, {"echo", "no"}, {"&&"}, {"echo", "yes"}}}, false},
+		{"a |\n b", [][][]string{{{"a", "|", "b"}}}, false},
+		{"a\nb;", [][][]string{{{"a"}}, {{"b"}}}, false},
+		{`| a`, nil, true},
+		{`a |`, nil, true},
+		{`a && || b`, nil, true},
+		{`; a`, nil, true},
+		{`a ;; b`, nil, true},
+	}
+
+	for _, test := range tests {
+		tree, err := parse(test.input)
+		if test.hasError {
+			if err == nil {
+				t.Errorf("Ожидалась ошибка для ввода %q, но её не произошло", test.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Не ожидалась ошибка для ввода %q, но произошла ошибка: %v", test.input, err)
+			continue
+		}
+		if got := shape(tree); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("Для ввода %q ожидалось %q, но получено %q", test.input, test.expected, got)
+		}
+	}
+}
+
+func TestParseCompound(t *testing.T) {
+	tests := []struct {
+		input    string
+		check    func(command) bool
+		hasError bool
+	}{
+		{"if a; then b; elif c; then d; else e; fi", func(c command) bool {
+			clause, ok := c.(*ifClause)
+			return ok && len(clause.conds) == 2 && len(clause.bodies) == 2 && clause.elseBody != nil
+		}, false},
+		{"while a\ndo\n b\ndone < in", func(c command) bool {
+			loop, ok := c.(*loopClause)
+			return ok && !loop.until && len(loop.body.items) == 1 && len(loop.redirs) == 1
+		}, false},
+		{"until a; do b; done", func(c command) bool {
+			loop, ok := c.(*loopClause)
+			return ok && loop.until
+		}, false},
+		{"for x in a 'b c'; do echo $x; done", func(c command) bool {
+			loop, ok := c.(*forClause)
+			return ok && loop.name == "x" && loop.hasIn && reflect.DeepEqual(literals(loop.words), []string{"a", "b c"})
+		}, false},
+		{"for x do echo; done", func(c command) bool {
+			loop, ok := c.(*forClause)
+			return ok && !loop.hasIn
+		}, false},
+		{"f() { echo hi; }", func(c command) bool {
+			fn, ok := c.(*funcDef)
+			_, group := fn.body.(*braceGroup)
+			return ok && fn.name == "f" && group
+		}, false},
+		{"function g\n{ echo; }", func(c command) bool {
+			fn, ok := c.(*funcDef)
+			return ok && fn.name == "g"
+		}, false},
+		{"'if' a", func(c command) bool {
+			_, ok := c.(*simpleCommand)
+			return ok
+		}, false},
+		{"if a; then fi", nil, true},
+		{"if a; fi", nil, true},
+		{"while a; done", nil, true},
+		{"for 1 in a; do b; done", nil, true},
+		{"f() echo", nil, true},
+		{"echo a; fi", nil, true},
+	}
+
+	for _, test := range tests {
+		tree, err := parse(test.input)
+		if test.hasError {
+			if err == nil {
+				t.Errorf("Ожидалась ошибка для ввода %q, но её не произошло", test.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Не ожидалась ошибка для ввода %q, но произошла ошибка: %v", test.input, err)
+			continue
+		}
+		if !test.check(tree.items[0].pipelines[0].commands[0]) {
+			t.Errorf("Для ввода %q получено неверное дерево разбора", test.input)
+		}
+	}
+}
+
+func TestParseRedirects(t *testing.T) {
+	type redir struct {
+		fd     int
+		op     redirOp
+		target string
+		body   string
+	}
+	tests := []struct {
+		input    string
+		args     []string
+		expected []redir
+		hasError bool
+	}{
+		{`echo hi > out`, []string{"echo", "hi"}, []redir{{1, redirOut, "out", ""}}, false},
+		{`echo hi>>out`, []string{"echo", "hi"}, []redir{{1, redirAppend, "out", ""}}, false},
+		{`<in sort -r`, []string{"sort", "-r"}, []redir{{0, redirIn, "in", ""}}, false},
+		{`ls 2>err >out 2>&1`, []string{"ls"}, []redir{{2, redirOut, "err", ""}, {1, redirOut, "out", ""}, {2, redirDupOut, "1", ""}}, false},
+		{`echo 2 > f`, []string{"echo", "2"}, []redir{{1, redirOut, "f", ""}}, false},
+		{`echo ">" '2>'`, []string{"echo", ">", "2>"}, nil, false},
+		{"cat <<EOF\na\n  b\nEOF", []string{"cat"}, []redir{{0, redirHeredoc, "EOF", "a\n  b\n"}}, false},
+		{"cat <<-'X'\n\ta $b\n\tX\n", []string{"cat"}, []redir{{0, redirHeredoc, "X", "a $b\n"}}, false},
+		{`echo >`, nil, nil, true},
+		{`echo > | cat`, nil, nil, true},
+		{"cat <<EOF\na", nil, nil, true},
+	}
+
+	for _, test := range tests {
+		tree, err := parse(test.input)
+		if test.hasError {
+			if err == nil {
+				t.Errorf("Ожидалась ошибка для ввода %q, но её не произошло", test.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Не ожидалась ошибка для ввода %q, но произошла ошибка: %v", test.input, err)
+			continue
+		}
+		cmd := tree.items[0].pipelines[0].commands[0].(*simpleCommand)
+		var got []redir
+		for _, r := range cmd.redirs {
+			rd := redir{fd: r.fd, op: r.op, target: r.target.literal()}
+			if r.heredoc != nil {
+				rd.body = r.heredoc.body
+			}
+			got = append(got, rd)
+		}
+		if args := literals(cmd.args); !reflect.DeepEqual(args, test.args) {
+			t.Errorf("Для ввода %q ожидались аргументы %q, но получено %q", test.input, test.args, args)
+		}
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("Для ввода %q ожидалось %v, но получено %v", test.input, test.expected, got)
+		}
+	}
+}
+
+func TestIncompleteInput(t *testing.T) {
+	for input, expected := range map[string]bool{
+		`echo "a`:         true,
+		`a |`:             true,
+		`a &&`:            true,
+		"cat <<EOF\nbody": true,
+		"echo $(ls |":     true,
+		"echo ${X:-":      true,
+		"echo `date":      true,
+		"if a; then":      true,
+		"while a\ndo b":   true,
+		"f() {":           true,
+		"for x in a b":    true,
+		`echo a`:          false,
+		`| a`:             false,
+		`a; fi`:           false,
+	} {
+		if got := needsMoreInput(input); got != expected {
+			t.Errorf("Для ввода %q ожидалось %v, но получено %v", input, expected, got)
+		}
+	}
+}
+
+func TestParseAliases(t *testing.T) {
+	aliases := map[string]string{
+		"ll":    "ls -l",
+		"ls":    "ls -F",
+		"sudo":  "sudo ",
+		"both":  "echo a; echo b",
+		"pipe":  "grep x |",
+		"loop1": "loop2",
+		"loop2": "loop1 arg",
+		"bad":   "echo 'open",
+	}
+	alias := func(name string) (string, bool) {
+		value, ok := aliases[name]
+		return value, ok
+	}
+
+	tests := []struct {
+		input    string
+		expected [][][]string
+		hasError bool
+	}{
+		{`ll /tmp`, [][][]string{{{"ls", "-F", "-l", "/tmp"}}}, false},
+		{`echo ll`, [][][]string{{{"echo", "ll"}}}, false},
+		{`'ll' x`, [][][]string{{{"ll", "x"}}}, false},
+		{`true && ll`, [][][]string{{{"true"}, {"&&"}, {"ls", "-F", "-l"}}}, false},
+		{`echo | ll`, [][][]string{{{"echo", "|", "ls", "-F", "-l"}}}, false},
+		{`sudo ll`, [][][]string{{{"sudo", "ls", "-F", "-l"}}}, false},
+		{`both; ll`, [][][]string{{{"echo", "a"}}, {{"echo", "b"}}, {{"ls", "-F", "-l"}}}, false},
+		{`pipe wc`, [][][]string{{{"grep", "x", "|", "wc"}}}, false},
+		{`loop1`, [][][]string{{{"loop1", "arg"}}}, false},
+		{`bad`, nil, true},
+	}
+
+	for _, test := range tests {
+		tree, err := parseWithAliases(test.input, alias)
+		if test.hasError {
+			if err == nil {
+				t.Errorf("Ожидалась ошибка для ввода %q, но её не произошло", test.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Не ожидалась ошибка для ввода %q, но произошла ошибка: %v", test.input, err)
+			continue
+		}
+		if got := shape(tree); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("Для ввода %q ожидалось %q, но получено %q", test.input, test.expected, got)
+		}
+		if text := tree.items[0].text; !strings.HasPrefix(test.input, text) {
+			t.Errorf("Для ввода %q текст команды должен остаться исходным, но получено %q", test.input, text)
+		}
+	}
+
+	tree, err := parseWithAliases("if true; then ll; fi", alias)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := tree.items[0].pipelines[0].commands[0].(*ifClause).bodies[0]
+	if got := literals(body.items[0].pipelines[0].commands[0].(*simpleCommand).args); !reflect.DeepEqual(got, []string{"ls", "-F", "-l"}) {
+		t.Errorf("Алиас в теле if должен раскрываться, но получено %q", got)
+	}
+}
@@ -0,0 +1,198 @@
+package shell
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// clockTicks — единица времени в /proc/[pid]/stat (USER_HZ); в Linux всегда 100.
+const clockTicks = 100
+
+// procInfo — сведения о процессе из /proc/[pid]/stat, status и cmdline.
+type procInfo struct {
+	pid, ppid, pgid, sid int
+	tty                  int // номер устройства терминала (tty_nr), 0 — нет терминала
+	state                byte
+	comm                 string   // имя программы (не более 15 символов)
+	cmdline              []string // пусто у потоков ядра и зомби
+	uid                  int
+	user                 string
+	rss, vsz             int64  // КиБ
+	cpuTicks             uint64 // utime + stime
+	startTicks           uint64 // время запуска от загрузки системы
+}
+
+// procFS читает процессы из каталога root; обычно это /proc, в тестах — поддельное дерево.
+type procFS struct {
+	root string
+}
+
+var (
+	userNamesMu sync.Mutex
+	userNames   = make(map[int]string)
+)
+
+// userName возвращает имя пользователя uid или сам uid, если имени нет (нет /etc/passwd).
+func userName(uid int) string {
+	userNamesMu.Lock()
+	defer userNamesMu.Unlock()
+	if name, ok := userNames[uid]; ok {
+		return name
+	}
+	name := strconv.Itoa(uid)
+	if u, err := user.LookupId(name); err == nil {
+		name = u.Username
+	}
+	userNames[uid] = name
+	return name
+}
+
+// processes возвращает все процессы по возрастанию pid. Процессы, завершившиеся
+// во время чтения, пропускаются.
+func (fs procFS) processes() ([]*procInfo, error) {
+	entries, err := os.ReadDir(fs.root)
+	if err != nil {
+		return nil, err
+	}
+	var procs []*procInfo
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+		if p, err := fs.process(pid); err == nil {
+			procs = append(procs, p)
+		}
+	}
+	sort.Slice(procs, func(i, j int) bool { return procs[i].pid < procs[j].pid })
+	return procs, nil
+}
+
+// process читает сведения о процессе pid.
+func (fs procFS) process(pid int) (*procInfo, error) {
+	dir := filepath.Join(fs.root, strconv.Itoa(pid))
+	stat, err := os.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return nil, err
+	}
+	p, err := parseStat(stat)
+	if err != nil {
+		return nil, fmt.Errorf("%s/stat: %w", dir, err)
+	}
+
+	if status, err := os.ReadFile(filepath.Join(dir, "status")); err == nil {
+		parseStatus(p, status)
+	}
+	p.user = userName(p.uid)
+
+	if cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline")); err == nil && len(cmdline) > 0 {
+		p.cmdline = strings.Split(strings.TrimRight(string(cmdline), "\x00"), "\x00")
+	}
+	return p, nil
+}
+
+// parseStat разбирает /proc/[pid]/stat: "pid (comm) state ppid pgrp session tty_nr ...".
+// comm может содержать пробелы и скобки, поэтому ищется последняя ')'.
+func parseStat(data []byte) (*procInfo, error) {
+	open, close := bytes.IndexByte(data, '('), bytes.LastIndexByte(data, ')')
+	if open < 0 || close < open {
+		return nil, fmt.Errorf("malformed stat")
+	}
+	p := &procInfo{comm: string(data[open+1 : close])}
+	var err error
+	if p.pid, err = strconv.Atoi(strings.TrimSpace(string(data[:open]))); err != nil {
+		return nil, fmt.Errorf("malformed pid: %w", err)
+	}
+
+	// Поля после comm, начиная с state (поле 3 в proc(5)).
+	fields := strings.Fields(string(data[close+1:]))
+	if len(fields) < 22 {
+		return nil, fmt.Errorf("too few fields")
+	}
+	p.state = fields[0][0]
+	ints := make([]int64, len(fields))
+	for i, f := range fields[1:22] {
+		if ints[i+1], err = strconv.ParseInt(f, 10, 64); err != nil {
+			return nil, fmt.Errorf("field %d: %w", i+4, err)
+		}
+	}
+	p.ppid, p.pgid, p.sid, p.tty = int(ints[1]), int(ints[2]), int(ints[3]), int(ints[4])
+	p.cpuTicks = uint64(ints[11] + ints[12])
+	p.startTicks = uint64(ints[19])
+	p.vsz = ints[20] / 1024
+	return p, nil
+}
+
+// parseStatus берёт из /proc/[pid]/status реальный UID и VmRSS.
+func parseStatus(p *procInfo, data []byte) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+		switch key {
+		case "Uid":
+			p.uid, _ = strconv.Atoi(fields[0])
+		case "VmRSS":
+			p.rss, _ = strconv.ParseInt(fields[0], 10, 64)
+		}
+	}
+}
+
+// bootTime возвращает время загрузки системы (btime из /proc/stat) в секундах Unix.
+func (fs procFS) bootTime() (int64, error) {
+	data, err := os.ReadFile(filepath.Join(fs.root, "stat"))
+	if err != nil {
+		return 0, err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if value, ok := strings.CutPrefix(line, "btime "); ok {
+			return strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		}
+	}
+	return 0, fmt.Errorf("btime not found in %s/stat", fs.root)
+}
+
+// ttyName переводит номер устройства из tty_nr в имя терминала: pts/N, ttyN или ?.
+func ttyName(dev int) string {
+	major, minor := (dev>>8)&0xfff, (dev&0xff)|((dev>>12)&0xfff00)
+	switch {
+	case dev == 0:
+		return "?"
+	case major >= 136 && major <= 143:
+		return fmt.Sprintf("pts/%d", (major-136)*256+minor)
+	case major == 4 && minor < 64:
+		return fmt.Sprintf("tty%d", minor)
+	case major == 4:
+		return fmt.Sprintf("ttyS%d", minor-64)
+	}
+	return "?"
+}
+
+// command возвращает командную строку процесса; у потоков ядра — [comm].
+// Непечатаемые символы заменяются на ?, чтобы не ломать вывод.
+func (p *procInfo) command() string {
+	if len(p.cmdline) == 0 {
+		return "[" + p.comm + "]"
+	}
+	return strings.Map(func(r rune) rune {
+		if !unicode.IsPrint(r) {
+			return '?'
+		}
+		return r
+	}, strings.Join(p.cmdline, " "))
+}
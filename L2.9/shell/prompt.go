@@ -0,0 +1,146 @@
+package shell
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Приглашения по умолчанию, если PS1 и PS2 не заданы.
+const (
+	defaultPS1 = "> "
+	defaultPS2 = "... "
+)
+
+// prompt возвращает приглашение из переменной name (PS1 или PS2) с раскрытыми
+// escape-последовательностями:
+//
+//	\u — пользователь, \h — имя хоста до первой точки, \H — полное имя хоста,
+//	\w — текущий каталог (домашний — ~), \W — последний элемент текущего каталога,
+//	\? — код завершения последней команды, \g — ветка git или сокращённый коммит,
+//	\$ — # у root, иначе $, \n — перевод строки, \e — ESC для цветов, \\ — обратная
+//	косая черта. \[ и \] (границы непечатаемых символов в bash) пропускаются.
+//
+// Подстановки $VAR и $(cmd) в приглашении не выполняются: имя каталога в \w
+// не должно исполняться как команда.
+func (sh *shell) prompt(name, def string) string {
+	ps, ok := sh.getVar(name)
+	if !ok {
+		return def
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(ps); i++ {
+		if ps[i] != '\\' || i+1 == len(ps) {
+			sb.WriteByte(ps[i])
+			continue
+		}
+		i++
+		switch ps[i] {
+		case 'u':
+			sb.WriteString(sh.currentUser())
+		case 'h':
+			host, _ := os.Hostname()
+			host, _, _ = strings.Cut(host, ".")
+			sb.WriteString(host)
+		case 'H':
+			host, _ := os.Hostname()
+			sb.WriteString(host)
+		case 'w':
+			sb.WriteString(sh.promptDir(false))
+		case 'W':
+			sb.WriteString(sh.promptDir(true))
+		case '?':
+			sh.mu.Lock()
+			sb.WriteString(strconv.Itoa(sh.lastStatus))
+			sh.mu.Unlock()
+		case 'g':
+			sb.WriteString(gitBranch(sh.cwd()))
+		case '$':
+			if os.Geteuid() == 0 {
+				sb.WriteByte('#')
+			} else {
+				sb.WriteByte('$')
+			}
+		case 'n':
+			sb.WriteByte('\n')
+		case 'e':
+			sb.WriteByte('\x1b')
+		case '\\':
+			sb.WriteByte('\\')
+		case '[', ']':
+		default:
+			sb.WriteByte('\\')
+			sb.WriteByte(ps[i])
+		}
+	}
+	return sb.String()
+}
+
+// currentUser возвращает имя пользователя из $USER, а без неё — из базы пользователей.
+func (sh *shell) currentUser() string {
+	if name, ok := sh.getVar("USER"); ok && name != "" {
+		return name
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return strconv.Itoa(os.Getuid())
+}
+
+// promptDir возвращает текущий каталог, заменяя домашний на ~; base — только последний элемент.
+func (sh *shell) promptDir(base bool) string {
+	dir := sh.cwd()
+	home, _ := sh.getVar("HOME")
+	home = strings.TrimSuffix(home, "/")
+	switch {
+	case home != "" && dir == home:
+		return "~"
+	case base:
+		return filepath.Base(dir)
+	case home != "" && strings.HasPrefix(dir, home+"/"):
+		return "~" + dir[len(home):]
+	}
+	return dir
+}
+
+// gitBranch ищет репозиторий git от каталога dir вверх и возвращает текущую ветку,
+// а в состоянии detached HEAD — первые 7 символов коммита. Вне репозитория — "".
+func gitBranch(dir string) string {
+	for {
+		gitDir := filepath.Join(dir, ".git")
+		if info, err := os.Stat(gitDir); err == nil {
+			if !info.IsDir() {
+				// Рабочее дерево git worktree или подмодуль: файл .git с "gitdir: путь".
+				data, err := os.ReadFile(gitDir)
+				if err != nil {
+					return ""
+				}
+				path, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+				if !ok {
+					return ""
+				}
+				if !filepath.IsAbs(path) {
+					path = filepath.Join(dir, path)
+				}
+				gitDir = path
+			}
+			head, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+			if err != nil {
+				return ""
+			}
+			ref := strings.TrimSpace(string(head))
+			if branch, ok := strings.CutPrefix(ref, "ref: refs/heads/"); ok {
+				return branch
+			}
+			return ref[:min(7, len(ref))]
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,76 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPrompt(t *testing.T) {
+	home := t.TempDir()
+	project := filepath.Join(home, "project")
+	os.MkdirAll(filepath.Join(project, ".git"), 0o755)
+	os.WriteFile(filepath.Join(project, ".git", "HEAD"), []byte("ref: refs/heads/feature/x\n"), 0o644)
+	os.MkdirAll(filepath.Join(project, "src"), 0o755)
+	wd, _ := os.Getwd()
+	os.Chdir(filepath.Join(project, "src"))
+	defer os.Chdir(wd)
+
+	sh := newShell()
+	sh.setVar("HOME", home)
+	sh.setVar("USER", "gopher")
+	sh.setStatus(3)
+	host, _ := os.Hostname()
+	short, _, _ := strings.Cut(host, ".")
+	sign := "$"
+	if os.Geteuid() == 0 {
+		sign = "#"
+	}
+
+	tests := []struct {
+		ps1      string
+		expected string
+	}{
+		{`\u@\h:\w\$ `, "gopher@" + short + ":~/project/src" + sign + " "},
+		{`\W [\?] (\g)`, "src [3] (feature/x)"},
+		{`\[\e[32m\]\H\[\e[0m\]\n> `, "\x1b[32m" + host + "\x1b[0m\n> "},
+		{`\\ \x $HOME $(echo no)`, `\ \x $HOME $(echo no)`},
+		{`tail\`, `tail\`},
+	}
+	for _, test := range tests {
+		sh.setVar("PS1", test.ps1)
+		if got := sh.prompt("PS1", defaultPS1); got != test.expected {
+			t.Errorf("Для PS1=%q ожидалось %q, но получено %q", test.ps1, test.expected, got)
+		}
+	}
+
+	sh.unsetVar("PS1")
+	if got := sh.prompt("PS1", defaultPS1); got != defaultPS1 {
+		t.Errorf("Без PS1 ожидалось %q, но получено %q", defaultPS1, got)
+	}
+}
+
+func TestGitBranch(t *testing.T) {
+	dir := t.TempDir()
+	if got := gitBranch(dir); got != "" {
+		t.Errorf("Вне репозитория ожидалась пустая строка, но получено %q", got)
+	}
+
+	repo := filepath.Join(dir, "repo")
+	os.MkdirAll(filepath.Join(repo, ".git"), 0o755)
+	os.WriteFile(filepath.Join(repo, ".git", "HEAD"), []byte("0123456789abcdef\n"), 0o644)
+	if got := gitBranch(repo); got != "0123456" {
+		t.Errorf("Для detached HEAD ожидалось %q, но получено %q", "0123456", got)
+	}
+
+	// Рабочее дерево git worktree: .git — файл со ссылкой на каталог репозитория.
+	worktree := filepath.Join(dir, "wt")
+	os.MkdirAll(filepath.Join(repo, ".git", "worktrees", "wt"), 0o755)
+	os.WriteFile(filepath.Join(repo, ".git", "worktrees", "wt", "HEAD"), []byte("ref: refs/heads/wt\n"), 0o644)
+	os.MkdirAll(filepath.Join(worktree, "deep"), 0o755)
+	os.WriteFile(filepath.Join(worktree, ".git"), []byte("gitdir: ../repo/.git/worktrees/wt\n"), 0o644)
+	if got := gitBranch(filepath.Join(worktree, "deep")); got != "wt" {
+		t.Errorf("Для worktree ожидалось %q, но получено %q", "wt", got)
+	}
+}
@@ -0,0 +1,260 @@
+package shell
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// psColumn — столбец вывода ps: заголовок, значение и порядок сортировки.
+type psColumn struct {
+	header  string
+	right   bool // числа выравниваются по правому краю
+	value   func(p *procInfo, ctx *psContext) string
+	compare func(a, b *procInfo) int
+}
+
+// psContext — данные, общие для всех строк вывода.
+type psContext struct {
+	boot int64 // время загрузки системы, Unix
+	now  time.Time
+}
+
+func intColumn(header string, get func(p *procInfo) int64) psColumn {
+	return psColumn{
+		header:  header,
+		right:   true,
+		value:   func(p *procInfo, _ *psContext) string { return strconv.FormatInt(get(p), 10) },
+		compare: func(a, b *procInfo) int { return cmp.Compare(get(a), get(b)) },
+	}
+}
+
+func textColumn(header string, get func(p *procInfo) string) psColumn {
+	return psColumn{
+		header:  header,
+		value:   func(p *procInfo, _ *psContext) string { return get(p) },
+		compare: func(a, b *procInfo) int { return strings.Compare(get(a), get(b)) },
+	}
+}
+
+// psColumns — столбцы, доступные в -o и --sort; имена как в procps.
+var psColumns = map[string]psColumn{
+	"pid":  intColumn("PID", func(p *procInfo) int64 { return int64(p.pid) }),
+	"ppid": intColumn("PPID", func(p *procInfo) int64 { return int64(p.ppid) }),
+	"pgid": intColumn("PGID", func(p *procInfo) int64 { return int64(p.pgid) }),
+	"sid":  intColumn("SID", func(p *procInfo) int64 { return int64(p.sid) }),
+	"uid":  intColumn("UID", func(p *procInfo) int64 { return int64(p.uid) }),
+	"rss":  intColumn("RSS", func(p *procInfo) int64 { return p.rss }),
+	"vsz":  intColumn("VSZ", func(p *procInfo) int64 { return p.vsz }),
+	"user": textColumn("USER", func(p *procInfo) string { return p.user }),
+	"stat": textColumn("S", func(p *procInfo) string { return string(p.state) }),
+	"tty":  textColumn("TTY", func(p *procInfo) string { return ttyName(p.tty) }),
+	"comm": textColumn("COMMAND", func(p *procInfo) string { return p.comm }),
+	"cmd":  textColumn("CMD", (*procInfo).command),
+	"args": textColumn("COMMAND", (*procInfo).command),
+	"time": {
+		header: "TIME",
+		right:  true,
+		value: func(p *procInfo, _ *psContext) string {
+			s := p.cpuTicks / clockTicks
+			return fmt.Sprintf("%02d:%02d:%02d", s/3600, s/60%60, s%60)
+		},
+		compare: func(a, b *procInfo) int { return cmp.Compare(a.cpuTicks, b.cpuTicks) },
+	},
+	"stime": {
+		header: "STIME",
+		value: func(p *procInfo, ctx *psContext) string {
+			start := time.Unix(ctx.boot+int64(p.startTicks/clockTicks), 0)
+			if y, m, d := start.Date(); y == ctx.now.Year() && m == ctx.now.Month() && d == ctx.now.Day() {
+				return start.Format("15:04")
+			}
+			return start.Format("Jan02")
+		},
+		compare: func(a, b *procInfo) int { return cmp.Compare(a.startTicks, b.startTicks) },
+	},
+}
+
+// Наборы столбцов по умолчанию, как у ps и ps -f.
+const (
+	psDefaultFormat = "pid,tty,time,comm"
+	psFullFormat    = "user,pid,ppid,stime,tty,time,cmd"
+)
+
+// psOptions — разобранные аргументы ps.
+type psOptions struct {
+	all     bool
+	full    bool
+	columns []string
+	sort    []string // ключи сортировки; "-" в начале — по убыванию
+}
+
+func parsePsArgs(args []string) (*psOptions, error) {
+	opts := &psOptions{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		value := func(name string) (string, error) {
+			if v, ok := strings.CutPrefix(arg, name+"="); ok {
+				return v, nil
+			}
+			if len(arg) > len(name) && !strings.HasPrefix(arg, "--") {
+				return arg[len(name):], nil
+			}
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("option %s requires an argument", name)
+			}
+			i++
+			return args[i], nil
+		}
+
+		switch {
+		case arg == "--sort" || strings.HasPrefix(arg, "--sort="):
+			keys, err := value("--sort")
+			if err != nil {
+				return nil, err
+			}
+			opts.sort = append(opts.sort, strings.Split(keys, ",")...)
+		case strings.HasPrefix(arg, "-o"):
+			cols, err := value("-o")
+			if err != nil {
+				return nil, err
+			}
+			opts.columns = append(opts.columns, strings.Split(cols, ",")...)
+		case strings.HasPrefix(arg, "-") && len(arg) > 1 && !strings.HasPrefix(arg, "--"):
+			// Короткие флаги можно объединять: -ef.
+			for _, c := range arg[1:] {
+				switch c {
+				case 'e', 'A':
+					opts.all = true
+				case 'f':
+					opts.full = true
+				default:
+					return nil, fmt.Errorf("unknown option -%c", c)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("unknown argument %s", arg)
+		}
+	}
+
+	if opts.columns == nil {
+		format := psDefaultFormat
+		if opts.full {
+			format = psFullFormat
+		}
+		opts.columns = strings.Split(format, ",")
+	}
+	for _, name := range opts.columns {
+		if _, ok := psColumns[name]; !ok {
+			return nil, fmt.Errorf("unknown column %s", name)
+		}
+	}
+	for _, key := range opts.sort {
+		if _, ok := psColumns[strings.TrimLeft(key, "+-")]; !ok {
+			return nil, fmt.Errorf("unknown sort key %s", key)
+		}
+	}
+	return opts, nil
+}
+
+// builtinPs показывает процессы, читая /proc напрямую, без внешней программы ps.
+//
+//	ps                   — процессы текущего терминала
+//	ps -e                — все процессы
+//	ps -f                — полный формат: USER PID PPID STIME TTY TIME CMD
+//	ps -o pid,ppid,user,rss,cmd  — выбор столбцов
+//	ps --sort=-rss,pid   — сортировка, - означает по убыванию
+func builtinPs(sh *shell, args []string, s streams) int {
+	return runPs(procFS{root: "/proc"}, os.Getpid(), args[1:], s.stdout, s.stderr)
+}
+
+// runPs выполняет ps над деревом fs; self — процесс, чей терминал считается текущим.
+func runPs(fs procFS, self int, args []string, stdout, stderr io.Writer) int {
+	opts, err := parsePsArgs(args)
+	if err != nil {
+		fmt.Fprintln(stderr, "ps:", err)
+		return 2
+	}
+	procs, err := fs.processes()
+	if err != nil {
+		fmt.Fprintln(stderr, "ps:", err)
+		return 1
+	}
+
+	if !opts.all {
+		// Как и ps без флагов: процессы того же пользователя на том же терминале.
+		me, err := fs.process(self)
+		if err != nil {
+			fmt.Fprintln(stderr, "ps:", err)
+			return 1
+		}
+		procs = slices.DeleteFunc(procs, func(p *procInfo) bool {
+			return p.tty != me.tty || p.uid != me.uid
+		})
+	}
+
+	slices.SortStableFunc(procs, func(a, b *procInfo) int {
+		for _, key := range opts.sort {
+			name := strings.TrimLeft(key, "+-")
+			c := psColumns[name].compare(a, b)
+			if strings.HasPrefix(key, "-") {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return cmp.Compare(a.pid, b.pid)
+	})
+
+	ctx := &psContext{now: time.Now()}
+	ctx.boot, _ = fs.bootTime()
+	rows := [][]string{make([]string, len(opts.columns))}
+	for i, name := range opts.columns {
+		rows[0][i] = psColumns[name].header
+	}
+	for _, p := range procs {
+		row := make([]string, len(opts.columns))
+		for i, name := range opts.columns {
+			row[i] = psColumns[name].value(p, ctx)
+		}
+		rows = append(rows, row)
+	}
+	writeTable(stdout, rows, func(col int) bool { return psColumns[opts.columns[col]].right })
+	return 0
+}
+
+// writeTable печатает строки, выравнивая столбцы по ширине; последний столбец
+// не дополняется пробелами, чтобы длинные командные строки не тянули за собой хвост.
+func writeTable(w io.Writer, rows [][]string, right func(col int) bool) {
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+	var sb strings.Builder
+	for _, row := range rows {
+		for i, cell := range row {
+			pad := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+			switch {
+			case right(i):
+				sb.WriteString(pad + cell)
+			case i < len(row)-1:
+				sb.WriteString(cell + pad)
+			default:
+				sb.WriteString(cell)
+			}
+			if i < len(row)-1 {
+				sb.WriteByte(' ')
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	io.WriteString(w, sb.String())
+}
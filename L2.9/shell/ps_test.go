@@ -0,0 +1,135 @@
+package shell
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeProc описывает процесс поддельного /proc.
+type fakeProc struct {
+	pid, ppid, pgid int
+	comm            string
+	tty             int
+	uid             int
+	rss             int
+	ticks           int
+	cmdline         string // аргументы через \x00
+}
+
+// makeFakeProc создаёт поддельное дерево /proc с процессами procs.
+func makeFakeProc(t *testing.T, procs []fakeProc) procFS {
+	t.Helper()
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "stat"), []byte("cpu  1 2 3\nbtime 1700000000\n"), 0o644)
+	os.Mkdir(filepath.Join(root, "sys"), 0o755)
+	for _, p := range procs {
+		dir := filepath.Join(root, fmt.Sprint(p.pid))
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		stat := fmt.Sprintf("%d (%s) S %d %d %d %d -1 4194304 100 0 0 0 %d 0 0 0 20 0 1 0 500 %d 10 18446744073709551615\n",
+			p.pid, p.comm, p.ppid, p.pgid, p.pgid, p.tty, p.ticks, 4096*1024)
+		status := fmt.Sprintf("Name:\t%s\nState:\tS (sleeping)\nUid:\t%d\t%d\t%d\t%d\nVmRSS:\t%8d kB\n",
+			p.comm, p.uid, p.uid, p.uid, p.uid, p.rss)
+		os.WriteFile(filepath.Join(dir, "stat"), []byte(stat), 0o644)
+		os.WriteFile(filepath.Join(dir, "status"), []byte(status), 0o644)
+		os.WriteFile(filepath.Join(dir, "cmdline"), []byte(p.cmdline), 0o644)
+	}
+	return procFS{root: root}
+}
+
+func TestParseStat(t *testing.T) {
+	p, err := parseStat([]byte("42 (my (odd) prog) R 1 42 40 34816 42 0 0 0 0 0 250 50 0 0 20 0 1 0 1234 8192000 100 0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.pid != 42 || p.comm != "my (odd) prog" || p.state != 'R' || p.ppid != 1 || p.pgid != 42 || p.sid != 40 {
+		t.Errorf("Неверно разобраны поля: %+v", p)
+	}
+	if p.cpuTicks != 300 || p.startTicks != 1234 || p.vsz != 8000 || ttyName(p.tty) != "pts/0" {
+		t.Errorf("Неверно разобраны время, память или терминал: %+v", p)
+	}
+
+	for _, bad := range []string{"", "42 prog R 1", "42 (prog) R 1 2 3"} {
+		if _, err := parseStat([]byte(bad)); err == nil {
+			t.Errorf("Ожидалась ошибка для %q", bad)
+		}
+	}
+}
+
+func TestPs(t *testing.T) {
+	const pts1 = 136<<8 | 1
+	fs := makeFakeProc(t, []fakeProc{
+		{pid: 1, ppid: 0, pgid: 1, comm: "init", uid: 0, rss: 1000, ticks: 12345, cmdline: "/sbin/init\x00"},
+		{pid: 2, ppid: 0, pgid: 0, comm: "kthreadd", uid: 0},
+		{pid: 300, ppid: 1, pgid: 300, comm: "l2sh", tty: pts1, uid: 54321, rss: 5000, cmdline: "l2sh\x00"},
+		{pid: 301, ppid: 300, pgid: 301, comm: "sleep", tty: pts1, uid: 54321, rss: 700, cmdline: "sleep\x0010\x00"},
+		{pid: 302, ppid: 300, pgid: 301, comm: "cat", tty: pts1, uid: 0, rss: 900, cmdline: "cat\x00"},
+	})
+
+	tests := []struct {
+		args     []string
+		expected string
+		status   int
+	}{
+		{nil, "" +
+			"PID TTY       TIME COMMAND\n" +
+			"300 pts/1 00:00:00 l2sh\n" +
+			"301 pts/1 00:00:00 sleep\n", 0},
+		{[]string{"-e", "-o", "pid,ppid,user,rss,cmd"}, "" +
+			"PID PPID USER   RSS CMD\n" +
+			"  1    0 root  1000 /sbin/init\n" +
+			"  2    0 root     0 [kthreadd]\n" +
+			"300    1 54321 5000 l2sh\n" +
+			"301  300 54321  700 sleep 10\n" +
+			"302  300 root   900 cat\n", 0},
+		{[]string{"-e", "-opid,rss", "--sort=-rss"}, "" +
+			"PID  RSS\n" +
+			"300 5000\n" +
+			"  1 1000\n" +
+			"302  900\n" +
+			"301  700\n" +
+			"  2    0\n", 0},
+		{[]string{"-A", "-o", "pgid,pid", "--sort", "-pgid,pid"}, "" +
+			"PGID PID\n" +
+			" 301 301\n" +
+			" 301 302\n" +
+			" 300 300\n" +
+			"   1   1\n" +
+			"   0   2\n", 0},
+		{[]string{"-ef"}, "" +
+			"USER  PID PPID STIME TTY       TIME CMD\n", 0},
+		{[]string{"-e", "-o", "comm,time", "--sort=comm"}, "" +
+			"COMMAND      TIME\n" +
+			"cat      00:00:00\n" +
+			"init     00:02:03\n" +
+			"kthreadd 00:00:00\n" +
+			"l2sh     00:00:00\n" +
+			"sleep    00:00:00\n", 0},
+		{[]string{"-x"}, "", 2},
+		{[]string{"-o", "bogus"}, "", 2},
+		{[]string{"--sort=bogus"}, "", 2},
+		{[]string{"-o"}, "", 2},
+	}
+
+	for _, test := range tests {
+		var stdout, stderr bytes.Buffer
+		status := runPs(fs, 300, test.args, &stdout, &stderr)
+		if status != test.status {
+			t.Errorf("Для %q ожидался код %d, но получен %d (%s)", test.args, test.status, status, stderr.String())
+		}
+		got := stdout.String()
+		if len(test.args) > 0 && test.args[0] == "-ef" {
+			// STIME зависит от часового пояса, проверяется только заголовок.
+			got, _, _ = strings.Cut(got, "\n")
+			got += "\n"
+		}
+		if got != test.expected {
+			t.Errorf("Для %q ожидалось:\n%s\nно получено:\n%s", test.args, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package shell
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// streams — стандартные потоки команды (дескрипторы 0, 1 и 2) после применения
+// перенаправлений. Встроенные команды пишут в них, внешним они передаются через exec.Cmd.
+// env — окружение команды; nil означает окружение процесса шелла.
+// ctl — состояние exit, return, break и continue для команд того же уровня.
+// job — задание, в котором выполняются вложенные конвейеры (тело цикла в фоне);
+// nil означает, что каждый конвейер становится заданием переднего плана.
+type streams struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	env    []string
+	ctl    *control
+	job    *job
+}
+
+// get возвращает поток, открытый на дескрипторе fd.
+func (s *streams) get(fd int) (any, error) {
+	switch fd {
+	case 0:
+		return s.stdin, nil
+	case 1:
+		return s.stdout, nil
+	case 2:
+		return s.stderr, nil
+	}
+	return nil, fmt.Errorf("%d: bad file descriptor", fd)
+}
+
+// set открывает поток v на дескрипторе fd. Дескриптор 0 должен быть открыт на чтение,
+// 1 и 2 — на запись.
+func (s *streams) set(fd int, v any) error {
+	switch fd {
+	case 0:
+		if r, ok := v.(io.Reader); ok {
+			s.stdin = r
+			return nil
+		}
+	case 1, 2:
+		w, ok := v.(io.Writer)
+		if !ok {
+			break
+		}
+		if fd == 1 {
+			s.stdout = w
+		} else {
+			s.stderr = w
+		}
+		return nil
+	}
+	return fmt.Errorf("%d: bad file descriptor", fd)
+}
+
+// applyRedirects применяет перенаправления к base слева направо. В именах файлов
+// и в here-doc с разделителем без кавычек подставляются переменные.
+// Возвращаемая функция закрывает открытые файлы, её нужно вызвать после завершения команды.
+func (sh *shell) applyRedirects(redirs []*redirect, base streams) (streams, func(), error) {
+	s := base
+	var files []*os.File
+	closeFiles := func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}
+
+	for _, r := range redirs {
+		// Дескриптор проверяется до открытия файла: иначе `cmd 3> file`
+		// обрезал бы file и только потом сообщил об ошибке.
+		if _, err := s.get(r.fd); err != nil {
+			closeFiles()
+			return base, func() {}, err
+		}
+		e := sh.expander()
+		target := e.expandNoSplit(r.target)
+		err := e.err
+		if err != nil {
+			closeFiles()
+			return base, func() {}, err
+		}
+		switch r.op {
+		case redirIn, redirOut, redirAppend:
+			if sh.restricted != nil {
+				if err = sh.restricted.checkRedirect(r.op, sh.path(target)); err != nil {
+					sh.audit("denied", []string{map[redirOp]string{redirIn: "<", redirOut: ">", redirAppend: ">>"}[r.op], target}, err)
+					break
+				}
+			}
+			var f *os.File
+			if f, err = openRedirectFile(sh.path(target), r.op); err == nil {
+				files = append(files, f)
+				err = s.set(r.fd, f)
+			} else if pathErr, ok := err.(*os.PathError); ok {
+				pathErr.Path = target // в сообщении — имя, как его написал пользователь
+			}
+		case redirDupIn, redirDupOut:
+			var fd int
+			if fd, err = strconv.Atoi(target); err != nil {
+				err = fmt.Errorf("%s: ambiguous redirect", target)
+				break
+			}
+			var v any
+			if v, err = s.get(fd); err == nil {
+				err = s.set(r.fd, v)
+			}
+		case redirHeredoc:
+			body := r.heredoc.body
+			if r.heredoc.expand {
+				body = e.expandText(body)
+				if err = e.err; err != nil {
+					break
+				}
+			}
+			err = s.set(r.fd, strings.NewReader(body))
+		}
+		if err != nil {
+			closeFiles()
+			return base, func() {}, err
+		}
+	}
+	return s, closeFiles, nil
+}
+
+func openRedirectFile(path string, op redirOp) (*os.File, error) {
+	switch op {
+	case redirOut:
+		return os.Create(path)
+	case redirAppend:
+		return os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o666)
+	}
+	return os.Open(path)
+}
@@ -0,0 +1,64 @@
+package shell
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApplyRedirects(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	if err := os.WriteFile(out, []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(input string) string {
+		t.Helper()
+		tree, err := parse(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var stdout, stderr bytes.Buffer
+		base := streams{stdin: bytes.NewReader(nil), stdout: &stdout, stderr: &stderr}
+		if status := newShell().runSimpleCommand(tree.items[0].pipelines[0].commands[0].(*simpleCommand), base); status != 0 {
+			t.Fatalf("Команда %q завершилась с кодом %d: %s", input, status, stderr.String())
+		}
+		return stdout.String()
+	}
+
+	run("echo first > " + out)
+	run("echo second >> " + out)
+	if data, _ := os.ReadFile(out); string(data) != "first\nsecond\n" {
+		t.Errorf("Ожидалось содержимое %q, но получено %q", "first\nsecond\n", data)
+	}
+	if got := run("echo to-stderr 1>&2 2>" + out); got != "" {
+		t.Errorf("Ожидался пустой stdout, но получено %q", got)
+	}
+
+	sh := newShell()
+	tree, _ := parse("cat <<EOF\nhello\nEOF")
+	s, closeFiles, err := sh.applyRedirects(tree.items[0].pipelines[0].commands[0].(*simpleCommand).redirs, sh.stdStreams())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeFiles()
+	if data, _ := io.ReadAll(s.stdin); string(data) != "hello\n" {
+		t.Errorf("Ожидалось тело here-doc %q, но получено %q", "hello\n", data)
+	}
+
+	if err := os.WriteFile(out, []byte("important\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := sh.applyRedirects([]*redirect{{fd: 3, op: redirOut, target: word{{text: out}}}}, sh.stdStreams()); err == nil {
+		t.Error("Ожидалась ошибка для дескриптора 3, но её не произошло")
+	}
+	if data, _ := os.ReadFile(out); string(data) != "important\n" {
+		t.Errorf("Файл не должен обрезаться при ошибке дескриптора, но получено %q", data)
+	}
+	if _, _, err := sh.applyRedirects([]*redirect{{fd: 0, op: redirIn, target: word{{text: filepath.Join(dir, "missing")}}}}, sh.stdStreams()); err == nil {
+		t.Error("Ожидалась ошибка для несуществующего файла, но её не произошло")
+	}
+}
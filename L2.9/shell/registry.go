@@ -0,0 +1,237 @@
+package shell
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Stdio — потоки встроенной команды после перенаправлений. Поля те же, что у
+// cli.Stdio утилит L2, поэтому одно значение переводится в другое преобразованием типа.
+type Stdio struct {
+	In  io.Reader
+	Out io.Writer
+	Err io.Writer
+}
+
+// IO — потоки и окружение, с которыми выполняется встроенная команда.
+type IO struct {
+	Stdio
+	Dir string   // текущий каталог шелла: от него считаются относительные пути
+	Env []string // окружение команды в виде NAME=value
+
+	// Состояние шелла и потоки команды для стандартных встроенных команд (coreBuiltin).
+	sh      *shell
+	streams streams
+}
+
+// Path переводит путь name относительно каталога шелла в абсолютный.
+func (c IO) Path(name string) string {
+	if filepath.IsAbs(name) || c.Dir == "" {
+		return name
+	}
+	return filepath.Join(c.Dir, name)
+}
+
+// Getenv возвращает значение переменной окружения команды.
+func (c IO) Getenv(name string) string {
+	return envValue(c.Env, name)
+}
+
+// Builtin — встроенная команда на Go. Она выполняется в процессе шелла, без запуска
+// программы, поэтому работает и там, где нет coreutils, и может быть стадией конвейера.
+// Каталог процесса у шелла не меняется, поэтому пути нужно переводить через IO.Path.
+type Builtin interface {
+	// Name — имя, по которому команду вызывают в шелле.
+	Name() string
+	// Usage — синтаксис команды для help, например "cat [-n] [file...]".
+	Usage() string
+	// Run выполняет команду и возвращает код завершения; args[0] — имя команды.
+	// ctx отменяется вместе с контекстом Interpreter.Run.
+	Run(ctx context.Context, io IO, args []string) int
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Builtin)
+)
+
+// Register добавляет встроенные команды во все шеллы процесса: в l2sh и в каждый
+// Interpreter. В том же реестре находятся и стандартные встроенные команды шелла,
+// поэтому их имена заняты. Повторная регистрация имени — ошибка программиста,
+// Register тогда паникует.
+func Register(cmds ...Builtin) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for _, b := range cmds {
+		name := b.Name()
+		if name == "" || strings.ContainsAny(name, "/ \t\n") {
+			panic(fmt.Sprintf("shell: Register: invalid builtin name %q", name))
+		}
+		if _, dup := registry[name]; dup {
+			panic("shell: Register called twice for builtin " + name)
+		}
+		registry[name] = b
+	}
+}
+
+func registered(name string) (Builtin, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	b, ok := registry[name]
+	return b, ok
+}
+
+// funcBuiltin — встроенная команда из функции, добавленная через Interpreter.RegisterBuiltin.
+type funcBuiltin struct {
+	name string
+	fn   BuiltinFunc
+}
+
+func (b funcBuiltin) Name() string  { return b.name }
+func (b funcBuiltin) Usage() string { return b.name }
+
+func (b funcBuiltin) Run(ctx context.Context, io IO, args []string) int {
+	return b.fn(ctx, io.Stdio, args)
+}
+
+// runBuiltin выполняет встроенную команду b с аргументами args и потоками s.
+func (sh *shell) runBuiltin(b Builtin, args []string, s streams) int {
+	env := s.env
+	if env == nil {
+		env = sh.environ(nil)
+	}
+	stdio := Stdio{In: s.stdin, Out: s.stdout, Err: s.stderr}
+	return b.Run(sh.context(), IO{Stdio: stdio, Dir: sh.cwd(), Env: env, sh: sh, streams: s}, args)
+}
+
+// builtin ищет встроенную команду: сначала среди добавленных в этот шелл через
+// Interpreter.Register, затем в реестре. В ограниченном режиме команда должна быть
+// разрешена настройками (см. restriction.allowsBuiltin).
+func (sh *shell) builtin(name string) (Builtin, bool) {
+	b, ok := sh.builtins[name]
+	if !ok {
+		b, ok = registered(name)
+	}
+	if !ok || !sh.restricted.allowsBuiltin(b) {
+		return nil, false
+	}
+	return b, true
+}
+
+// builtinUsages возвращает синтаксис всех доступных встроенных команд шелла
+// по именам, включая префиксы time и timeout.
+func (sh *shell) builtinUsages() map[string]string {
+	registryMu.RLock()
+	names := make([]string, 0, len(registry)+len(sh.builtins))
+	for name := range registry {
+		names = append(names, name)
+	}
+	registryMu.RUnlock()
+	for name := range sh.builtins {
+		names = append(names, name)
+	}
+
+	usages := make(map[string]string)
+	for _, name := range names {
+		if b, ok := sh.builtin(name); ok {
+			usages[name] = b.Usage()
+		}
+	}
+	for name, usage := range prefixUsages {
+		usages[name] = usage
+	}
+	return usages
+}
+
+// builtinHelp печатает синтаксис встроенных команд: всех или перечисленных.
+func builtinHelp(sh *shell, args []string, s streams) int {
+	usages := sh.builtinUsages()
+	if len(args) < 2 {
+		names := make([]string, 0, len(usages))
+		for name := range usages {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Fprintln(s.stdout, "l2sh builtin commands. Type `help name` to see the usage of one command.")
+		fmt.Fprintln(s.stdout)
+		for _, name := range names {
+			fmt.Fprintf(s.stdout, "  %s\n", usages[name])
+		}
+		return 0
+	}
+
+	status := 0
+	for _, name := range args[1:] {
+		usage, ok := usages[name]
+		if !ok {
+			fmt.Fprintf(s.stderr, "help: no help topics match `%s'\n", name)
+			status = 1
+			continue
+		}
+		fmt.Fprintf(s.stdout, "%s: %s\n", name, usage)
+	}
+	return status
+}
+
+// builtinType сообщает, чем шелл считает каждое имя: алиасом, ключевым словом,
+// функцией, встроенной командой или программой. С -t печатается только вид.
+func builtinType(sh *shell, args []string, s streams) int {
+	args = args[1:]
+	short := len(args) > 0 && args[0] == "-t"
+	if short {
+		args = args[1:]
+	}
+	env := s.env
+	if env == nil {
+		env = sh.environ(nil)
+	}
+
+	status := 0
+	for _, name := range args {
+		kind, desc := sh.commandKind(name, envValue(env, "PATH"))
+		switch {
+		case kind == "":
+			fmt.Fprintf(s.stderr, "type: %s: not found\n", name)
+			status = 1
+		case short:
+			fmt.Fprintln(s.stdout, kind)
+		default:
+			fmt.Fprintln(s.stdout, desc)
+		}
+	}
+	return status
+}
+
+// commandKind определяет, что выполнит шелл по имени name, в том же порядке,
+// в каком ищет команду: alias, keyword, function, builtin или file.
+// Для неизвестного имени kind пуст.
+func (sh *shell) commandKind(name, path string) (kind, desc string) {
+	if value, ok := sh.alias(name); ok {
+		return "alias", fmt.Sprintf("%s is aliased to `%s'", name, value)
+	}
+	if _, ok := prefixUsages[name]; ok || keywords[name] {
+		return "keyword", name + " is a shell keyword"
+	}
+	if _, ok := sh.function(name); ok {
+		return "function", name + " is a function"
+	}
+	if _, ok := sh.builtin(name); ok {
+		return "builtin", name + " is a shell builtin"
+	}
+	var file string
+	var err error
+	if sh.restricted != nil {
+		file, err = sh.restricted.command(name)
+	} else {
+		file, err = lookPath(name, path, sh.cwd())
+	}
+	if err != nil {
+		return "", ""
+	}
+	return "file", name + " is " + file
+}
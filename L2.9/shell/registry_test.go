@@ -0,0 +1,71 @@
+package shell
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// upper — встроенная команда для тестов: переводит stdin в верхний регистр.
+type upper struct{}
+
+func (upper) Name() string  { return "upper" }
+func (upper) Usage() string { return "upper [prefix]" }
+
+func (upper) Run(ctx context.Context, in IO, args []string) int {
+	data, err := io.ReadAll(in.In)
+	if err != nil {
+		return 1
+	}
+	fmt.Fprint(in.Out, strings.Join(args[1:], ""), strings.ToUpper(string(data)))
+	return 0
+}
+
+func init() {
+	Register(upper{})
+}
+
+func TestRegister(t *testing.T) {
+	tests := []struct {
+		script   string
+		expected string
+		status   int
+	}{
+		{"echo abc | upper", "ABC\n", 0},
+		{"echo abc | upper '> '", "> ABC\n", 0},
+		{"alias ll='ls -l'; type upper; type -t cd if ll; type missing", "upper is a shell builtin\nbuiltin\nkeyword\nalias\n", 1},
+		{"type -t timeout", "keyword\n", 0},
+		{"help upper cd", "upper: upper [prefix]\ncd: cd dir\n", 0},
+		{"help missing", "", 1},
+		{"upper() { echo function; }; echo x | upper", "function\n", 0},
+	}
+
+	for _, test := range tests {
+		status, out, _ := runScriptText(t, newShell(), test.script)
+		if status != test.status {
+			t.Errorf("Для скрипта %q ожидался код %d, но получен %d", test.script, test.status, status)
+		}
+		if out != test.expected {
+			t.Errorf("Для скрипта %q ожидалось %q, но получено %q", test.script, test.expected, out)
+		}
+	}
+
+	_, out, _ := runScriptText(t, newShell(), "help")
+	if !strings.Contains(out, "\n  upper [prefix]\n") || !strings.Contains(out, "\n  cd dir\n") {
+		t.Errorf("help должен перечислять все встроенные команды, но вывел %q", out)
+	}
+
+	// Стандартные встроенные команды лежат в том же реестре, поэтому их имена заняты.
+	for _, b := range []Builtin{upper{}, funcBuiltin{name: "cd"}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Повторная регистрация команды %s должна паниковать", b.Name())
+				}
+			}()
+			Register(b)
+		}()
+	}
+}
@@ -0,0 +1,223 @@
+package shell
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultRestrictConfig — файл настроек ограниченного режима по умолчанию.
+const defaultRestrictConfig = "/etc/l2sh/restricted.json"
+
+// restrictConfig — настройки ограниченного режима (l2sh --restricted):
+//
+//	{
+//	  "root": "/srv/support",
+//	  "commands": ["ls", "cat", "grep", "/opt/tools/report"],
+//	  "path": "/usr/bin:/bin",
+//	  "audit_log": "/var/log/l2sh/audit.log"
+//	}
+//
+// commands — разрешённые внешние команды: имена ищутся в path (по умолчанию
+// /usr/bin:/bin) при загрузке настроек, а не по PATH шелла, который пользователь
+// может изменить. Встроенные команды из Register (cat, ls, ...) и встроенные
+// kill, pkill и ulimit (см. guardedBuiltins) тоже нужно перечислить.
+type restrictConfig struct {
+	Root     string   `json:"root"`
+	Commands []string `json:"commands"`
+	Path     string   `json:"path"`
+	AuditLog string   `json:"audit_log"`
+}
+
+// restriction — ограничения шелла в режиме --restricted: cd, source и перенаправление
+// ввода не выходят за root, перенаправлять вывод в файлы нельзя, внешние команды —
+// только из commands.
+// Каждая команда и каждый отказ записываются в журнал аудита.
+type restriction struct {
+	root     string
+	commands map[string]string // имя команды → путь к программе; "" — только встроенная команда
+	user     string
+
+	mu    sync.Mutex
+	audit io.Writer
+}
+
+// errRestricted — общая причина отказов ограниченного режима.
+var errRestricted = errors.New("restricted")
+
+// guardedBuiltins — встроенные команды шелла, которые действуют за его пределами:
+// посылают сигналы чужим процессам или меняют ограничения ресурсов. В ограниченном
+// режиме они, как и внешние программы, доступны, только если перечислены в commands.
+var guardedBuiltins = map[string]bool{"kill": true, "pkill": true, "ulimit": true}
+
+// loadRestriction читает настройки ограниченного режима из файла path
+// и открывает журнал аудита на дозапись.
+func loadRestriction(path string) (*restriction, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg restrictConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if cfg.Root == "" || cfg.AuditLog == "" {
+		return nil, fmt.Errorf("%s: root and audit_log are required", path)
+	}
+	if cfg.Path == "" {
+		cfg.Path = "/usr/bin:/bin"
+	}
+
+	root, err := filepath.EvalSymlinks(cfg.Root)
+	if err == nil {
+		root, err = filepath.Abs(root)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("root: %w", err)
+	}
+	r := &restriction{root: root, commands: make(map[string]string)}
+	for _, name := range cfg.Commands {
+		file, err := lookPath(name, cfg.Path, "/")
+		if err != nil {
+			if _, builtin := registered(name); !builtin {
+				return nil, fmt.Errorf("commands: %w", err)
+			}
+			// Только встроенная команда: внешней программы с этим именем нет.
+			file = ""
+		}
+		r.commands[filepath.Base(name)] = file
+	}
+
+	r.user = strconv.Itoa(os.Getuid())
+	if u, err := user.Current(); err == nil {
+		r.user = u.Username
+	}
+	audit, err := os.OpenFile(cfg.AuditLog, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("audit_log: %w", err)
+	}
+	r.audit = audit
+	return r, nil
+}
+
+// log записывает в журнал аудита строку: время, пользователь, pid шелла, каталог,
+// событие и команда, для отказов — ещё и причина. Поля разделены табуляцией,
+// аргументы с пробелами и спецсимволами записываются в кавычках Go.
+func (r *restriction) log(dir, event string, args []string, reason error) {
+	words := make([]string, len(args))
+	for i, arg := range args {
+		words[i] = arg
+		if arg == "" || strings.ContainsAny(arg, " \t\n'\"\\$`;&|<>(){}*?[]#~") || !strconv.CanBackquote(arg) {
+			words[i] = strconv.Quote(arg)
+		}
+	}
+	line := fmt.Sprintf("%s\t%s\t%d\t%s\t%s\t%s", time.Now().Format(time.RFC3339Nano),
+		r.user, os.Getpid(), dir, event, strings.Join(words, " "))
+	if reason != nil {
+		line += "\t" + reason.Error()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fmt.Fprintln(r.audit, line)
+}
+
+// allows сообщает, что команду name можно выполнять; nil — обычный режим без ограничений.
+// Встроенные команды из Register и guardedBuiltins разрешаются так же, как внешние программы.
+func (r *restriction) allows(name string) bool {
+	if r == nil {
+		return true
+	}
+	_, ok := r.commands[name]
+	return ok
+}
+
+// allowsBuiltin сообщает, что встроенную команду b можно выполнять. Стандартные
+// встроенные команды, кроме guardedBuiltins, доступны всегда, остальные — как
+// внешние программы, если перечислены в commands.
+func (r *restriction) allowsBuiltin(b Builtin) bool {
+	if _, core := b.(coreBuiltin); core && !guardedBuiltins[b.Name()] {
+		return true
+	}
+	return r.allows(b.Name())
+}
+
+// command возвращает путь к разрешённой внешней команде name.
+func (r *restriction) command(name string) (string, error) {
+	if strings.Contains(name, "/") {
+		return "", fmt.Errorf("%w: %s: command names cannot contain /", errRestricted, name)
+	}
+	file, ok := r.commands[name]
+	switch {
+	case !ok:
+		return "", fmt.Errorf("%w: %s: command not allowed", errRestricted, name)
+	case file == "":
+		return "", fmt.Errorf("%w: %s: not an external command", errRestricted, name)
+	}
+	return file, nil
+}
+
+// checkPath проверяет, что файл или каталог path вместе с символическими ссылками
+// не выходит за пределы root.
+func (r *restriction) checkPath(path string) error {
+	real, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return err
+	}
+	if r.root != "/" && real != r.root && !strings.HasPrefix(real, r.root+string(filepath.Separator)) {
+		return fmt.Errorf("%w: %s: outside of %s", errRestricted, path, r.root)
+	}
+	return nil
+}
+
+// checkRedirect запрещает перенаправление вывода в файлы (/dev/null разрешён)
+// и ввод из файлов вне root. Ошибку несуществующего файла сообщит его открытие.
+func (r *restriction) checkRedirect(op redirOp, path string) error {
+	if op == redirIn {
+		if err := r.checkPath(path); errors.Is(err, errRestricted) {
+			return err
+		}
+		return nil
+	}
+	if path != os.DevNull {
+		return fmt.Errorf("%w: %s: cannot redirect output to a file", errRestricted, path)
+	}
+	return nil
+}
+
+// restrict переводит шелл в ограниченный режим: текущим каталогом становится root.
+func (sh *shell) restrict(r *restriction) {
+	sh.restricted = r
+	sh.mu.Lock()
+	sh.dir = r.root
+	sh.mu.Unlock()
+	sh.setVar("PWD", r.root)
+	r.log(r.root, "start", []string{sh.name}, nil)
+}
+
+// audit записывает событие в журнал аудита, если шелл работает в ограниченном режиме.
+func (sh *shell) audit(event string, args []string, reason error) {
+	if sh.restricted != nil {
+		sh.restricted.log(sh.cwd(), event, args, reason)
+	}
+}
+
+// restrictCommand подменяет программу cmd разрешённой в ограниченном режиме
+// или запрещает запуск.
+func (sh *shell) restrictCommand(cmd *exec.Cmd, args []string) {
+	if sh.restricted == nil {
+		return
+	}
+	cmd.Path, cmd.Err = sh.restricted.command(args[0])
+	if cmd.Err != nil {
+		sh.audit("denied", args, cmd.Err)
+	}
+}
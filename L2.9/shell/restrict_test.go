@@ -0,0 +1,142 @@
+package shell
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"L2/internal/cli"
+)
+
+func TestRestricted(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "root")
+	if err := os.MkdirAll(filepath.Join(root, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(dir, filepath.Join(root, "escape")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "sub", "notes"), []byte("one\ntwo\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, "secret"), []byte("echo secret\n"), 0o644)
+	os.WriteFile(filepath.Join(root, "sub", "script"), []byte("echo sourced\n"), 0o644)
+	// Программа upper в текущем каталоге не должна запускаться вместо встроенной upper.
+	os.WriteFile(filepath.Join(root, "upper"), []byte("#!/bin/sh\necho program\n"), 0o755)
+	auditLog := filepath.Join(dir, "audit.log")
+	config := filepath.Join(dir, "restricted.json")
+	os.WriteFile(config, []byte(`{"root": "`+root+`", "commands": ["cat", "/usr/bin/wc", "upper"], "audit_log": "`+auditLog+`"}`), 0o644)
+
+	r, err := loadRestriction(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sh := newShell()
+	sh.restrict(r)
+
+	tests := []struct {
+		script   string
+		expected string
+		status   int
+	}{
+		{"pwd", root + "\n", 0},
+		{"cd sub && pwd && cat notes && wc -l < notes", filepath.Join(root, "sub") + "\none\ntwo\n2\n", 0},
+		{"cd ..; pwd", root + "\n", 0},
+		{"cd ..; pwd", root + "\n", 0},
+		{"cd /; pwd", root + "\n", 0},
+		{"cd escape", "", 1},
+		{"echo secret > file", "", 1},
+		{"echo quiet > /dev/null; cat < sub/notes", "one\ntwo\n", 0},
+		{"ls", "", 126},
+		{"/bin/cat sub/notes", "", 126},
+		{"PATH=/bin; wc -l < sub/notes", "2\n", 0},
+		{"env ls", "", 126},
+		{"timeout 5 ls", "", 126},
+		{"echo native | upper", "NATIVE\n", 0},
+		{"env upper", "", 126},
+		{"timeout 5 upper", "", 126},
+		{"source sub/script; . ../secret", "sourced\n", 1},
+		{"source escape/secret", "", 1},
+		{"cat < ../secret", "", 1},
+		{"cat < escape/secret", "", 1},
+		// kill, pkill и ulimit не перечислены в commands.
+		{"kill -0 $$", "", 126},
+		{"pkill -0 l2sh-none", "", 126},
+		{"ulimit -a", "", 126},
+		{"help kill ulimit", "", 1},
+	}
+	for _, test := range tests {
+		status, out, _ := runScriptText(t, sh, test.script)
+		if status != test.status {
+			t.Errorf("Для скрипта %q ожидался код %d, но получен %d", test.script, test.status, status)
+		}
+		if out != test.expected {
+			t.Errorf("Для скрипта %q ожидалось %q, но получено %q", test.script, test.expected, out)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "file")); err == nil {
+		t.Error("В ограниченном режиме перенаправление не должно создавать файл")
+	}
+
+	data, err := os.ReadFile(auditLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := string(data)
+	for _, expected := range []string{
+		"\tstart\tl2sh\n",
+		"\t" + root + "\trun\tcd sub\n",
+		"\t" + filepath.Join(root, "sub") + "\trun\tcat notes\n",
+		"\trun\techo quiet\n",
+		"\tdenied\tcd escape\trestricted: ",
+		"\tdenied\t\">\" file\trestricted: ",
+		"\tdenied\tls\trestricted: ls: command not allowed\n",
+		"\tdenied\t/bin/cat sub/notes\trestricted: /bin/cat: command names cannot contain /\n",
+		"\tdenied\t. ../secret\trestricted: ",
+		"\tdenied\t\"<\" ../secret\trestricted: ",
+		"\tdenied\tkill -0 ",
+		"\tdenied\tulimit -a\trestricted: ulimit: command not allowed\n",
+		"\tdenied\tupper\trestricted: upper: not an external command\n",
+	} {
+		if !strings.Contains(log, expected) {
+			t.Errorf("В журнале аудита не найдено %q:\n%s", expected, log)
+		}
+	}
+	for _, line := range strings.Split(strings.TrimSpace(log), "\n") {
+		if fields := strings.Split(line, "\t"); len(fields) < 6 || !strings.HasPrefix(fields[0], "20") {
+			t.Errorf("Неверная строка журнала аудита: %q", line)
+		}
+	}
+}
+
+func TestRestrictedOptions(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "restricted.json")
+	auditLog := filepath.Join(dir, "audit.log")
+	os.WriteFile(config, []byte(`{"root": "`+dir+`", "commands": ["cat"], "audit_log": "`+auditLog+`"}`), 0o644)
+
+	tests := []struct {
+		args     []string
+		expected string
+		status   int
+	}{
+		{[]string{"--restricted", "--config", config, "-c", "pwd; ls"}, dir + "\n", 126},
+		{[]string{"--restricted", "--config=" + config, "-c", "echo ok"}, "ok\n", 0},
+		{[]string{"--restricted", "--config", filepath.Join(dir, "missing.json"), "-c", "echo ok"}, "", 2},
+		{[]string{"--restricted", "--config", auditLog, "-c", "echo ok"}, "", 2},
+		{[]string{"--unknown"}, "", 2},
+	}
+	for _, test := range tests {
+		var stdout, stderr bytes.Buffer
+		status := Main(test.args, cli.Stdio{In: strings.NewReader(""), Out: &stdout, Err: &stderr})
+		if status != test.status {
+			t.Errorf("Для аргументов %q ожидался код %d, но получен %d (%s)", test.args, test.status, status, stderr.String())
+		}
+		if stdout.String() != test.expected {
+			t.Errorf("Для аргументов %q ожидалось %q, но получено %q", test.args, test.expected, stdout.String())
+		}
+	}
+}
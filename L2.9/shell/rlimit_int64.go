@@ -0,0 +1,34 @@
+//go:build freebsd || dragonfly
+
+package shell
+
+import (
+	"math"
+	"syscall"
+)
+
+// Во FreeBSD и DragonFly поля syscall.Rlimit знаковые, а RLIM_INFINITY равно math.MaxInt64.
+
+func getrlimit(resource int) (resourceLimit, error) {
+	var lim syscall.Rlimit
+	err := syscall.Getrlimit(resource, &lim)
+	return resourceLimit{fromRlim(lim.Cur), fromRlim(lim.Max)}, err
+}
+
+func setrlimit(resource int, lim resourceLimit) error {
+	return syscall.Setrlimit(resource, &syscall.Rlimit{Cur: toRlim(lim.cur), Max: toRlim(lim.max)})
+}
+
+func fromRlim(v int64) uint64 {
+	if v < 0 || v == math.MaxInt64 {
+		return rlimitInfinity
+	}
+	return uint64(v)
+}
+
+func toRlim(v uint64) int64 {
+	if v >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return int64(v)
+}
@@ -0,0 +1,15 @@
+//go:build !freebsd && !dragonfly
+
+package shell
+
+import "syscall"
+
+func getrlimit(resource int) (resourceLimit, error) {
+	var lim syscall.Rlimit
+	err := syscall.Getrlimit(resource, &lim)
+	return resourceLimit{lim.Cur, lim.Max}, err
+}
+
+func setrlimit(resource int, lim resourceLimit) error {
+	return syscall.Setrlimit(resource, &syscall.Rlimit{Cur: lim.cur, Max: lim.max})
+}
@@ -0,0 +1,384 @@
+package shell
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type flowKind int
+
+const (
+	flowNone     flowKind = iota
+	flowBreak             // break N
+	flowContinue          // continue N
+	flowReturn            // return из функции
+	flowExit              // exit: шелл завершается
+)
+
+// control — прерывание обычного порядка выполнения командами exit, return, break
+// и continue. Его разделяют команды одного уровня: список, тело цикла, тело функции.
+// Функция получает свой control, стадии конвейера и фоновые задания — тоже.
+type control struct {
+	kind     flowKind
+	count    int // сколько вложенных циклов прервать
+	status   int // код для exit и return
+	loops    int // глубина вложенности циклов
+	function bool
+}
+
+// interrupted сообщает, что оставшиеся команды выполнять не нужно.
+func (c *control) interrupted() bool {
+	return c.kind != flowNone
+}
+
+// endIteration обрабатывает break и continue после итерации цикла
+// и сообщает, нужно ли выйти из цикла.
+func (c *control) endIteration() bool {
+	switch c.kind {
+	case flowBreak, flowContinue:
+		c.count--
+		if c.count > 0 {
+			return true
+		}
+		stop := c.kind == flowBreak
+		c.kind = flowNone
+		return stop
+	case flowReturn, flowExit:
+		return true
+	}
+	return false
+}
+
+// runCompound выполняет составную команду в текущем шелле.
+func (sh *shell) runCompound(cmd command, s streams) int {
+	switch c := cmd.(type) {
+	case *ifClause:
+		for i, cond := range c.conds {
+			status := sh.runList(cond, s)
+			if s.ctl.interrupted() {
+				return status
+			}
+			if status == 0 {
+				return sh.runList(c.bodies[i], s)
+			}
+		}
+		if c.elseBody != nil {
+			return sh.runList(c.elseBody, s)
+		}
+		return 0
+	case *loopClause:
+		return sh.runLoop(s, func() bool {
+			return (sh.runList(c.cond, s) == 0) != c.until
+		}, c.body)
+	case *forClause:
+		values := sh.positional()
+		if c.hasIn {
+			e := sh.expander()
+			if values = e.expandArgs(c.words); e.err != nil {
+				fmt.Fprintln(s.stderr, e.err)
+				return 1
+			}
+		}
+		return sh.runLoop(s, func() bool {
+			if len(values) == 0 {
+				return false
+			}
+			sh.setVar(c.name, values[0])
+			values = values[1:]
+			return true
+		}, c.body)
+	case *braceGroup:
+		return sh.runList(c.body, s)
+	}
+	return 0
+}
+
+// runLoop выполняет body, пока next возвращает true, и возвращает код последнего
+// выполнения тела (0, если тело не выполнялось).
+func (sh *shell) runLoop(s streams, next func() bool, body *list) int {
+	s.ctl.loops++
+	defer func() { s.ctl.loops-- }()
+
+	status := 0
+	for !sh.cancelled() {
+		ok := next()
+		if s.ctl.interrupted() {
+			if s.ctl.endIteration() {
+				break
+			}
+			continue
+		}
+		if !ok {
+			break
+		}
+		status = sh.runList(body, s)
+		if s.ctl.endIteration() {
+			break
+		}
+	}
+	if s.ctl.kind == flowReturn || s.ctl.kind == flowExit {
+		return s.ctl.status
+	}
+	return status
+}
+
+func (sh *shell) defineFunction(fn *funcDef) {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if sh.funcs == nil {
+		sh.funcs = make(map[string]*funcDef)
+	}
+	sh.funcs[fn.name] = fn
+}
+
+func (sh *shell) function(name string) (*funcDef, bool) {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	fn, ok := sh.funcs[name]
+	return fn, ok
+}
+
+// callFunction выполняет тело функции с позиционными параметрами args[1:].
+// return завершает только функцию, exit передаётся вызывающему.
+func (sh *shell) callFunction(fn *funcDef, args []string, s streams) int {
+	caller := s.ctl
+	s.ctl = &control{function: true}
+	saved := sh.setPositional(args[1:])
+	defer sh.setPositional(saved)
+
+	status := sh.runCompound(fn.body, s)
+	switch s.ctl.kind {
+	case flowReturn:
+		status = s.ctl.status
+	case flowExit:
+		*caller = *s.ctl
+		status = s.ctl.status
+	}
+	return status
+}
+
+// positional возвращает позиционные параметры $1, $2, ...
+func (sh *shell) positional() []string {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	return append([]string(nil), sh.params...)
+}
+
+// setPositional заменяет позиционные параметры и возвращает прежние.
+func (sh *shell) setPositional(params []string) []string {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	saved := sh.params
+	sh.params = params
+	return saved
+}
+
+// source выполняет файл в текущем шелле: его переменные и функции остаются.
+func (sh *shell) source(path string, s streams) (int, error) {
+	data, err := os.ReadFile(sh.path(path))
+	if err != nil {
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) {
+			err = pathErr.Err
+		}
+		return 1, err
+	}
+	tree, err := sh.parse(string(data))
+	if err != nil {
+		return 2, scriptError(string(data), err)
+	}
+	return sh.runList(tree, s), nil
+}
+
+// runScript выполняет скрипт целиком и возвращает код завершения шелла:
+// код exit или последней команды. name используется в сообщениях об ошибках.
+func (sh *shell) runScript(src, name string) int {
+	tree, err := sh.parse(src)
+	if err != nil {
+		fmt.Fprintf(sh.stderr, "%s: %v\n", name, scriptError(src, err))
+		return 2
+	}
+	base := sh.stdStreams()
+	status := sh.runList(tree, base)
+	if base.ctl.kind == flowExit {
+		return base.ctl.status
+	}
+	return status
+}
+
+// scriptError указывает в синтаксической ошибке номер строки вместо позиции.
+func scriptError(src string, err error) error {
+	var syntaxErr *syntaxError
+	if !errors.As(err, &syntaxErr) {
+		return err
+	}
+	runes := []rune(src)
+	line := 1 + strings.Count(string(runes[:min(syntaxErr.pos, len(runes))]), "\n")
+	return fmt.Errorf("line %d: syntax error: %s", line, syntaxErr.msg)
+}
+
+// flowCount разбирает необязательный аргумент exit, return, break и continue.
+func flowCount(name string, args []string, s streams, def int) (int, bool) {
+	if len(args) < 2 {
+		return def, true
+	}
+	n, err := strconv.Atoi(args[1])
+	if err != nil {
+		fmt.Fprintf(s.stderr, "%s: %s: numeric argument required\n", name, args[1])
+		return 0, false
+	}
+	return n, true
+}
+
+func builtinExit(sh *shell, args []string, s streams) int {
+	sh.mu.Lock()
+	last := sh.lastStatus
+	sh.mu.Unlock()
+	status, ok := flowCount("exit", args, s, last)
+	if !ok {
+		status = 2
+	}
+	s.ctl.kind, s.ctl.status = flowExit, status&0xff
+	return s.ctl.status
+}
+
+func builtinReturn(sh *shell, args []string, s streams) int {
+	if !s.ctl.function {
+		fmt.Fprintln(s.stderr, "return: can only `return' from a function")
+		return 1
+	}
+	sh.mu.Lock()
+	last := sh.lastStatus
+	sh.mu.Unlock()
+	status, ok := flowCount("return", args, s, last)
+	if !ok {
+		status = 2
+	}
+	s.ctl.kind, s.ctl.status = flowReturn, status&0xff
+	return s.ctl.status
+}
+
+// loopBuiltin создаёт break или continue.
+func loopBuiltin(name string, kind flowKind) builtinFunc {
+	return func(sh *shell, args []string, s streams) int {
+		n, ok := flowCount(name, args, s, 1)
+		if !ok {
+			return 1
+		}
+		if n < 1 {
+			fmt.Fprintf(s.stderr, "%s: %d: loop count out of range\n", name, n)
+			return 1
+		}
+		if s.ctl.loops == 0 {
+			fmt.Fprintf(s.stderr, "%s: only meaningful in a `for', `while', or `until' loop\n", name)
+			return 0
+		}
+		s.ctl.kind, s.ctl.count = kind, min(n, s.ctl.loops)
+		return 0
+	}
+}
+
+// builtinShift сдвигает позиционные параметры: $2 становится $1.
+func builtinShift(sh *shell, args []string, s streams) int {
+	n, ok := flowCount("shift", args, s, 1)
+	if !ok {
+		return 1
+	}
+	params := sh.positional()
+	if n < 0 || n > len(params) {
+		fmt.Fprintf(s.stderr, "shift: %d: shift count out of range\n", n)
+		return 1
+	}
+	sh.setPositional(params[n:])
+	return 0
+}
+
+// builtinSource выполняет файл в текущем шелле; остальные аргументы
+// на время выполнения становятся позиционными параметрами.
+func builtinSource(sh *shell, args []string, s streams) int {
+	if len(args) < 2 {
+		fmt.Fprintf(s.stderr, "%s: filename argument required\n", args[0])
+		return 2
+	}
+	if sh.restricted != nil {
+		if err := sh.restricted.checkPath(sh.path(args[1])); errors.Is(err, errRestricted) {
+			sh.audit("denied", args, err)
+			fmt.Fprintf(s.stderr, "%s: %v\n", args[0], err)
+			return 1
+		}
+	}
+	if len(args) > 2 {
+		saved := sh.setPositional(args[2:])
+		defer sh.setPositional(saved)
+	}
+	status, err := sh.source(args[1], s)
+	if err != nil {
+		fmt.Fprintf(s.stderr, "%s: %s: %v\n", args[0], args[1], err)
+	}
+	return status
+}
+
+// builtinRead читает строку из stdin в переменные: слова по очереди, остаток
+// строки — в последнюю. Без -r обратная косая черта экранирует следующий символ.
+// Ввод читается по байту, чтобы не забрать строки, предназначенные следующим командам.
+func builtinRead(sh *shell, args []string, s streams) int {
+	names := args[1:]
+	raw := len(names) > 0 && names[0] == "-r"
+	if raw {
+		names = names[1:]
+	}
+	if len(names) == 0 {
+		names = []string{"REPLY"}
+	}
+
+	var line []byte
+	buf := make([]byte, 1)
+	eof := false
+	for {
+		n, err := s.stdin.Read(buf)
+		if n == 0 && err != nil {
+			eof = true
+			break
+		}
+		if n == 0 {
+			continue
+		}
+		if buf[0] == '\n' {
+			if !raw && len(line) > 0 && line[len(line)-1] == '\\' {
+				line = line[:len(line)-1]
+				continue
+			}
+			break
+		}
+		line = append(line, buf[0])
+	}
+	text := string(line)
+	if !raw {
+		text = strings.NewReplacer(`\\`, `\`, `\`, "").Replace(text)
+	}
+
+	fields := strings.Fields(text)
+	for i, name := range names {
+		value := ""
+		switch {
+		case i == len(names)-1 && i < len(fields):
+			// Остаток строки без начальных и конечных пробелов.
+			rest := strings.TrimSpace(text)
+			for _, f := range fields[:i] {
+				rest = strings.TrimLeft(strings.TrimPrefix(rest, f), " \t")
+			}
+			value = rest
+		case i < len(fields):
+			value = fields[i]
+		}
+		sh.setVar(name, value)
+	}
+	if eof && len(line) == 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,163 @@
+package shell
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runScriptText выполняет скрипт в sh и возвращает код и вывод встроенных команд.
+func runScriptText(t *testing.T, sh *shell, script string) (int, string, streams) {
+	t.Helper()
+	tree, err := parse(script)
+	if err != nil {
+		t.Fatalf("Не ожидалась ошибка разбора %q: %v", script, err)
+	}
+	var stdout, stderr bytes.Buffer
+	s := streams{stdin: strings.NewReader(""), stdout: &stdout, stderr: &stderr, ctl: &control{}}
+	status := sh.runList(tree, s)
+	return status, stdout.String(), s
+}
+
+func TestCompoundCommands(t *testing.T) {
+	tests := []struct {
+		script   string
+		expected string
+		status   int
+	}{
+		{"if false; then echo a; elif true; then echo b; else echo c; fi", "b\n", 0},
+		{"if false; then echo a; fi", "", 0},
+		{"for x in a 'b c' d; do echo $x; done", "a\nb c\nd\n", 0},
+		{"i=; while [ \"$i\" != xxx ]; do i=x$i; done; echo $i", "xxx\n", 0},
+		{"until true; do echo never; done", "", 0},
+		{"for x in 1 2 3; do if [ $x = 2 ]; then continue; fi; echo $x; done", "1\n3\n", 0},
+		{"for x in 1 2 3; do echo $x; break; done", "1\n", 0},
+		{"for a in 1 2; do for b in 1 2 3; do [ $b = 2 ] && continue 2; echo $a$b; done; done", "11\n21\n", 0},
+		{"for a in 1 2; do for b in 1 2; do break 2; done; done; echo out", "out\n", 0},
+		{"{ echo a; echo b; } | cat > /dev/null; echo $?", "0\n", 0},
+		{"! false", "", 0},
+		{"! true", "", 1},
+		{"f() { echo \"$# $1 $2\"; }; f x 'y z'", "2 x y z\n", 0},
+		{"f() { return 3; echo no; }; f; echo $?", "3\n", 0},
+		{"f() { for x in \"$@\"; do echo \"[$x]\"; done; }; f 'a b' c", "[a b]\n[c]\n", 0},
+		{"f() { echo in; exit 4; }; f; echo no", "in\n", 4},
+		{"function g { echo ${1:-none}; }; g", "none\n", 0},
+		{"exit 300", "", 44},
+		{"echo a | exit 3; echo still", "still\n", 0},
+		{"while read x rest; do echo \"$x/$rest\"; done <<EOF\na b  c \nd\nEOF", "a/b  c\nd/\n", 0},
+		// Подстановка команды выполняется в копии шелла.
+		{"d=$(pwd); x=$(cd /; y=1; f() { :; }; pwd); echo \"$x [$y]\"; [ \"$(pwd)\" = \"$d\" ] && type -t f || echo kept", "/ []\nkept\n", 0},
+		{"set -- a b; x=$(shift; set -o pipefail; echo $1); echo $x $1; set -o | while read n v; do [ $n = pipefail ] && echo $v; done", "b a\noff\n", 0},
+		{"v=$(exit 3); echo $?", "3\n", 0},
+		{"v=$(false) w=$(true); echo $?; $(exit 5); echo $?", "0\n5\n", 0},
+		// Ошибка в ${...} прерывает команду с кодом 1.
+		{"echo ${x!} $(echo ran); echo $?", "1\n", 0},
+		{"x=old; x=${} y=${x:=${:}}; echo $x $?", "old 1\n", 0},
+		{"for i in a ${1x}; do echo $i; done; echo $?", "1\n", 0},
+		{"cat <<EOF; echo $?\n${x:}\nEOF", "1\n", 0},
+	}
+
+	for _, test := range tests {
+		status, out, _ := runScriptText(t, newShell(), test.script)
+		if status != test.status {
+			t.Errorf("Для скрипта %q ожидался код %d, но получен %d", test.script, test.status, status)
+		}
+		if out != test.expected {
+			t.Errorf("Для скрипта %q ожидалось %q, но получено %q", test.script, test.expected, out)
+		}
+	}
+}
+
+func TestExitStopsScript(t *testing.T) {
+	_, _, s := runScriptText(t, newShell(), "true; exit 2; echo no")
+	if s.ctl.kind != flowExit || s.ctl.status != 2 {
+		t.Errorf("Ожидался выход с кодом 2, но получено %v, %d", s.ctl.kind, s.ctl.status)
+	}
+
+	_, _, s = runScriptText(t, newShell(), "for x in a; do break; done; return 1")
+	if s.ctl.interrupted() {
+		t.Errorf("break и return вне функции не должны прерывать скрипт")
+	}
+}
+
+func TestSourceAndShift(t *testing.T) {
+	lib := filepath.Join(t.TempDir(), "lib.sh")
+	if err := os.WriteFile(lib, []byte("LIB=loaded\nhello() { echo hello $1; }\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	sh := newShell()
+	sh.params = []string{"a", "b", "c"}
+	_, out, _ := runScriptText(t, sh, ". "+lib+"; hello $LIB; shift; echo $# $1; shift 2; echo $#")
+	if expected := "hello loaded\n2 b\n0\n"; out != expected {
+		t.Errorf("Ожидалось %q, но получено %q", expected, out)
+	}
+	if status, _, _ := runScriptText(t, sh, "shift"); status != 1 {
+		t.Errorf("Ожидался код 1 для shift без параметров, но получен %d", status)
+	}
+}
+
+func TestBuiltinTest(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	os.WriteFile(file, []byte("x"), 0o644)
+
+	tests := []struct {
+		args     []string
+		expected int
+	}{
+		{[]string{"test"}, 1},
+		{[]string{"test", "word"}, 0},
+		{[]string{"test", ""}, 1},
+		{[]string{"test", "-n", ""}, 1},
+		{[]string{"test", "-z", ""}, 0},
+		{[]string{"test", "a", "=", "a"}, 0},
+		{[]string{"test", "a", "!=", "a"}, 1},
+		{[]string{"test", "10", "-gt", "9"}, 0},
+		{[]string{"test", "x", "-eq", "1"}, 2},
+		{[]string{"test", "!", "-e", file}, 1},
+		{[]string{"test", "-f", file}, 0},
+		{[]string{"test", "-d", file}, 1},
+		{[]string{"test", "-d", dir, "-a", "-s", file}, 0},
+		{[]string{"test", "-f", dir, "-o", "(", "1", "-lt", "2", ")"}, 0},
+		{[]string{"[", "a", "=", "a", "]"}, 0},
+		{[]string{"[", "a", "=", "a"}, 2},
+		{[]string{"test", "-q", "x"}, 2},
+	}
+
+	var stderr bytes.Buffer
+	for _, test := range tests {
+		s := streams{stdout: &bytes.Buffer{}, stderr: &stderr}
+		if got := builtinTest(newShell(), test.args, s); got != test.expected {
+			t.Errorf("Для %q ожидался код %d, но получен %d", test.args, test.expected, got)
+		}
+	}
+}
+
+func TestAliasBuiltins(t *testing.T) {
+	sh := newShell()
+	status, out, _ := runScriptText(t, sh, `alias ll='echo long' e='echo ' w=world; alias; alias e nope`)
+	expected := "alias e='echo '\nalias ll='echo long'\nalias w='world'\nalias e='echo '\n"
+	if status != 1 || out != expected {
+		t.Errorf("Ожидалось %q с кодом 1, но получено %q с кодом %d", expected, out, status)
+	}
+
+	// Алиасы из предыдущей строки раскрываются при разборе следующей.
+	tree, err := sh.parse("ll x; e w; unalias ll; alias ll")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	status = sh.runList(tree, streams{stdin: strings.NewReader(""), stdout: &stdout, stderr: &stderr, ctl: &control{}})
+	if expected := "long x\nworld\n"; status != 1 || stdout.String() != expected {
+		t.Errorf("Ожидалось %q с кодом 1, но получено %q с кодом %d", expected, stdout.String(), status)
+	}
+	if _, ok := sh.alias("ll"); ok {
+		t.Error("Алиас ll должен быть удалён unalias")
+	}
+	if status, _, _ := runScriptText(t, sh, "unalias -a; alias 'a b=c'"); status != 1 || len(sh.aliases) != 0 {
+		t.Errorf("Ожидались удаление всех алиасов и ошибка для недопустимого имени, но получен код %d и %d алиасов", status, len(sh.aliases))
+	}
+}
@@ -0,0 +1,215 @@
+// Package shell реализует UNIX-шелл l2sh: разбор командной строки по правилам
+// POSIX shell, конвейеры, перенаправления, задания, переменные, скрипты и
+// редактор строки. Шелл запускается как программа через Main или встраивается
+// в другую программу через Interpreter.
+package shell
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"sync"
+	"syscall"
+)
+
+// shell — состояние шелла между командами.
+type shell struct {
+	// interactive — stdin является терминалом, и шелл передаёт его заданиям переднего плана.
+	interactive bool
+	pgid        int // группа процессов самого шелла
+	// Потоки шелла: с ними выполняются команды, если нет перенаправлений.
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	// builtins — встроенные команды только этого шелла (Interpreter.Register);
+	// они заменяют одноимённые команды реестра.
+	builtins map[string]Builtin
+	// restricted — ограничения режима --restricted; nil — обычный режим.
+	restricted *restriction
+
+	mu sync.Mutex
+	// dir — текущий каталог шелла. cd меняет его, а не каталог процесса,
+	// поэтому несколько интерпретаторов в одном процессе не мешают друг другу.
+	dir string
+	// ctx — контекст текущего Interpreter.Run; после отмены команды не запускаются.
+	ctx context.Context
+	// vars — переменные шелла; изначально это окружение процесса.
+	vars       map[string]*variable
+	lastStatus int // $?
+	// lastBackground — группа процессов последнего фонового задания ($!).
+	lastBackground int
+	// jobs — фоновые и остановленные задания; последнее — текущее (+).
+	jobs []*job
+	// foreground — стек заданий переднего плана: fg внутри конвейера добавляет ещё одно.
+	foreground []*job
+	name       string   // $0
+	params     []string // позиционные параметры $1, $2, ...
+	funcs      map[string]*funcDef
+	options    map[string]bool // включённые параметры set -o
+	aliases    map[string]string
+	// limits — ограничения ресурсов для запускаемых программ, заданные ulimit.
+	limits map[int]resourceLimit
+	// limitExec — исполняемый файл l2sh, через который программы запускаются
+	// с ограничениями limits (см. startProcess); пусто — шелл встроен в другую программу.
+	limitExec string
+}
+
+// newShell создаёт шелл со стандартными потоками, окружением и текущим каталогом процесса.
+func newShell() *shell {
+	sh := &shell{
+		pgid:   syscall.Getpgrp(),
+		name:   "l2sh",
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+		ctx:    context.Background(),
+	}
+	sh.initVars(os.Environ())
+	sh.dir, _ = os.Getwd()
+	return sh
+}
+
+// subshell возвращает копию шелла для подстановки команды: переменные, каталог,
+// функции, параметры set -o, псевдонимы и позиционные параметры копируются,
+// поэтому изменения в копии не затрагивают sh. Задания sh в копию не попадают.
+func (sh *shell) subshell() *shell {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	sub := &shell{
+		interactive:    sh.interactive,
+		pgid:           sh.pgid,
+		stdin:          sh.stdin,
+		stdout:         sh.stdout,
+		stderr:         sh.stderr,
+		builtins:       sh.builtins,
+		restricted:     sh.restricted,
+		dir:            sh.dir,
+		ctx:            sh.ctx,
+		vars:           make(map[string]*variable, len(sh.vars)),
+		lastStatus:     sh.lastStatus,
+		lastBackground: sh.lastBackground,
+		name:           sh.name,
+		params:         slices.Clone(sh.params),
+		funcs:          maps.Clone(sh.funcs),
+		options:        maps.Clone(sh.options),
+		aliases:        maps.Clone(sh.aliases),
+		limits:         maps.Clone(sh.limits),
+		limitExec:      sh.limitExec,
+	}
+	for name, v := range sh.vars {
+		copied := *v
+		sub.vars[name] = &copied
+	}
+	return sub
+}
+
+// stdStreams возвращает потоки шелла для команды верхнего уровня.
+func (sh *shell) stdStreams() streams {
+	return streams{stdin: sh.stdin, stdout: sh.stdout, stderr: sh.stderr, ctl: &control{}}
+}
+
+// cwd возвращает текущий каталог шелла.
+func (sh *shell) cwd() string {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	return sh.dir
+}
+
+// path переводит путь относительно текущего каталога шелла в абсолютный.
+func (sh *shell) path(name string) string {
+	if filepath.IsAbs(name) || name == "" {
+		return name
+	}
+	return filepath.Join(sh.cwd(), name)
+}
+
+// context возвращает контекст выполнения команд.
+func (sh *shell) context() context.Context {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	return sh.ctx
+}
+
+// cancelled сообщает, что контекст выполнения отменён и новые команды запускать нельзя.
+func (sh *shell) cancelled() bool {
+	return sh.context().Err() != nil
+}
+
+// enableJobControl включает управление заданиями, если ввод шелла — терминал:
+// шелл становится группой переднего плана, а Ctrl+C и Ctrl+Z достаются
+// только заданию переднего плана, а не самому шеллу.
+func (sh *shell) enableJobControl() {
+	if sh.stdin == os.Stdin && isTerminal(0) {
+		sh.interactive = tcsetpgrp(0, sh.pgid) == nil
+	}
+	sh.forwardSignals()
+}
+
+// forwardSignals пересылает Ctrl+C и Ctrl+Z, полученные шеллом, заданию переднего
+// плана: его процессы в своей группе и сами сигнал не получат. Скрипт после Ctrl+C
+// завершается, как и sh.
+func (sh *shell) forwardSignals() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTSTP)
+	go func() {
+		for sig := range signals {
+			// С терминалом ядро само доставляет сигнал группе переднего плана;
+			// сюда сигнал попадает, если он адресован шеллу (нет терминала, kill -INT).
+			if j := sh.currentForeground(); j != nil {
+				if pgid := j.pgroup(); pgid != 0 {
+					syscall.Kill(-pgid, sig.(syscall.Signal))
+				}
+			}
+			if !sh.interactive && sig == syscall.SIGINT {
+				os.Exit(128 + int(syscall.SIGINT))
+			}
+		}
+	}()
+}
+
+func (sh *shell) currentForeground() *job {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if n := len(sh.foreground); n > 0 {
+		return sh.foreground[n-1]
+	}
+	return nil
+}
+
+// waitForeground ждёт задание переднего плана. Если его остановили (Ctrl+Z),
+// оно попадает в таблицу заданий, а код завершения равен 128+SIGTSTP.
+// После отмены контекста выполнения группа процессов задания получает SIGKILL.
+func (sh *shell) waitForeground(j *job) int {
+	sh.mu.Lock()
+	sh.foreground = append(sh.foreground, j)
+	sh.mu.Unlock()
+
+	stop := context.AfterFunc(sh.context(), func() {
+		if pgid := j.pgroup(); pgid != 0 {
+			syscall.Kill(-pgid, syscall.SIGKILL)
+		}
+	})
+	stopped, status := j.waitStopOrDone()
+	stop()
+
+	sh.mu.Lock()
+	sh.foreground = sh.foreground[:len(sh.foreground)-1]
+	sh.mu.Unlock()
+	if sh.interactive {
+		tcsetpgrp(0, sh.pgid)
+	}
+
+	if stopped {
+		j.setForeground(false)
+		sh.addJob(j)
+		fmt.Fprintf(sh.stderr, "\n%s\n", formatJob(j, '+'))
+		return 128 + int(syscall.SIGTSTP)
+	}
+	sh.removeJob(j)
+	return status
+}
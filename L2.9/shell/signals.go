@@ -0,0 +1,329 @@
+package shell
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// signalNames — имена сигналов без префикса SIG, по номерам. Сигналы, которые
+// есть только в Linux, добавляются в signals_linux.go.
+var signalNames = map[syscall.Signal]string{
+	syscall.SIGHUP: "HUP", syscall.SIGINT: "INT", syscall.SIGQUIT: "QUIT", syscall.SIGILL: "ILL",
+	syscall.SIGTRAP: "TRAP", syscall.SIGABRT: "ABRT", syscall.SIGBUS: "BUS", syscall.SIGFPE: "FPE",
+	syscall.SIGKILL: "KILL", syscall.SIGUSR1: "USR1", syscall.SIGSEGV: "SEGV", syscall.SIGUSR2: "USR2",
+	syscall.SIGPIPE: "PIPE", syscall.SIGALRM: "ALRM", syscall.SIGTERM: "TERM",
+	syscall.SIGCHLD: "CHLD", syscall.SIGCONT: "CONT", syscall.SIGSTOP: "STOP", syscall.SIGTSTP: "TSTP",
+	syscall.SIGTTIN: "TTIN", syscall.SIGTTOU: "TTOU", syscall.SIGURG: "URG", syscall.SIGXCPU: "XCPU",
+	syscall.SIGXFSZ: "XFSZ", syscall.SIGVTALRM: "VTALRM", syscall.SIGPROF: "PROF", syscall.SIGWINCH: "WINCH",
+	syscall.SIGIO: "IO", syscall.SIGSYS: "SYS",
+}
+
+// parseSignal разбирает сигнал по номеру (9) или имени (TERM, SIGTERM, term).
+func parseSignal(spec string) (syscall.Signal, error) {
+	if n, err := strconv.Atoi(spec); err == nil {
+		if n == 0 {
+			return 0, nil
+		}
+		if _, ok := signalNames[syscall.Signal(n)]; ok {
+			return syscall.Signal(n), nil
+		}
+		return 0, fmt.Errorf("%s: invalid signal specification", spec)
+	}
+	name := strings.TrimPrefix(strings.ToUpper(spec), "SIG")
+	for sig, signalName := range signalNames {
+		if signalName == name {
+			return sig, nil
+		}
+	}
+	return 0, fmt.Errorf("%s: invalid signal specification", spec)
+}
+
+// listSignals печатает сигналы для kill -l. С аргументами переводит номера
+// в имена и обратно; код завершения 128+N тоже переводится в имя сигнала N.
+func listSignals(args []string, w io.Writer) error {
+	if len(args) == 0 {
+		for n := 1; n < 32; n++ {
+			sep := "\t"
+			if n%5 == 0 || n == 31 {
+				sep = "\n"
+			}
+			fmt.Fprintf(w, "%2d) SIG%s%s", n, signalNames[syscall.Signal(n)], sep)
+		}
+		return nil
+	}
+	for _, arg := range args {
+		if n, err := strconv.Atoi(arg); err == nil {
+			if n > 128 {
+				n -= 128
+			}
+			name, ok := signalNames[syscall.Signal(n)]
+			if !ok {
+				return fmt.Errorf("%s: invalid signal specification", arg)
+			}
+			fmt.Fprintln(w, name)
+			continue
+		}
+		sig, err := parseSignal(arg)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, int(sig))
+	}
+	return nil
+}
+
+// builtinKill посылает сигнал процессам, группам процессов и заданиям:
+//
+//	kill [-s SIG | -SIG | -n N] pid|-pgid|%job...
+//	kill -l [N|SIG...]
+//
+// По умолчанию посылается SIGTERM. Остановленному заданию вслед посылается
+// SIGCONT, чтобы сигнал был доставлен.
+func builtinKill(sh *shell, args []string, s streams) int {
+	args = args[1:]
+	sig := syscall.SIGTERM
+	if len(args) > 0 {
+		switch arg := args[0]; {
+		case arg == "-l" || arg == "-L":
+			if err := listSignals(args[1:], s.stdout); err != nil {
+				fmt.Fprintln(s.stderr, "kill:", err)
+				return 1
+			}
+			return 0
+		case arg == "-s" || arg == "-n":
+			if len(args) < 2 {
+				fmt.Fprintf(s.stderr, "kill: %s: option requires an argument\n", arg)
+				return 2
+			}
+			var err error
+			if sig, err = parseSignal(args[1]); err != nil {
+				fmt.Fprintln(s.stderr, "kill:", err)
+				return 1
+			}
+			args = args[2:]
+		case arg == "--":
+			args = args[1:]
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			var err error
+			if sig, err = parseSignal(arg[1:]); err != nil {
+				fmt.Fprintln(s.stderr, "kill:", err)
+				return 1
+			}
+			args = args[1:]
+		}
+	}
+	if len(args) > 0 && args[0] == "--" {
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		fmt.Fprintln(s.stderr, "kill: usage: kill [-s sigspec | -n signum | -sigspec] pid | jobspec ... or kill -l [sigspec]")
+		return 2
+	}
+
+	status := 0
+	for _, target := range args {
+		if err := sh.signalTarget(target, sig); err != nil {
+			fmt.Fprintln(s.stderr, "kill:", err)
+			status = 1
+		}
+	}
+	return status
+}
+
+// signalTarget посылает сигнал процессу (pid), группе (-pgid) или заданию (%job).
+func (sh *shell) signalTarget(target string, sig syscall.Signal) error {
+	if !strings.HasPrefix(target, "%") {
+		pid, err := strconv.Atoi(target)
+		if err != nil {
+			return fmt.Errorf("%s: arguments must be process or job IDs", target)
+		}
+		if err := syscall.Kill(pid, sig); err != nil {
+			return fmt.Errorf("%s: %w", target, err)
+		}
+		return nil
+	}
+
+	j, err := sh.findJob(target)
+	if err != nil {
+		return err
+	}
+	pgid := j.pgroup()
+	if pgid == 0 {
+		return fmt.Errorf("%s: job has no processes", target)
+	}
+	if err := syscall.Kill(-pgid, sig); err != nil {
+		return fmt.Errorf("%s: %w", target, err)
+	}
+	if j.state() == "Stopped" && sig != syscall.SIGKILL && sig != syscall.SIGCONT && sig != 0 {
+		return continueJob(j)
+	}
+	return nil
+}
+
+// pgrepOptions — условия отбора процессов для pgrep и pkill.
+type pgrepOptions struct {
+	pattern *regexp.Regexp
+	full    bool // сравнивать с полной командной строкой, а не с именем
+	exact   bool // шаблон должен совпасть с именем целиком
+	uid     int  // -1 — любой пользователь
+	parent  int  // 0 — любой родитель
+	newest  bool
+	oldest  bool
+	list    bool // pgrep -l: печатать имя рядом с pid
+	sig     syscall.Signal
+}
+
+// parsePgrepArgs разбирает аргументы pgrep и pkill; для pkill первым может быть -SIG.
+func parsePgrepArgs(name string, args []string) (*pgrepOptions, error) {
+	opts := &pgrepOptions{uid: -1, sig: syscall.SIGTERM}
+	var pattern string
+	havePattern := false
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			if havePattern {
+				return nil, errors.New("only one pattern can be provided")
+			}
+			pattern, havePattern = arg, true
+			continue
+		}
+		next := func() (string, error) {
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("option %s requires an argument", arg)
+			}
+			i++
+			return args[i], nil
+		}
+		switch arg {
+		case "-f":
+			opts.full = true
+		case "-x":
+			opts.exact = true
+		case "-n":
+			opts.newest = true
+		case "-o":
+			opts.oldest = true
+		case "-l":
+			opts.list = true
+		case "-u":
+			value, err := next()
+			if err != nil {
+				return nil, err
+			}
+			if opts.uid, err = strconv.Atoi(value); err != nil {
+				u, err := user.Lookup(value)
+				if err != nil {
+					return nil, fmt.Errorf("invalid user name: %s", value)
+				}
+				opts.uid, _ = strconv.Atoi(u.Uid)
+			}
+		case "-P":
+			value, err := next()
+			if err != nil {
+				return nil, err
+			}
+			if opts.parent, err = strconv.Atoi(value); err != nil {
+				return nil, fmt.Errorf("invalid parent pid: %s", value)
+			}
+		default:
+			sig, err := parseSignal(arg[1:])
+			if name != "pkill" || err != nil {
+				return nil, fmt.Errorf("invalid option %s", arg)
+			}
+			opts.sig = sig
+		}
+	}
+	if !havePattern && opts.uid < 0 && opts.parent == 0 {
+		return nil, errors.New("no matching criteria specified")
+	}
+
+	if opts.exact {
+		pattern = "^(?:" + pattern + ")$"
+	}
+	var err error
+	if opts.pattern, err = regexp.Compile(pattern); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// matchProcesses отбирает процессы fs по условиям opts, пропуская процесс self.
+func matchProcesses(fs procFS, self int, opts *pgrepOptions) ([]*procInfo, error) {
+	procs, err := fs.processes()
+	if err != nil {
+		return nil, err
+	}
+	var matched []*procInfo
+	for _, p := range procs {
+		subject := p.comm
+		if opts.full && len(p.cmdline) > 0 {
+			subject = strings.Join(p.cmdline, " ")
+		}
+		if p.pid == self || !opts.pattern.MatchString(subject) ||
+			opts.uid >= 0 && p.uid != opts.uid || opts.parent != 0 && p.ppid != opts.parent {
+			continue
+		}
+		matched = append(matched, p)
+	}
+
+	if len(matched) > 0 && (opts.newest || opts.oldest) {
+		pick := matched[0]
+		for _, p := range matched[1:] {
+			if opts.newest && p.startTicks >= pick.startTicks || opts.oldest && p.startTicks < pick.startTicks {
+				pick = p
+			}
+		}
+		matched = []*procInfo{pick}
+	}
+	return matched, nil
+}
+
+// builtinPgrep — pgrep и pkill поверх /proc. Шаблон — регулярное выражение
+// для имени процесса (с -f — для всей командной строки); сам шелл не выбирается.
+//
+//	pgrep [-l] [-f] [-x] [-n|-o] [-u user] [-P ppid] pattern  — печатает pid
+//	pkill [-SIG] [-f] [-x] [-n|-o] [-u user] [-P ppid] pattern — посылает сигнал (SIGTERM)
+//
+// Код 0 — процессы найдены, 1 — не найдены, 2 — ошибка в аргументах, 3 — не удалось прочитать /proc.
+func builtinPgrep(sh *shell, args []string, s streams) int {
+	return runPgrep(procFS{root: "/proc"}, os.Getpid(), args, s)
+}
+
+// runPgrep выполняет pgrep или pkill (по args[0]) над деревом fs, пропуская процесс self.
+func runPgrep(fs procFS, self int, args []string, s streams) int {
+	name := args[0]
+	opts, err := parsePgrepArgs(name, args[1:])
+	if err != nil {
+		fmt.Fprintf(s.stderr, "%s: %v\n", name, err)
+		return 2
+	}
+	procs, err := matchProcesses(fs, self, opts)
+	if err != nil {
+		fmt.Fprintf(s.stderr, "%s: %v\n", name, err)
+		return 3
+	}
+	if len(procs) == 0 {
+		return 1
+	}
+
+	for _, p := range procs {
+		switch {
+		case name == "pkill":
+			if err := syscall.Kill(p.pid, opts.sig); err != nil {
+				fmt.Fprintf(s.stderr, "pkill: killing pid %d failed: %v\n", p.pid, err)
+			}
+		case opts.list:
+			fmt.Fprintln(s.stdout, p.pid, p.comm)
+		default:
+			fmt.Fprintln(s.stdout, p.pid)
+		}
+	}
+	return 0
+}
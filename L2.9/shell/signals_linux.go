@@ -0,0 +1,8 @@
+package shell
+
+import "syscall"
+
+func init() {
+	signalNames[syscall.SIGSTKFLT] = "STKFLT"
+	signalNames[syscall.SIGPWR] = "PWR"
+}
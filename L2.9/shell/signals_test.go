@@ -0,0 +1,136 @@
+package shell
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestParseSignal(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected syscall.Signal
+		ok       bool
+	}{
+		{"9", syscall.SIGKILL, true},
+		{"TERM", syscall.SIGTERM, true},
+		{"SIGHUP", syscall.SIGHUP, true},
+		{"usr1", syscall.SIGUSR1, true},
+		{"0", 0, true},
+		{"64", 0, false},
+		{"BOGUS", 0, false},
+	}
+	for _, test := range tests {
+		sig, err := parseSignal(test.spec)
+		if (err == nil) != test.ok || sig != test.expected {
+			t.Errorf("Для %q ожидалось %v (ошибка: %v), но получено %v (%v)", test.spec, test.expected, !test.ok, sig, err)
+		}
+	}
+}
+
+func TestKill(t *testing.T) {
+	tests := []struct {
+		script   string
+		expected string
+		status   int
+	}{
+		{"kill -l 9 TERM 143", "KILL\n15\nTERM\n", 0},
+		{"kill -l | head -c 0; kill -l 99", "", 1},
+		{"kill -BOGUS 1", "", 1},
+		{"kill", "", 2},
+		{"kill abc", "", 1},
+		{"kill %5", "", 1},
+		{"sleep 10 & kill %1; wait %1", "", 128 + int(syscall.SIGTERM)},
+		{"sleep 10 & kill -s KILL %sleep; wait %sleep", "", 128 + int(syscall.SIGKILL)},
+		{"sleep 10 & kill %1; wait", "", 0},
+		{"sleep 0.1 & wait; sleep 10 & kill %1; wait %1", "", 128 + int(syscall.SIGTERM)},
+		{"true & wait; jobs", "", 0},
+		{"sleep 10 & kill -9 $!; wait $!", "", 128 + int(syscall.SIGKILL)},
+		{"sleep 10 & kill -INT -- -$!; wait $!", "", 128 + int(syscall.SIGINT)},
+		{"sleep 10 & sleep 10 & kill -HUP %1 %2; wait %1", "", 128 + int(syscall.SIGHUP)},
+	}
+	for _, test := range tests {
+		// Файлы вместо буферов и os.Stdin (шелл заменит его на /dev/null): фоновые
+		// процессы работают с ними без горутин-копировщиков.
+		stdout, _ := os.Create(filepath.Join(t.TempDir(), "out"))
+		tree, err := parse(test.script)
+		if err != nil {
+			t.Fatal(err)
+		}
+		status := newShell().runList(tree, streams{stdin: os.Stdin, stdout: stdout, stderr: io.Discard, ctl: &control{}})
+		stdout.Close()
+		out, _ := os.ReadFile(stdout.Name())
+		if status != test.status || string(out) != test.expected {
+			t.Errorf("Для %q ожидалось %q с кодом %d, но получено %q с кодом %d",
+				test.script, test.expected, test.status, out, status)
+		}
+	}
+
+	var out bytes.Buffer
+	listSignals(nil, &out)
+	if !strings.HasPrefix(out.String(), " 1) SIGHUP") || !strings.Contains(out.String(), "15) SIGTERM") {
+		t.Errorf("Неверный список сигналов:\n%s", out.String())
+	}
+}
+
+func TestPgrep(t *testing.T) {
+	fs := makeFakeProc(t, []fakeProc{
+		{pid: 1, comm: "init", uid: 0, cmdline: "/sbin/init\x00"},
+		{pid: 300, ppid: 1, comm: "l2sh", uid: 1000, cmdline: "l2sh\x00"},
+		{pid: 301, ppid: 300, comm: "sleep", uid: 1000, ticks: 1, cmdline: "sleep\x0010\x00"},
+		{pid: 302, ppid: 300, comm: "sleepy", uid: 0, cmdline: "sleepy\x00--deep\x00"},
+		{pid: 303, ppid: 1, comm: "python3", uid: 1000, cmdline: "python3\x00sleep.py\x00"},
+	})
+
+	tests := []struct {
+		args     []string
+		expected string
+		status   int
+	}{
+		{[]string{"pgrep", "sleep"}, "301\n302\n", 0},
+		{[]string{"pgrep", "-x", "sleep"}, "301\n", 0},
+		{[]string{"pgrep", "-f", "sleep"}, "301\n302\n303\n", 0},
+		{[]string{"pgrep", "-l", "^s"}, "301 sleep\n302 sleepy\n", 0},
+		{[]string{"pgrep", "-u", "1000", "."}, "301\n303\n", 0},
+		{[]string{"pgrep", "-P", "300"}, "301\n302\n", 0},
+		{[]string{"pgrep", "-f", "--deep"}, "", 2},
+		{[]string{"pgrep", "l2sh"}, "", 1},
+		{[]string{"pgrep", "nothing"}, "", 1},
+		{[]string{"pgrep"}, "", 2},
+		{[]string{"pgrep", "-TERM", "sleep"}, "", 2},
+		{[]string{"pgrep", "a", "b"}, "", 2},
+		{[]string{"pgrep", "("}, "", 2},
+	}
+	for _, test := range tests {
+		var stdout, stderr bytes.Buffer
+		status := runPgrep(fs, 300, test.args, streams{stdout: &stdout, stderr: &stderr})
+		if status != test.status || stdout.String() != test.expected {
+			t.Errorf("Для %q ожидалось %q с кодом %d, но получено %q с кодом %d (%s)",
+				test.args, test.expected, test.status, stdout.String(), status, stderr.String())
+		}
+	}
+}
+
+func TestPkill(t *testing.T) {
+	cmd := exec.Command("sleep", "10")
+	if err := cmd.Start(); err != nil {
+		t.Skip("sleep недоступен:", err)
+	}
+	fs := makeFakeProc(t, []fakeProc{
+		{pid: cmd.Process.Pid, comm: "sleep", cmdline: "sleep\x0010\x00"},
+	})
+
+	var stderr bytes.Buffer
+	if status := runPgrep(fs, 1, []string{"pkill", "-KILL", "-x", "sleep"}, streams{stderr: &stderr}); status != 0 {
+		t.Fatalf("Ожидался код 0, но получен %d (%s)", status, stderr.String())
+	}
+	err := cmd.Wait()
+	if ws, ok := cmd.ProcessState.Sys().(syscall.WaitStatus); !ok || ws.Signal() != syscall.SIGKILL {
+		t.Errorf("Процесс должен быть завершён SIGKILL, но получено %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package shell
+
+import (
+	"os/signal"
+	"syscall"
+	"unsafe"
+)
+
+// Коды si_code для SIGCHLD (см. waitid(2)).
+const (
+	cldStopped   = 5
+	cldContinued = 6
+)
+
+// siginfo — начало структуры siginfo_t; из неё нужен только si_code.
+type siginfo struct {
+	signo int32
+	errno int32
+	code  int32
+	_     [116]byte
+}
+
+func waitid(pid int, options int) (siginfo, error) {
+	var info siginfo
+	const pPID = 1
+	_, _, errno := syscall.Syscall6(syscall.SYS_WAITID, pPID, uintptr(pid),
+		uintptr(unsafe.Pointer(&info)), uintptr(options), 0, 0)
+	if errno != 0 {
+		return info, errno
+	}
+	return info, nil
+}
+
+// waitStopOrExit блокируется до остановки, продолжения или завершения процесса pid.
+// Завершившийся процесс остаётся зомби, чтобы его код забрал exec.Cmd.Wait;
+// события остановки и продолжения считываются, чтобы не получать их повторно.
+func waitStopOrExit(pid int) (stopped, continued bool, err error) {
+	for {
+		info, err := waitid(pid, syscall.WEXITED|syscall.WSTOPPED|syscall.WCONTINUED|syscall.WNOWAIT)
+		if err == syscall.EINTR {
+			continue
+		}
+		if err != nil {
+			return false, false, err
+		}
+		switch info.code {
+		case cldStopped, cldContinued:
+			waitid(pid, syscall.WSTOPPED|syscall.WCONTINUED|syscall.WNOHANG)
+			return info.code == cldStopped, info.code == cldContinued, nil
+		}
+		return false, false, nil
+	}
+}
+
+func ioctl(fd int, req uintptr, arg unsafe.Pointer) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), req, uintptr(arg))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// isTerminal сообщает, связан ли дескриптор fd с терминалом.
+func isTerminal(fd int) bool {
+	var termios syscall.Termios
+	return ioctl(fd, syscall.TCGETS, unsafe.Pointer(&termios)) == nil
+}
+
+// makeRaw переводит терминал fd в посимвольный режим без эха и без сигналов
+// от Ctrl+C и Ctrl+Z: эти клавиши обрабатывает редактор строки. Возвращает
+// функцию, восстанавливающую прежний режим, — до запуска команд его нужно вернуть.
+func makeRaw(fd int) (restore func(), err error) {
+	var old syscall.Termios
+	if err := ioctl(fd, syscall.TCGETS, unsafe.Pointer(&old)); err != nil {
+		return nil, err
+	}
+	raw := old
+	raw.Iflag &^= syscall.ICRNL | syscall.IXON | syscall.BRKINT | syscall.INPCK | syscall.ISTRIP
+	raw.Lflag &^= syscall.ICANON | syscall.ECHO | syscall.ISIG | syscall.IEXTEN
+	raw.Cflag |= syscall.CS8
+	raw.Cc[syscall.VMIN], raw.Cc[syscall.VTIME] = 1, 0
+	if err := ioctl(fd, syscall.TCSETS, unsafe.Pointer(&raw)); err != nil {
+		return nil, err
+	}
+	return func() { ioctl(fd, syscall.TCSETS, unsafe.Pointer(&old)) }, nil
+}
+
+// terminalWidth возвращает ширину терминала fd в символах или 80, если она неизвестна.
+func terminalWidth(fd int) int {
+	var size struct{ rows, cols, x, y uint16 }
+	if err := ioctl(fd, syscall.TIOCGWINSZ, unsafe.Pointer(&size)); err != nil || size.cols == 0 {
+		return 80
+	}
+	return int(size.cols)
+}
+
+// tcsetpgrp делает группу pgid группой переднего плана терминала fd. Пока шелл
+// не на переднем плане, вызов порождает SIGTTOU, поэтому на время вызова сигнал
+// игнорируется. Постоянно игнорировать его нельзя: игнорирование наследуется
+// запущенными программами, поэтому на это время запрещён и запуск процессов.
+func tcsetpgrp(fd, pgid int) error {
+	syscall.ForkLock.Lock()
+	defer syscall.ForkLock.Unlock()
+	signal.Ignore(syscall.SIGTTOU)
+	defer signal.Reset(syscall.SIGTTOU)
+
+	pgrp := int32(pgid)
+	return ioctl(fd, syscall.TIOCSPGRP, unsafe.Pointer(&pgrp))
+}
@@ -0,0 +1,33 @@
+//go:build !linux
+
+package shell
+
+import "errors"
+
+// Вне Linux шелл работает без управления заданиями и редактора строки:
+// для них нужны waitid и ioctl терминала из sys_linux.go.
+
+var errUnsupported = errors.New("not supported on this system")
+
+// waitStopOrExit сразу возвращает управление: остановки процессов не отслеживаются,
+// и код завершения забирает exec.Cmd.Wait.
+func waitStopOrExit(pid int) (stopped, continued bool, err error) {
+	return false, false, nil
+}
+
+// isTerminal считает любой дескриптор не терминалом: шелл читает строки без редактора.
+func isTerminal(fd int) bool {
+	return false
+}
+
+func makeRaw(fd int) (restore func(), err error) {
+	return nil, errUnsupported
+}
+
+func terminalWidth(fd int) int {
+	return 80
+}
+
+func tcsetpgrp(fd, pgid int) error {
+	return errUnsupported
+}
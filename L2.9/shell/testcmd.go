@@ -0,0 +1,186 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// builtinTest проверяет условие для if и while: test expr или [ expr ].
+// Код 0 — условие истинно, 1 — ложно, 2 — ошибка в выражении.
+func builtinTest(sh *shell, args []string, s streams) int {
+	name := args[0]
+	args = args[1:]
+	if name == "[" {
+		if len(args) == 0 || args[len(args)-1] != "]" {
+			fmt.Fprintln(s.stderr, "[: missing `]'")
+			return 2
+		}
+		args = args[:len(args)-1]
+	}
+
+	p := &testParser{args: args, path: sh.path}
+	ok, err := p.or()
+	if err == nil && p.pos < len(p.args) {
+		err = fmt.Errorf("%s: unexpected argument", p.args[p.pos])
+	}
+	if err != nil {
+		fmt.Fprintf(s.stderr, "%s: %v\n", name, err)
+		return 2
+	}
+	return boolStatus(ok)
+}
+
+// testParser разбирает выражение test:
+//
+//	or      := and { '-o' and }
+//	and     := not { '-a' not }
+//	not     := '!' not | primary
+//	primary := '(' or ')' | UNARY ARG | ARG BINARY ARG | ARG
+type testParser struct {
+	args []string
+	pos  int
+	path func(name string) string // путь к файлу относительно каталога шелла
+}
+
+func (p *testParser) left() int {
+	return len(p.args) - p.pos
+}
+
+func (p *testParser) or() (bool, error) {
+	result, err := p.and()
+	for err == nil && p.left() > 0 && p.args[p.pos] == "-o" {
+		p.pos++
+		var next bool
+		next, err = p.and()
+		result = result || next
+	}
+	return result, err
+}
+
+func (p *testParser) and() (bool, error) {
+	result, err := p.not()
+	for err == nil && p.left() > 0 && p.args[p.pos] == "-a" {
+		p.pos++
+		var next bool
+		next, err = p.not()
+		result = result && next
+	}
+	return result, err
+}
+
+func (p *testParser) not() (bool, error) {
+	if p.left() > 1 && p.args[p.pos] == "!" {
+		p.pos++
+		result, err := p.not()
+		return !result, err
+	}
+	return p.primary()
+}
+
+func (p *testParser) primary() (bool, error) {
+	if p.left() == 0 {
+		return false, nil
+	}
+	arg := p.args[p.pos]
+	if p.left() >= 3 && isTestBinary(p.args[p.pos+1]) {
+		op, right := p.args[p.pos+1], p.args[p.pos+2]
+		p.pos += 3
+		return testBinary(arg, op, right)
+	}
+	if arg == "(" && p.left() >= 3 {
+		p.pos++
+		result, err := p.or()
+		if err != nil {
+			return false, err
+		}
+		if p.left() == 0 || p.args[p.pos] != ")" {
+			return false, fmt.Errorf("`)' expected")
+		}
+		p.pos++
+		return result, nil
+	}
+	if p.left() >= 2 && len(arg) == 2 && arg[0] == '-' {
+		operand := p.args[p.pos+1]
+		p.pos += 2
+		return testUnary(arg, operand, p.path)
+	}
+	p.pos++
+	return arg != "", nil
+}
+
+func isTestBinary(op string) bool {
+	switch op {
+	case "=", "==", "!=", "<", ">", "-eq", "-ne", "-lt", "-le", "-gt", "-ge":
+		return true
+	}
+	return false
+}
+
+func testBinary(left, op, right string) (bool, error) {
+	switch op {
+	case "=", "==":
+		return left == right, nil
+	case "!=":
+		return left != right, nil
+	case "<":
+		return left < right, nil
+	case ">":
+		return left > right, nil
+	}
+
+	a, err := strconv.ParseInt(left, 10, 64)
+	if err != nil {
+		return false, fmt.Errorf("%s: integer expression expected", left)
+	}
+	b, err := strconv.ParseInt(right, 10, 64)
+	if err != nil {
+		return false, fmt.Errorf("%s: integer expression expected", right)
+	}
+	switch op {
+	case "-eq":
+		return a == b, nil
+	case "-ne":
+		return a != b, nil
+	case "-lt":
+		return a < b, nil
+	case "-le":
+		return a <= b, nil
+	case "-gt":
+		return a > b, nil
+	}
+	return a >= b, nil
+}
+
+func testUnary(op, operand string, path func(string) string) (bool, error) {
+	switch op {
+	case "-n":
+		return operand != "", nil
+	case "-z":
+		return operand == "", nil
+	}
+
+	operand = path(operand)
+	switch op {
+	case "-L", "-h":
+		info, err := os.Lstat(operand)
+		return err == nil && info.Mode()&os.ModeSymlink != 0, nil
+	case "-r", "-w", "-x":
+		mode := map[string]uint32{"-r": 4, "-w": 2, "-x": 1}[op]
+		return syscall.Access(operand, mode) == nil, nil
+	}
+
+	info, err := os.Stat(operand)
+	switch op {
+	case "-e":
+		return err == nil, nil
+	case "-f":
+		return err == nil && info.Mode().IsRegular(), nil
+	case "-d":
+		return err == nil && info.IsDir(), nil
+	case "-s":
+		return err == nil && info.Size() > 0, nil
+	}
+	return false, fmt.Errorf("%s: unary operator expected", op)
+}
@@ -0,0 +1,230 @@
+package shell
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"L2/internal/cli"
+)
+
+// wrapper — префиксы time и timeout перед простой командой. Они разбираются
+// при запуске команды, а не встроенными командами, чтобы процесс команды остался
+// процессом задания: его можно остановить Ctrl+Z и прервать Ctrl+C.
+type wrapper struct {
+	// time: после завершения команды напечатать время в stderr.
+	timed  bool
+	posix  bool // time -p: формат POSIX
+	stderr io.Writer
+	start  time.Time
+
+	// timeout: по истечении timeout послать signal группе процессов команды,
+	// а если задано killAfter — ещё через killAfter послать SIGKILL.
+	timeout   time.Duration
+	signal    syscall.Signal
+	killAfter time.Duration
+
+	mu       sync.Mutex
+	timers   []*time.Timer
+	timedOut bool
+	killed   bool
+}
+
+// prefixUsages — синтаксис префиксов команды для help.
+var prefixUsages = map[string]string{
+	"time":    "time [-p] command [arg ...]",
+	"timeout": "timeout [-s SIGNAL] [-k DURATION] DURATION command [arg ...]",
+}
+
+const timeoutUsage = "usage: timeout [-s SIGNAL] [-k DURATION] DURATION command [args...]"
+
+// parseWrappers снимает с args префиксы "time [-p]" и "timeout [-s SIG] [-k DUR] DUR".
+// Без префиксов возвращает nil. ok == false — ошибка в аргументах timeout,
+// она уже напечатана в stderr.
+func parseWrappers(args []string, stderr io.Writer) (rest []string, w *wrapper, ok bool) {
+	for len(args) > 0 {
+		switch {
+		case args[0] == "time" && (w == nil || !w.timed && w.timeout == 0):
+			if w == nil {
+				w = &wrapper{stderr: stderr}
+			}
+			w.timed = true
+			args = args[1:]
+			if len(args) > 0 && args[0] == "-p" {
+				w.posix = true
+				args = args[1:]
+			}
+		case args[0] == "timeout" && (w == nil || w.timeout == 0):
+			if w == nil {
+				w = &wrapper{stderr: stderr}
+			}
+			var err error
+			if args, err = w.parseTimeout(args[1:]); err != nil {
+				fmt.Fprintln(stderr, "timeout:", err)
+				fmt.Fprintln(stderr, timeoutUsage)
+				return nil, nil, false
+			}
+		default:
+			return args, w, true
+		}
+	}
+	return args, w, true
+}
+
+// parseTimeout разбирает параметры timeout и возвращает команду.
+func (w *wrapper) parseTimeout(args []string) ([]string, error) {
+	w.signal = syscall.SIGTERM
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") && args[0] != "-" {
+		opt := args[0]
+		args = args[1:]
+		if opt == "--" {
+			break
+		}
+		if opt != "-s" && opt != "-k" {
+			return nil, fmt.Errorf("%s: invalid option", opt)
+		}
+		if len(args) == 0 {
+			return nil, fmt.Errorf("%s: option requires an argument", opt)
+		}
+		var err error
+		if opt == "-s" {
+			w.signal, err = parseSignal(args[0])
+		} else {
+			w.killAfter, err = cli.ParseDuration(args[0])
+		}
+		if err != nil {
+			return nil, err
+		}
+		args = args[1:]
+	}
+	if len(args) < 2 {
+		return nil, fmt.Errorf("missing operand")
+	}
+	d, err := cli.ParseDuration(args[0])
+	if err != nil {
+		return nil, err
+	}
+	// timeout 0 не ограничивает время, как в GNU timeout.
+	w.timeout = d
+	return args[1:], nil
+}
+
+// external сообщает, что команду нужно запустить внешней программой, даже если есть
+// встроенная команда или функция с таким именем: timeout, как и GNU timeout,
+// не умеет прерывать код самого шелла.
+func (w *wrapper) external() bool {
+	return w != nil && w.timeout > 0
+}
+
+// runInShell выполняет встроенную команду или функцию; для time её время процессора
+// считается по getrusage шелла и его завершившихся детей.
+func (w *wrapper) runInShell(run func() int) int {
+	if w == nil || !w.timed {
+		return run()
+	}
+	start, before := time.Now(), selfUsage()
+	status := run()
+	after := selfUsage()
+	w.report(time.Since(start), after.user-before.user, after.sys-before.sys)
+	return status
+}
+
+// starting вызывается непосредственно перед запуском внешнего процесса.
+func (w *wrapper) starting() {
+	if w != nil {
+		w.start = time.Now()
+	}
+}
+
+// started запускает таймер timeout для процесса pid. Если процесс возглавляет группу,
+// сигнал получает вся группа вместе с потомками команды.
+func (w *wrapper) started(pid int, leader bool) {
+	if w == nil || w.timeout <= 0 {
+		return
+	}
+	target := pid
+	if leader {
+		target = -pid
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.timers = append(w.timers, time.AfterFunc(w.timeout, func() {
+		w.mu.Lock()
+		w.timedOut = true
+		w.killed = w.signal == syscall.SIGKILL
+		if w.killAfter > 0 {
+			w.timers = append(w.timers, time.AfterFunc(w.killAfter, func() {
+				w.mu.Lock()
+				w.killed = true
+				w.mu.Unlock()
+				syscall.Kill(target, syscall.SIGKILL)
+			}))
+		}
+		w.mu.Unlock()
+		syscall.Kill(target, w.signal)
+		if w.signal != syscall.SIGKILL && w.signal != syscall.SIGCONT {
+			// Остановленный процесс получит сигнал, только когда продолжит работу.
+			syscall.Kill(target, syscall.SIGCONT)
+		}
+	}))
+}
+
+// finish останавливает таймеры, печатает время для time и возвращает код завершения
+// команды: для timeout — 124, если время истекло, или 137, если пришлось послать SIGKILL.
+func (w *wrapper) finish(state *os.ProcessState, status int) int {
+	if w == nil {
+		return status
+	}
+	w.mu.Lock()
+	for _, t := range w.timers {
+		t.Stop()
+	}
+	switch {
+	case w.killed:
+		status = 128 + int(syscall.SIGKILL)
+	case w.timedOut:
+		status = 124
+	}
+	w.mu.Unlock()
+	if w.timed {
+		var user, sys time.Duration
+		if state != nil {
+			user, sys = state.UserTime(), state.SystemTime()
+		}
+		w.report(time.Since(w.start), user, sys)
+	}
+	return status
+}
+
+// report печатает время выполнения в формате bash или, для time -p, POSIX.
+func (w *wrapper) report(real, user, sys time.Duration) {
+	if w.posix {
+		fmt.Fprintf(w.stderr, "real %.2f\nuser %.2f\nsys %.2f\n", real.Seconds(), user.Seconds(), sys.Seconds())
+		return
+	}
+	format := func(d time.Duration) string {
+		m := int(d / time.Minute)
+		return fmt.Sprintf("%dm%.3fs", m, (d - time.Duration(m)*time.Minute).Seconds())
+	}
+	fmt.Fprintf(w.stderr, "\nreal\t%s\nuser\t%s\nsys\t%s\n", format(real), format(user), format(sys))
+}
+
+// rusage — время процессора в режиме пользователя и ядра.
+type rusage struct{ user, sys time.Duration }
+
+// selfUsage возвращает время процессора шелла вместе с его завершившимися детьми.
+func selfUsage() rusage {
+	var total rusage
+	for _, who := range []int{syscall.RUSAGE_SELF, syscall.RUSAGE_CHILDREN} {
+		var ru syscall.Rusage
+		if syscall.Getrusage(who, &ru) == nil {
+			total.user += time.Duration(ru.Utime.Nano())
+			total.sys += time.Duration(ru.Stime.Nano())
+		}
+	}
+	return total
+}
@@ -0,0 +1,83 @@
+package shell
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeout(t *testing.T) {
+	tests := []struct {
+		script   string
+		expected string
+		status   int
+	}{
+		{"timeout 5 true; echo $?", "0\n", 0},
+		{"timeout 5 sh -c 'exit 3'; echo $?", "3\n", 0},
+		{"timeout 0.2 sleep 5; echo $?", "124\n", 0},
+		{"timeout -s KILL 0.2 sleep 5; echo $?", "137\n", 0},
+		{"timeout -k 0.2 0.2 sh -c 'trap \"\" TERM; sleep 5'; echo $?", "137\n", 0},
+		{"timeout 0.2 sh -c 'sleep 5; echo survived'; echo $?", "124\n", 0},
+		{"timeout 0 sleep 0.1; echo $?", "0\n", 0},
+		{"timeout 5 echo external", "external\n", 0},
+		{"timeout", "", 125},
+		{"timeout -x 1 true", "", 125},
+		{"timeout soon true", "", 125},
+	}
+
+	for _, test := range tests {
+		start := time.Now()
+		status, out, _ := runScriptText(t, newShell(), test.script)
+		if status != test.status {
+			t.Errorf("Для скрипта %q ожидался код %d, но получен %d", test.script, test.status, status)
+		}
+		if out != test.expected {
+			t.Errorf("Для скрипта %q ожидалось %q, но получено %q", test.script, test.expected, out)
+		}
+		if elapsed := time.Since(start); elapsed > 3*time.Second {
+			t.Errorf("Скрипт %q выполнялся %v: timeout не завершил команду", test.script, elapsed)
+		}
+	}
+}
+
+func TestTime(t *testing.T) {
+	bashFormat := regexp.MustCompile(`^\nreal\t0m0\.[0-9]{3}s\nuser\t0m0\.[0-9]{3}s\nsys\t0m0\.[0-9]{3}s\n$`)
+	tests := []struct {
+		script   string
+		expected string
+		posix    bool
+	}{
+		{"time sleep 0.1", "", false},
+		{"time -p echo hi", "hi\n", true},
+		{"f() { echo in f; }; time f", "in f\n", false},
+		{"time", "", false},
+	}
+
+	for _, test := range tests {
+		var stderr strings.Builder
+		sh := newShell()
+		tree, err := parse(test.script)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var stdout strings.Builder
+		sh.runList(tree, streams{stdin: strings.NewReader(""), stdout: &stdout, stderr: &stderr, ctl: &control{}})
+		if stdout.String() != test.expected {
+			t.Errorf("Для скрипта %q ожидалось %q, но получено %q", test.script, test.expected, stdout.String())
+		}
+		report := stderr.String()
+		if test.posix && !regexp.MustCompile(`^real [0-9.]+\nuser [0-9.]+\nsys [0-9.]+\n$`).MatchString(report) ||
+			!test.posix && !bashFormat.MatchString(report) {
+			t.Errorf("Для скрипта %q получен неверный отчёт time: %q", test.script, report)
+		}
+	}
+
+	var stderr strings.Builder
+	sh := newShell()
+	tree, _ := parse("time sleep 0.2 2>/dev/null")
+	sh.runList(tree, streams{stdin: strings.NewReader(""), stdout: &strings.Builder{}, stderr: &stderr, ctl: &control{}})
+	if !strings.Contains(stderr.String(), "real\t0m0.2") {
+		t.Errorf("Отчёт time должен идти в stderr шелла и учитывать время ожидания, но получено %q", stderr.String())
+	}
+}
@@ -0,0 +1,78 @@
+// Команда l2 — multi-call бинарь в стиле busybox, объединяющий утилиты L2.
+//
+// Утилиту можно вызвать подкомандой (l2 sort -n file.txt) или через символическую
+// ссылку с её именем (ln -s l2 /usr/local/bin/sort), тогда выбор делается по argv[0].
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"L2/L2.4/sorter"
+	"L2/L2.6/grep"
+	"L2/L2.7/awk"
+	"L2/L2.7/cut"
+	"L2/internal/cli"
+	"L2/textutil/headtail"
+	"L2/textutil/uniq"
+	"L2/textutil/wc"
+)
+
+var commands = []cli.Command{
+	{Name: "sort", Usage: "сортировка строк", Run: sorter.Main},
+	{Name: "grep", Usage: "фильтрация строк по шаблону", Run: grep.Main},
+	{Name: "cut", Usage: "выбор колонок", Run: cut.Main},
+	{Name: "awk", Usage: "обработка полей программой вида 'шаблон { действие }'", Run: awk.Main},
+	{Name: "uniq", Usage: "схлопывание соседних одинаковых строк", Run: uniq.Main},
+	{Name: "wc", Usage: "подсчёт строк, слов, байт и символов", Run: wc.Main},
+	{Name: "head", Usage: "первые строки файла", Run: headtail.HeadMain},
+	{Name: "tail", Usage: "последние строки файла, -f — слежение за файлом", Run: headtail.TailMain},
+}
+
+func main() {
+	os.Exit(run(os.Args, cli.OSStdio()))
+}
+
+// run выбирает утилиту по имени бинаря, а если оно не совпадает ни с одной утилитой,
+// то по первому аргументу.
+func run(argv []string, stdio cli.Stdio) int {
+	name := filepath.Base(argv[0])
+	args := argv[1:]
+	if _, ok := lookup(name); !ok {
+		if len(args) == 0 {
+			printUsage(stdio)
+			return cli.ExitUsage
+		}
+		name, args = args[0], args[1:]
+	}
+
+	switch name {
+	case "help", "-h", "-help", "--help":
+		printUsage(stdio)
+		return cli.ExitOK
+	}
+
+	command, ok := lookup(name)
+	if !ok {
+		return cli.Errorf(stdio, "l2", cli.ExitUsage, "unknown command %q, run 'l2 help' for the list", name)
+	}
+	return command.Run(args, stdio)
+}
+
+func lookup(name string) (cli.Command, bool) {
+	for _, command := range commands {
+		if command.Name == name {
+			return command, true
+		}
+	}
+	return cli.Command{}, false
+}
+
+func printUsage(stdio cli.Stdio) {
+	fmt.Fprintln(stdio.Err, "Usage: l2 <command> [arguments]")
+	fmt.Fprintln(stdio.Err, "Commands:")
+	for _, command := range commands {
+		fmt.Fprintf(stdio.Err, "  %-6s %s\n", command.Name, command.Usage)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"L2/internal/cli"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		argv     []string
+		stdin    string
+		expected string
+		code     int
+	}{
+		{[]string{"l2", "sort"}, "b\na\n", "a\nb\n", cli.ExitOK},
+		{[]string{"/usr/bin/sort", "-r"}, "a\nb\n", "b\na\n", cli.ExitOK},
+		{[]string{"l2", "grep", "-c", "a"}, "a\nb\na\n", "2\n", cli.ExitOK},
+		{[]string{"grep", "z"}, "a\n", "", cli.ExitFailure},
+		{[]string{"l2", "cut", "-d", ",", "-f", "2"}, "a,b\n", "b\n", cli.ExitOK},
+		{[]string{"l2", "sort", "-x"}, "", "", cli.ExitUsage},
+		{[]string{"l2", "tac"}, "", "", cli.ExitUsage},
+		{[]string{"l2"}, "", "", cli.ExitUsage},
+	}
+
+	for _, test := range tests {
+		var out, errOut bytes.Buffer
+		stdio := cli.Stdio{In: strings.NewReader(test.stdin), Out: &out, Err: &errOut}
+		code := run(test.argv, stdio)
+		if code != test.code {
+			t.Errorf("%v: ожидался код %d, получен %d (stderr: %s)", test.argv, test.code, code, errOut.String())
+		}
+		if out.String() != test.expected {
+			t.Errorf("%v: ожидалось %q, получено %q", test.argv, test.expected, out.String())
+		}
+	}
+}
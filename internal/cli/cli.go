@@ -0,0 +1,73 @@
+// Package cli содержит общие для консольных утилит L2 вещи: потоки ввода-вывода,
+// разбор флагов, открытие входных файлов, вывод ошибок и коды завершения.
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Коды завершения, общие для всех утилит.
+const (
+	ExitOK      = 0 // успешное завершение
+	ExitFailure = 1 // ошибка выполнения (для grep — совпадений не найдено)
+	ExitUsage   = 2 // неверные аргументы командной строки (для grep — любая ошибка)
+)
+
+// Stdio — потоки, с которыми работает утилита. Позволяет запускать утилиты
+// из multi-call бинаря и из тестов без обращения к os.Stdin/os.Stdout напрямую.
+type Stdio struct {
+	In  io.Reader
+	Out io.Writer
+	Err io.Writer
+}
+
+// OSStdio возвращает стандартные потоки процесса.
+func OSStdio() Stdio {
+	return Stdio{In: os.Stdin, Out: os.Stdout, Err: os.Stderr}
+}
+
+// Command — утилита, которую можно вызвать из multi-call бинаря.
+type Command struct {
+	Name  string
+	Usage string
+	Run   func(args []string, stdio Stdio) int
+}
+
+// NewFlagSet создаёт набор флагов, который не завершает процесс при ошибке
+// и печатает справку в stdio.Err.
+func NewFlagSet(name string, stdio Stdio) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stdio.Err)
+	return fs
+}
+
+// ParseFlags разбирает аргументы. Если разбор не удался, возвращает ok == false
+// и код, с которым утилита должна завершиться: ExitOK для -h, иначе ExitUsage.
+func ParseFlags(fs *flag.FlagSet, args []string) (code int, ok bool) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK, false
+		}
+		return ExitUsage, false
+	}
+	return ExitOK, true
+}
+
+// OpenInput открывает файл для чтения. Пустой путь или "-" означают stdin.
+func OpenInput(path string, stdin io.Reader) (io.ReadCloser, error) {
+	if path == "" || path == "-" {
+		return io.NopCloser(stdin), nil
+	}
+	return os.Open(path)
+}
+
+// Errorf печатает сообщение об ошибке в формате "name: message" и возвращает code,
+// чтобы вызывающий мог сразу написать return cli.Errorf(...).
+func Errorf(stdio Stdio, name string, code int, format string, args ...any) int {
+	fmt.Fprintf(stdio.Err, "%s: %s\n", name, fmt.Sprintf(format, args...))
+	return code
+}
@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// ParseDuration разбирает длительность: число секунд с необязательным суффиксом
+// s, m, h или d (как в GNU timeout и wget --wait) или длительность Go вроде 1m30s.
+// Отрицательные, бесконечные, NaN и не помещающиеся в time.Duration значения — ошибка.
+func ParseDuration(s string) (time.Duration, error) {
+	units := map[byte]time.Duration{'s': time.Second, 'm': time.Minute, 'h': time.Hour, 'd': 24 * time.Hour}
+	number, unit := s, time.Second
+	if n := len(s); n > 1 {
+		if u, ok := units[s[n-1]]; ok {
+			number, unit = s[:n-1], u
+		}
+	}
+	if f, err := strconv.ParseFloat(number, 64); err == nil && f >= 0 {
+		// inf и слишком большие числа не помещаются в time.Duration.
+		ns := f * float64(unit)
+		if math.IsInf(ns, 0) || ns >= math.MaxInt64 {
+			return 0, fmt.Errorf("%s: time interval out of range", s)
+		}
+		return time.Duration(ns), nil
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return d, nil
+	}
+	return 0, fmt.Errorf("%s: invalid time interval", s)
+}
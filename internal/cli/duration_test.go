@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+		ok       bool
+	}{
+		{"2", 2 * time.Second, true},
+		{"0.5", 500 * time.Millisecond, true},
+		{"1.5m", 90 * time.Second, true},
+		{"2h", 2 * time.Hour, true},
+		{"1d", 24 * time.Hour, true},
+		{"1m30s", 90 * time.Second, true},
+		{"250ms", 250 * time.Millisecond, true},
+		{"0", 0, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{"inf", 0, false},
+		{"+Inf", 0, false},
+		{"infs", 0, false},
+		{"NaN", 0, false},
+		{"nanm", 0, false},
+		{"1e300", 0, false},
+		{"1e400", 0, false},
+		{"300000000000d", 0, false},
+		{"9223372036", 9223372036 * time.Second, true},
+		{"9223372037", 0, false},
+	}
+
+	for _, test := range tests {
+		d, err := ParseDuration(test.input)
+		if (err == nil) != test.ok {
+			t.Errorf("Для %q ожидалась ошибка: %v, но получено %v", test.input, !test.ok, err)
+		}
+		if d != test.expected {
+			t.Errorf("Для %q ожидалось %v, но получено %v", test.input, test.expected, d)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+)
+
+// LineReader потоково читает строки. В отличие от bufio.Scanner, длина строки
+// не ограничена, а для последней строки известно, был ли у неё перевод строки,
+// поэтому по прочитанному можно точно восстановить исходные байты.
+type LineReader struct {
+	r       *bufio.Reader
+	line    []byte
+	newline bool
+	err     error
+}
+
+// NewLineReader создаёт LineReader поверх r.
+func NewLineReader(r io.Reader) *LineReader {
+	return &LineReader{r: bufio.NewReader(r)}
+}
+
+// Next читает следующую строку и возвращает false, когда строки закончились
+// или произошла ошибка (её возвращает Err).
+func (lr *LineReader) Next() bool {
+	if lr.err != nil {
+		return false
+	}
+	line, err := lr.r.ReadBytes('\n')
+	if err != nil {
+		if !errors.Is(err, io.EOF) {
+			lr.err = err
+			return false
+		}
+		lr.err = io.EOF
+		if len(line) == 0 {
+			return false
+		}
+	}
+	lr.newline = bytes.HasSuffix(line, []byte{'\n'})
+	lr.line = bytes.TrimSuffix(line, []byte{'\n'})
+	return true
+}
+
+// Bytes возвращает текущую строку без перевода строки.
+// Срез действителен до следующего вызова Next.
+func (lr *LineReader) Bytes() []byte {
+	return lr.line
+}
+
+// Text возвращает текущую строку без перевода строки.
+func (lr *LineReader) Text() string {
+	return string(lr.line)
+}
+
+// HasNewline сообщает, заканчивалась ли текущая строка переводом строки.
+func (lr *LineReader) HasNewline() bool {
+	return lr.newline
+}
+
+// Err возвращает первую ошибку чтения, кроме io.EOF.
+func (lr *LineReader) Err() error {
+	if errors.Is(lr.err, io.EOF) {
+		return nil
+	}
+	return lr.err
+}
+
+// ReadLines читает все строки из r без символов перевода строки.
+func ReadLines(r io.Reader) ([]string, error) {
+	var lines []string
+	lr := NewLineReader(r)
+	for lr.Next() {
+		lines = append(lines, lr.Text())
+	}
+	return lines, lr.Err()
+}
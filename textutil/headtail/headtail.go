@@ -0,0 +1,236 @@
+// Package headtail реализует утилиты head и tail, включая режим слежения tail -f.
+package headtail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"L2/internal/cli"
+)
+
+// HeadMain запускает head с аргументами args: head [-n N] [FILE ...].
+func HeadMain(args []string, stdio cli.Stdio) int {
+	fs := cli.NewFlagSet("head", stdio)
+	count := fs.Int("n", 10, "Print the first N lines")
+	if code, ok := cli.ParseFlags(fs, args); !ok {
+		return code
+	}
+	if *count < 0 {
+		return cli.Errorf(stdio, "head", cli.ExitUsage, "invalid number of lines: %d", *count)
+	}
+
+	return forEachInput("head", fs.Args(), stdio, func(r io.Reader) error {
+		return head(r, stdio.Out, *count)
+	})
+}
+
+// head копирует первые n строк из r в w и прекращает чтение.
+func head(r io.Reader, w io.Writer, n int) error {
+	lines := cli.NewLineReader(r)
+	for i := 0; i < n && lines.Next(); i++ {
+		if err := writeLine(w, lines.Bytes(), lines.HasNewline()); err != nil {
+			return err
+		}
+	}
+	return lines.Err()
+}
+
+// TailMain запускает tail с аргументами args: tail [-n N|+N] [-f] [-s INTERVAL] [FILE ...].
+// В режиме -f файл опрашивается до Ctrl+C (SIGINT) или SIGTERM.
+func TailMain(args []string, stdio cli.Stdio) int {
+	return runTail(context.Background(), args, stdio)
+}
+
+func runTail(ctx context.Context, args []string, stdio cli.Stdio) int {
+	fs := cli.NewFlagSet("tail", stdio)
+	countSpec := fs.String("n", "10", "Print the last N lines, or from line N onwards with +N")
+	follow := fs.Bool("f", false, "Output appended data as the file grows")
+	interval := fs.Duration("s", 500*time.Millisecond, "Polling interval for -f")
+	if code, ok := cli.ParseFlags(fs, args); !ok {
+		return code
+	}
+	count, fromStart, err := parseCount(*countSpec)
+	if err != nil {
+		return cli.Errorf(stdio, "tail", cli.ExitUsage, "%v", err)
+	}
+	if *follow && fs.NArg() > 1 {
+		return cli.Errorf(stdio, "tail", cli.ExitUsage, "-f supports a single file")
+	}
+	if *interval <= 0 {
+		return cli.Errorf(stdio, "tail", cli.ExitUsage, "invalid polling interval %v", *interval)
+	}
+
+	printTail := func(r io.Reader) error {
+		if fromStart {
+			return tailFrom(r, stdio.Out, count)
+		}
+		return tail(r, stdio.Out, count)
+	}
+
+	// Для stdin (канала) слежение не имеет смысла, как и в GNU tail.
+	path := fs.Arg(0)
+	if !*follow || path == "" || path == "-" {
+		return forEachInput("tail", fs.Args(), stdio, printTail)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return cli.Errorf(stdio, "tail", cli.ExitFailure, "%v", err)
+	}
+	defer file.Close()
+	if err := printTail(file); err != nil {
+		return cli.Errorf(stdio, "tail", cli.ExitFailure, "%s: %v", path, err)
+	}
+	// Сигналы перехватываются только на время слежения: без -f Ctrl+C
+	// завершает tail как обычно.
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := followFile(ctx, file, stdio, *interval); err != nil {
+		return cli.Errorf(stdio, "tail", cli.ExitFailure, "%s: %v", path, err)
+	}
+	return cli.ExitOK
+}
+
+// parseCount разбирает значение -n: "N" — последние N строк, "+N" — начиная со строки N.
+func parseCount(spec string) (count int, fromStart bool, err error) {
+	fromStart = strings.HasPrefix(spec, "+")
+	count, err = strconv.Atoi(strings.TrimPrefix(spec, "+"))
+	if err != nil || count < 0 {
+		return 0, false, fmt.Errorf("invalid number of lines: %q", spec)
+	}
+	return count, fromStart, nil
+}
+
+type line struct {
+	text    []byte
+	newline bool
+}
+
+// tail печатает последние n строк из r. В памяти хранится не более n строк;
+// кольцевой буфер растёт по мере чтения, поэтому большое n на коротком входе
+// не занимает лишней памяти.
+func tail(r io.Reader, w io.Writer, n int) error {
+	if n == 0 {
+		_, err := io.Copy(io.Discard, r)
+		return err
+	}
+	var ring []line
+	total := 0
+	lines := cli.NewLineReader(r)
+	for lines.Next() {
+		if len(ring) < n {
+			ring = append(ring, line{})
+		}
+		slot := &ring[total%len(ring)]
+		slot.text = append(slot.text[:0], lines.Bytes()...)
+		slot.newline = lines.HasNewline()
+		total++
+	}
+	if err := lines.Err(); err != nil {
+		return err
+	}
+
+	for i := total - len(ring); i < total; i++ {
+		l := ring[i%len(ring)]
+		if err := writeLine(w, l.text, l.newline); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tailFrom печатает строки r начиная со строки с номером n (с единицы).
+func tailFrom(r io.Reader, w io.Writer, n int) error {
+	lines := cli.NewLineReader(r)
+	for number := 1; lines.Next(); number++ {
+		if number < n {
+			continue
+		}
+		if err := writeLine(w, lines.Bytes(), lines.HasNewline()); err != nil {
+			return err
+		}
+	}
+	return lines.Err()
+}
+
+// followFile печатает данные, дописанные в конец file после уже прочитанного, пока не отменён ctx.
+// Если файл укоротили (например, ротация через truncate), чтение начинается заново.
+func followFile(ctx context.Context, file *os.File, stdio cli.Stdio, interval time.Duration) error {
+	offset, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		info, err := file.Stat()
+		if err != nil {
+			return err
+		}
+		if info.Size() < offset {
+			fmt.Fprintf(stdio.Err, "tail: %s: file truncated\n", file.Name())
+			if offset, err = file.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+		}
+		n, err := io.Copy(stdio.Out, file)
+		offset += n
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+	}
+}
+
+// forEachInput применяет fn к каждому входу; при нескольких файлах перед выводом
+// каждого печатается заголовок "==> name <==", как у head и tail из coreutils.
+func forEachInput(name string, paths []string, stdio cli.Stdio, fn func(io.Reader) error) int {
+	if len(paths) == 0 {
+		paths = []string{""}
+	}
+	code := cli.ExitOK
+	for i, path := range paths {
+		input, err := cli.OpenInput(path, stdio.In)
+		if err != nil {
+			code = cli.Errorf(stdio, name, cli.ExitFailure, "%v", err)
+			continue
+		}
+		if len(paths) > 1 {
+			if i > 0 {
+				fmt.Fprintln(stdio.Out)
+			}
+			fmt.Fprintf(stdio.Out, "==> %s <==\n", path)
+		}
+		err = fn(input)
+		input.Close()
+		if err != nil {
+			code = cli.Errorf(stdio, name, cli.ExitFailure, "%s: %v", path, err)
+		}
+	}
+	return code
+}
+
+func writeLine(w io.Writer, text []byte, newline bool) error {
+	if _, err := w.Write(text); err != nil {
+		return err
+	}
+	if newline {
+		_, err := w.Write([]byte{'\n'})
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package headtail
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"L2/internal/cli"
+)
+
+func TestHeadTail(t *testing.T) {
+	const input = "1\n2\n3\n4\n5"
+
+	tests := []struct {
+		name     string
+		fn       func(*bytes.Buffer) error
+		expected string
+	}{
+		{"head", func(out *bytes.Buffer) error { return head(strings.NewReader(input), out, 2) }, "1\n2\n"},
+		{"head more than input", func(out *bytes.Buffer) error { return head(strings.NewReader(input), out, 10) }, input},
+		{"tail", func(out *bytes.Buffer) error { return tail(strings.NewReader(input), out, 2) }, "4\n5"},
+		{"tail zero", func(out *bytes.Buffer) error { return tail(strings.NewReader(input), out, 0) }, ""},
+		{"tail more than input", func(out *bytes.Buffer) error { return tail(strings.NewReader(input), out, 9) }, input},
+		{"tail huge count", func(out *bytes.Buffer) error { return tail(strings.NewReader(input), out, 1_000_000_000) }, input},
+		{"tail from line", func(out *bytes.Buffer) error { return tailFrom(strings.NewReader(input), out, 4) }, "4\n5"},
+	}
+
+	for _, test := range tests {
+		var out bytes.Buffer
+		if err := test.fn(&out); err != nil {
+			t.Errorf("%s: не ожидалась ошибка: %v", test.name, err)
+		} else if out.String() != test.expected {
+			t.Errorf("%s: ожидалось %q, получено %q", test.name, test.expected, out.String())
+		}
+	}
+}
+
+// syncBuffer — буфер, который безопасно читать, пока tail -f пишет в него из другой горутины.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestTailFollow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.txt")
+	if err := os.WriteFile(path, []byte("old 1\nold 2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out, errOut syncBuffer
+	stdio := cli.Stdio{In: strings.NewReader(""), Out: &out, Err: &errOut}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan int)
+	go func() {
+		done <- runTail(ctx, []string{"-n", "1", "-f", "-s", "10ms", path}, stdio)
+	}()
+
+	waitFor := func(expected string) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for out.String() != expected {
+			if time.Now().After(deadline) {
+				t.Fatalf("ожидалось %q, получено %q", expected, out.String())
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	waitFor("old 2\n")
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString("new 3\n")
+	file.Close()
+	waitFor("old 2\nnew 3\n")
+
+	if err := os.WriteFile(path, []byte("x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor("old 2\nnew 3\nx\n")
+	if !strings.Contains(errOut.String(), "truncated") {
+		t.Errorf("ожидалось сообщение об усечении файла, получено %q", errOut.String())
+	}
+
+	cancel()
+	if code := <-done; code != cli.ExitOK {
+		t.Errorf("ожидался код %d, получен %d", cli.ExitOK, code)
+	}
+}
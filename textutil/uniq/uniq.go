@@ -0,0 +1,130 @@
+// Package uniq реализует утилиту uniq: схлопывает соседние одинаковые строки.
+//
+// В отличие от sort -u, uniq сравнивает только соседние строки и держит в памяти
+// одну предыдущую строку, поэтому подходит для конвейеров вида sort | uniq -c | sort -n.
+package uniq
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+
+	"L2/internal/cli"
+)
+
+// UniqOptions — ключи утилиты uniq.
+type UniqOptions struct {
+	count      bool // -c: печатать число повторов перед строкой
+	repeated   bool // -d: печатать только повторяющиеся строки
+	unique     bool // -u: печатать только неповторяющиеся строки
+	skipFields int  // -f: не сравнивать первые N полей
+	skipChars  int  // -s: не сравнивать первые N символов (после пропуска полей)
+	ignoreCase bool // -i: сравнивать без учёта регистра
+}
+
+// Main запускает uniq с аргументами args: uniq [флаги] [INPUT [OUTPUT]].
+func Main(args []string, stdio cli.Stdio) int {
+	var opts UniqOptions
+	fs := cli.NewFlagSet("uniq", stdio)
+	fs.BoolVar(&opts.count, "c", false, "Prefix lines by the number of occurrences")
+	fs.BoolVar(&opts.repeated, "d", false, "Only print duplicate lines, one for each group")
+	fs.BoolVar(&opts.unique, "u", false, "Only print unique lines")
+	fs.IntVar(&opts.skipFields, "f", 0, "Avoid comparing the first N fields")
+	fs.IntVar(&opts.skipChars, "s", 0, "Avoid comparing the first N characters")
+	fs.BoolVar(&opts.ignoreCase, "i", false, "Ignore differences in case when comparing")
+	if code, ok := cli.ParseFlags(fs, args); !ok {
+		return code
+	}
+	if opts.skipFields < 0 || opts.skipChars < 0 {
+		return cli.Errorf(stdio, "uniq", cli.ExitUsage, "-f and -s must not be negative")
+	}
+
+	input, err := cli.OpenInput(fs.Arg(0), stdio.In)
+	if err != nil {
+		return cli.Errorf(stdio, "uniq", cli.ExitFailure, "%v", err)
+	}
+	defer input.Close()
+
+	out := stdio.Out
+	if path := fs.Arg(1); path != "" && path != "-" {
+		file, err := os.Create(path)
+		if err != nil {
+			return cli.Errorf(stdio, "uniq", cli.ExitFailure, "%v", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if err := uniqLines(input, out, opts); err != nil {
+		return cli.Errorf(stdio, "uniq", cli.ExitFailure, "%v", err)
+	}
+	return cli.ExitOK
+}
+
+// uniqLines читает строки из r и пишет в w по одной строке из каждой группы соседних
+// одинаковых (с учётом opts) строк. Печатается первая строка группы.
+func uniqLines(r io.Reader, w io.Writer, opts UniqOptions) error {
+	lines := cli.NewLineReader(r)
+	var (
+		current    string
+		currentKey string
+		count      int
+	)
+
+	flush := func() error {
+		if count == 0 {
+			return nil
+		}
+		if (opts.repeated && count < 2) || (opts.unique && count > 1) {
+			return nil
+		}
+		var err error
+		if opts.count {
+			_, err = fmt.Fprintf(w, "%7d %s\n", count, current)
+		} else {
+			_, err = fmt.Fprintln(w, current)
+		}
+		return err
+	}
+
+	for lines.Next() {
+		line := lines.Text()
+		key := compareKey(line, opts)
+		if count > 0 && key == currentKey {
+			count++
+			continue
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+		current, currentKey, count = line, key, 1
+	}
+	if err := lines.Err(); err != nil {
+		return err
+	}
+	return flush()
+}
+
+// compareKey возвращает часть строки, по которой сравниваются соседние строки.
+// Поле, как и в POSIX uniq, — это пробельные символы и следующие за ними непробельные.
+func compareKey(line string, opts UniqOptions) string {
+	key := line
+	for i := 0; i < opts.skipFields && key != ""; i++ {
+		key = strings.TrimLeftFunc(key, unicode.IsSpace)
+		if end := strings.IndexFunc(key, unicode.IsSpace); end >= 0 {
+			key = key[end:]
+		} else {
+			key = ""
+		}
+	}
+	if opts.skipChars > 0 {
+		runes := []rune(key)
+		key = string(runes[min(opts.skipChars, len(runes)):])
+	}
+	if opts.ignoreCase {
+		key = strings.ToLower(key)
+	}
+	return key
+}
@@ -0,0 +1,37 @@
+package uniq
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestUniqLines(t *testing.T) {
+	const input = "a\na\nb\nA\nc\nc\nc\n"
+
+	tests := []struct {
+		name     string
+		input    string
+		opts     UniqOptions
+		expected string
+	}{
+		{"default", input, UniqOptions{}, "a\nb\nA\nc\n"},
+		{"count", input, UniqOptions{count: true}, "      2 a\n      1 b\n      1 A\n      3 c\n"},
+		{"repeated", input, UniqOptions{repeated: true}, "a\nc\n"},
+		{"unique", input, UniqOptions{unique: true}, "b\nA\n"},
+		{"ignore case", "a\nA\nb\n", UniqOptions{ignoreCase: true, count: true}, "      2 a\n      1 b\n"},
+		{"skip fields", "1 x\n2 x\n3 y\n", UniqOptions{skipFields: 1}, "1 x\n3 y\n"},
+		{"skip chars", "ax\nbx\ncy\n", UniqOptions{skipChars: 1}, "ax\ncy\n"},
+		{"no trailing newline", "a\na", UniqOptions{count: true}, "      2 a\n"},
+		{"empty input", "", UniqOptions{}, ""},
+	}
+
+	for _, test := range tests {
+		var out bytes.Buffer
+		if err := uniqLines(strings.NewReader(test.input), &out, test.opts); err != nil {
+			t.Errorf("%s: не ожидалась ошибка: %v", test.name, err)
+		} else if out.String() != test.expected {
+			t.Errorf("%s: ожидалось %q, получено %q", test.name, test.expected, out.String())
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Package wc реализует утилиту wc: подсчёт строк, слов, байт и символов.
+package wc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"L2/internal/cli"
+)
+
+// WcOptions — какие счётчики печатать. Если не выбран ни один, печатаются строки, слова и байты.
+type WcOptions struct {
+	lines bool
+	words bool
+	bytes bool
+	runes bool
+}
+
+// counts — результат подсчёта для одного входа.
+type counts struct {
+	lines, words, bytes, runes int
+}
+
+func (c *counts) add(other counts) {
+	c.lines += other.lines
+	c.words += other.words
+	c.bytes += other.bytes
+	c.runes += other.runes
+}
+
+// Main запускает wc с аргументами args: wc [-l] [-w] [-c] [-m] [FILE ...].
+// При нескольких файлах в конце печатается строка total.
+func Main(args []string, stdio cli.Stdio) int {
+	var opts WcOptions
+	fs := cli.NewFlagSet("wc", stdio)
+	fs.BoolVar(&opts.lines, "l", false, "Print the newline counts")
+	fs.BoolVar(&opts.words, "w", false, "Print the word counts")
+	fs.BoolVar(&opts.bytes, "c", false, "Print the byte counts")
+	fs.BoolVar(&opts.runes, "m", false, "Print the character (UTF-8 rune) counts")
+	if code, ok := cli.ParseFlags(fs, args); !ok {
+		return code
+	}
+	if !opts.lines && !opts.words && !opts.bytes && !opts.runes {
+		opts.lines, opts.words, opts.bytes = true, true, true
+	}
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{""}
+	}
+
+	code := cli.ExitOK
+	var total counts
+	for _, path := range paths {
+		input, err := cli.OpenInput(path, stdio.In)
+		if err != nil {
+			code = cli.Errorf(stdio, "wc", cli.ExitFailure, "%v", err)
+			continue
+		}
+		c, err := count(input)
+		input.Close()
+		if err != nil {
+			code = cli.Errorf(stdio, "wc", cli.ExitFailure, "%s: %v", path, err)
+			continue
+		}
+		total.add(c)
+		printCounts(stdio.Out, c, opts, path)
+	}
+	if len(paths) > 1 {
+		printCounts(stdio.Out, total, opts, "total")
+	}
+	return code
+}
+
+// count считает строки (переводы строки), слова, байты и руны в r.
+// Слова не переносятся через строку, поэтому считаются построчно.
+func count(r io.Reader) (counts, error) {
+	var c counts
+	lines := cli.NewLineReader(r)
+	for lines.Next() {
+		line := lines.Bytes()
+		c.words += len(bytes.Fields(line))
+		c.bytes += len(line)
+		c.runes += utf8.RuneCount(line)
+		if lines.HasNewline() {
+			c.lines++
+			c.bytes++
+			c.runes++
+		}
+	}
+	return c, lines.Err()
+}
+
+func printCounts(w io.Writer, c counts, opts WcOptions, name string) {
+	var columns []string
+	if opts.lines {
+		columns = append(columns, fmt.Sprintf("%7d", c.lines))
+	}
+	if opts.words {
+		columns = append(columns, fmt.Sprintf("%7d", c.words))
+	}
+	if opts.runes {
+		columns = append(columns, fmt.Sprintf("%7d", c.runes))
+	}
+	if opts.bytes {
+		columns = append(columns, fmt.Sprintf("%7d", c.bytes))
+	}
+	if name != "" && name != "-" {
+		columns = append(columns, name)
+	}
+	fmt.Fprintln(w, strings.Join(columns, " "))
+}
@@ -0,0 +1,27 @@
+package wc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected counts
+	}{
+		{"", counts{}},
+		{"hello world\n", counts{lines: 1, words: 2, bytes: 12, runes: 12}},
+		{"привет мир", counts{lines: 0, words: 2, bytes: 19, runes: 10}},
+		{"  a\tb  \n\nc\n", counts{lines: 3, words: 3, bytes: 11, runes: 11}},
+	}
+
+	for _, test := range tests {
+		c, err := count(strings.NewReader(test.input))
+		if err != nil {
+			t.Errorf("%q: не ожидалась ошибка: %v", test.input, err)
+		} else if c != test.expected {
+			t.Errorf("%q: ожидалось %+v, получено %+v", test.input, test.expected, c)
+		}
+	}
+}